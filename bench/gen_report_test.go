@@ -76,12 +76,12 @@ func throwErr(t *testing.T, err error) {
 		return
 	}
 
-	t.Logf(fmt.Sprintf("[Gen Report Failed] %v", err))
+	t.Logf("[Gen Report Failed] %v", err)
 	t.Fail()
 }
 
 func throwErrWithReason(t *testing.T, reason string) {
-	t.Logf(fmt.Sprintf("[Gen Report Failed] %s", reason))
+	t.Logf("[Gen Report Failed] %s", reason)
 	t.Fail()
 }
 
@@ -188,7 +188,7 @@ func (l *lineChart) genChart(htmlFileName string) error {
 	sort.Strings(names)
 
 	for _, name := range names {
-		line.AddSeries(name, series[name], charts.WithLineChartOpts(opts.LineChart{Smooth: true}))
+		line.AddSeries(name, series[name], charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(true)}))
 		legend = append(legend, name)
 	}
 
@@ -196,8 +196,8 @@ func (l *lineChart) genChart(htmlFileName string) error {
 		charts.WithTitleOpts(opts.Title{Title: l.title, Right: "center", Bottom: "bottom"}),
 		charts.WithXAxisOpts(opts.XAxis{Name: l.xAxisName}),
 		charts.WithYAxisOpts(opts.YAxis{Name: l.yAxisName}),
-		charts.WithLegendOpts(opts.Legend{Data: legend, Show: true}),
-		charts.WithTooltipOpts(opts.Tooltip{Trigger: "axis", Show: true}),
+		charts.WithLegendOpts(opts.Legend{Data: legend, Show: opts.Bool(true)}),
+		charts.WithTooltipOpts(opts.Tooltip{Trigger: "axis", Show: opts.Bool(true)}),
 	)
 
 	f, err := os.Create(htmlFileName)
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var (
@@ -83,7 +84,66 @@ func BenchmarkHybridSPSC(b *testing.B) {
 	spscBenchmark(b, mpscRB, 2, 1)
 }
 
+func BenchmarkOversubscribedBusySpin(b *testing.B) {
+	oversubscribedBenchmark(b, lfring.BusySpinWait())
+}
+
+func BenchmarkOversubscribedYielding(b *testing.B) {
+	oversubscribedBenchmark(b, lfring.YieldingWait())
+}
+
+func BenchmarkOversubscribedCooperative(b *testing.B) {
+	oversubscribedBenchmark(b, lfring.CooperativeWait(16, 50*time.Microsecond))
+}
+
+// oversubscribedBenchmark runs threadNum producers per P against a single consumer on 2 Ps,
+// every side waits by the given strategy after a failed attempt.
+func oversubscribedBenchmark(b *testing.B, wait lfring.WaitStrategy) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+	buffer := lfring.New[int](lfring.NodeBased, capacity)
+	ints := setup()
+
+	done := make(chan struct{})
+	var producers sync.WaitGroup
+	for p := 0; p < 2*threadNum; p++ {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for i, attempt := 0, 1; ; attempt++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if buffer.Offer(ints[i&(len(ints)-1)]) {
+					i++
+					attempt = 0
+					continue
+				}
+				wait.Wait(attempt)
+			}
+		}()
+	}
+
+	b.ResetTimer()
+	for i, attempt := 0, 1; i < b.N; attempt++ {
+		if _, success := buffer.Poll(); success {
+			i++
+			attempt = 0
+			continue
+		}
+		wait.Wait(attempt)
+	}
+	b.StopTimer()
+
+	close(done)
+	producers.Wait()
+}
+
+// fakeBuffer wraps a go channel as RingBuffer, only the methods used by benchmarks are
+// implemented, the others fall to the embedded nil RingBuffer.
 type fakeBuffer[T any] struct {
+	lfring.RingBuffer[T]
 	capacity uint64
 	ch       chan T
 	empty    T
@@ -147,8 +207,8 @@ var wg sync.WaitGroup
 func manage(b *testing.B, threadCount int, trueCount int) {
 	runtime.GOMAXPROCS(threadCount)
 
+	wg.Add(1)
 	go func() {
-		wg.Add(1)
 		for i := 0; i < threadCount; i++ {
 			if trueCount > 0 {
 				controlCh <- true
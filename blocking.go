@@ -0,0 +1,298 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed is returned when operate a closed buffer.
+var ErrClosed = errors.New("lfring: buffer is closed")
+
+// errDeadline is returned by await when deadline passed.
+var errDeadline = errors.New("lfring: deadline exceeded")
+
+// zeroTime is the deadline of await that never passes.
+var zeroTime time.Time
+
+// Blocking wraps a RingBuffer with blocking Put and Take. A blocked call first spins a
+// bounded number of attempts by the wait strategy, which keeps the latency low under load,
+// then parks until the other side makes progress, which costs nearly no CPU when idle.
+//
+// The non-blocking methods of the wrapped buffer are still available, they also wake the
+// parked goroutines, hence all access to the buffer should go through Blocking once wrapped.
+//
+// Close works like closing a channel: producers can't offer any more, consumers drain the
+// rest values then get ErrClosed. Values offered concurrently with Close may be left in
+// buffer.
+type Blocking[T any] struct {
+	RingBuffer[T]
+	closed   int32
+	spins    int
+	wait     WaitStrategy
+	profiler *Profiler
+	logger   logger
+	// see WithWatermarks, above is true once high reached until back to low
+	highMark uint64
+	lowMark  uint64
+	above    atomic.Bool
+	readable notifier
+	writable notifier
+}
+
+// NewBlocking wraps buffer, see WithSpinLimit and WithWaitStrategy for how it spins,
+// WithProfiler for how it retries, and WithLogger and WithWatermarks for the logs of close,
+// drops and occupancy.
+func NewBlocking[T any](buffer RingBuffer[T], opts ...Option) *Blocking[T] {
+	c := newConfig(opts)
+	return &Blocking[T]{
+		RingBuffer: buffer,
+		spins:      c.spinLimit,
+		wait:       c.wait,
+		profiler:   c.profiler,
+		logger:     newLogger(c),
+		highMark:   c.highMark,
+		lowMark:    c.lowMark,
+	}
+}
+
+// Put offers value, blocks while buffer is full. It panics if Blocking is closed, just like
+// send on a closed channel.
+func (b *Blocking[T]) Put(value T) {
+	if b.await(context.Background(), func() bool { return b.Offer(value) }, OpOffer, zeroTime) != nil {
+		panic("lfring: Put on closed buffer")
+	}
+}
+
+// OfferUntil offers value, blocks while buffer is full, but gives up and returns false once
+// deadline passed or Blocking closed. The wait strategy and parking are the same as Put, a
+// parked call wakes at the deadline.
+func (b *Blocking[T]) OfferUntil(value T, deadline time.Time) bool {
+	err := b.await(context.Background(), func() bool { return b.Offer(value) }, OpOffer, deadline)
+	if err == errDeadline {
+		b.logger.log(slog.LevelWarn, "lfring: value dropped at deadline", stateAttr(b.State()))
+	}
+	return err == nil
+}
+
+// Take polls a value, blocks while buffer is empty. Once Blocking is closed and drained, it
+// returns the zero value, just like receive from a closed channel.
+func (b *Blocking[T]) Take() T {
+	value, _ := b.PollWait()
+	return value
+}
+
+// PollWait polls a value, blocks while buffer is empty, returns ErrClosed once Blocking is
+// closed and drained.
+func (b *Blocking[T]) PollWait() (value T, err error) {
+	return b.Recv(context.Background())
+}
+
+// Send offers value, blocks while buffer is full. It returns ErrClosed if Blocking is
+// closed, or ctx.Err() if ctx done before sent.
+func (b *Blocking[T]) Send(ctx context.Context, value T) error {
+	return b.await(ctx, func() bool { return b.Offer(value) }, OpOffer, zeroTime)
+}
+
+// Recv polls a value, blocks while buffer is empty. It returns ErrClosed if Blocking is
+// closed and drained, or ctx.Err() if ctx done before received.
+func (b *Blocking[T]) Recv(ctx context.Context) (value T, err error) {
+	err = b.await(ctx, func() (success bool) {
+		value, success = b.Poll()
+		return
+	}, OpPoll, zeroTime)
+	return
+}
+
+// Close closes Blocking and wakes all the parked calls, it returns ErrClosed if closed
+// already.
+func (b *Blocking[T]) Close() error {
+	if !atomic.CompareAndSwapInt32(&b.closed, 0, 1) {
+		return ErrClosed
+	}
+	b.logger.log(slog.LevelInfo, "lfring: closed", stateAttr(b.State()))
+
+	b.readable.broadcast()
+	b.writable.broadcast()
+	return nil
+}
+
+// Closed reports whether Blocking is closed.
+func (b *Blocking[T]) Closed() bool {
+	return atomic.LoadInt32(&b.closed) == 1
+}
+
+// Readable returns a channel that closed once buffer becomes non-empty (or Blocking closed),
+// it's closed already if so at the call. It works with select alongside timers and context:
+//
+//	for {
+//		if v, ok := b.Poll(); ok {
+//			handle(v)
+//			continue
+//		}
+//		select {
+//		case <-b.Readable():
+//		case <-ctx.Done():
+//			return
+//		}
+//	}
+//
+// The readiness is a hint, other consumers may take the value first, so always Poll after.
+// Get a new channel for every wait, a channel fires only once.
+func (b *Blocking[T]) Readable() <-chan struct{} {
+	ready := b.readable.wait()
+	if b.Len() > 0 || b.Closed() {
+		return closedChan
+	}
+	return ready
+}
+
+// Writable returns a channel that closed once buffer has free space (or Blocking closed),
+// see Readable for the usage.
+func (b *Blocking[T]) Writable() <-chan struct{} {
+	ready := b.writable.wait()
+	if !bufferFull[T](b.RingBuffer) || b.Closed() {
+		return closedChan
+	}
+	return ready
+}
+
+// await retries try of op until success, spins by the wait strategy at first, then parks
+// until the other side makes progress between two tries. It returns errDeadline if deadline (zero means never) passed, ctx.Err()
+// if ctx done, or ErrClosed if Blocking closed, before success.
+func (b *Blocking[T]) await(ctx context.Context, try func() bool, op Op, deadline time.Time) error {
+	done := ctx.Done()
+	n := &b.readable
+	if op == OpOffer {
+		n = &b.writable
+	}
+	if b.profiler != nil {
+		try = b.profiler.profile(op, try)
+	}
+
+	var timeout <-chan time.Time
+	for attempt := 1; ; attempt++ {
+		if try() {
+			return nil
+		}
+		if b.Closed() {
+			// values may be published right before closed
+			if try() {
+				return nil
+			}
+			return ErrClosed
+		}
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return errDeadline
+		}
+		if attempt <= b.spins {
+			b.wait.Wait(attempt)
+			continue
+		}
+
+		if timeout == nil && !deadline.IsZero() {
+			timer := time.NewTimer(time.Until(deadline))
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		parked := n.wait()
+		if try() {
+			return nil
+		}
+		if b.Closed() {
+			continue
+		}
+		select {
+		case <-parked:
+		case <-timeout:
+		case <-done:
+		}
+	}
+}
+
+// Offer a value, return false if Blocking is closed or the wrapped Offer failed.
+func (b *Blocking[T]) Offer(value T) (success bool) {
+	if b.Closed() {
+		return false
+	}
+	if success = b.RingBuffer.Offer(value); success {
+		b.readable.broadcast()
+		b.watchHigh()
+	}
+	return
+}
+
+func (b *Blocking[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	if b.Closed() {
+		return
+	}
+	b.RingBuffer.SingleProducerOffer(valueSupplier)
+	b.readable.broadcast()
+	b.watchHigh()
+}
+
+func (b *Blocking[T]) Poll() (value T, success bool) {
+	if value, success = b.RingBuffer.Poll(); success {
+		b.writable.broadcast()
+		b.watchLow()
+	}
+	return
+}
+
+func (b *Blocking[T]) PollNBatched(n uint64) (values []T, count uint64) {
+	if values, count = b.RingBuffer.PollNBatched(n); count > 0 {
+		b.writable.broadcast()
+		b.watchLow()
+	}
+	return
+}
+
+func (b *Blocking[T]) PollBatchInto(dst []T) (count uint64) {
+	if count = b.RingBuffer.PollBatchInto(dst); count > 0 {
+		b.writable.broadcast()
+		b.watchLow()
+	}
+	return
+}
+
+func (b *Blocking[T]) SingleConsumerPoll(valueConsumer func(T)) {
+	b.RingBuffer.SingleConsumerPoll(valueConsumer)
+	b.writable.broadcast()
+	b.watchLow()
+}
+
+func (b *Blocking[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
+	if validCnt = b.RingBuffer.SingleConsumerPollVec(ret); validCnt > 0 {
+		b.writable.broadcast()
+		b.watchLow()
+	}
+	return
+}
+
+// watchHigh logs once the occupancy reached the high watermark, see WithWatermarks.
+func (b *Blocking[T]) watchHigh() {
+	if b.highMark == 0 || b.above.Load() || b.Len() < b.highMark {
+		return
+	}
+	if b.above.CompareAndSwap(false, true) {
+		b.logger.log(slog.LevelWarn, "lfring: high watermark reached", slog.Uint64("high", b.highMark), stateAttr(b.State()))
+	}
+}
+
+// watchLow logs once the occupancy fell back to the low watermark after the high one.
+func (b *Blocking[T]) watchLow() {
+	if !b.above.Load() || b.Len() > b.lowMark {
+		return
+	}
+	if b.above.CompareAndSwap(true, false) {
+		b.logger.log(slog.LevelInfo, "lfring: back to low watermark", slog.Uint64("low", b.lowMark), stateAttr(b.State()))
+	}
+}
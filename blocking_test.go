@@ -0,0 +1,170 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"sync"
+	"time"
+)
+
+func (s *MySuite) TestBlockingTakeParkUntilPut(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := NewBlocking[int](New[int](t, 4), WithSpinLimit(0))
+		taken := make(chan int)
+
+		// when
+		go func() { taken <- buffer.Take() }()
+		buffer.Put(7)
+
+		// then
+		c.Assert(<-taken, Equals, 7)
+	}
+}
+
+func (s *MySuite) TestBlockingPutParkWhileFull(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := NewBlocking[int](New[int](t, 4), WithSpinLimit(2))
+		var wg sync.WaitGroup
+
+		// when
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				buffer.Put(i)
+			}
+		}()
+
+		// then
+		for i := 0; i < 100; i++ {
+			c.Assert(buffer.Take(), Equals, i)
+		}
+		wg.Wait()
+	}
+}
+
+func (s *MySuite) TestBlockingOfferUntilDeadline(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 2), WithSpinLimit(4))
+	buffer.Put(1)
+	buffer.Put(2)
+
+	// when
+	start := time.Now()
+	offered := buffer.OfferUntil(3, start.Add(20*time.Millisecond))
+	elapsed := time.Since(start)
+
+	// then
+	c.Assert(offered, Equals, false)
+	c.Assert(elapsed >= 20*time.Millisecond, Equals, true)
+	c.Assert(elapsed < time.Second, Equals, true)
+
+	// when
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		buffer.Take()
+	}()
+
+	// then
+	c.Assert(buffer.OfferUntil(3, time.Now().Add(time.Second)), Equals, true)
+}
+
+func (s *MySuite) TestBlockingPollWaitUntilClosed(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 4), WithSpinLimit(0))
+	buffer.Put(1)
+	errs := make(chan error)
+
+	// when
+	go func() {
+		_, err := buffer.PollWait()
+		_, err = buffer.PollWait()
+		errs <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	// then
+	c.Assert(buffer.Close(), IsNil)
+	c.Assert(<-errs, Equals, ErrClosed)
+	c.Assert(buffer.Close(), Equals, ErrClosed)
+	c.Assert(buffer.Offer(2), Equals, false)
+	c.Assert(buffer.Take(), Equals, 0)
+	c.Assert(func() { buffer.Put(2) }, PanicMatches, ".*closed buffer")
+}
+
+func (s *MySuite) TestBlockingDrainAfterClosed(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](Classical, 4))
+	buffer.Put(1)
+	buffer.Put(2)
+
+	// when
+	buffer.Close()
+
+	// then
+	v, err := buffer.PollWait()
+	c.Assert(v, Equals, 1)
+	c.Assert(err, IsNil)
+	v, err = buffer.PollWait()
+	c.Assert(v, Equals, 2)
+	c.Assert(err, IsNil)
+	_, err = buffer.PollWait()
+	c.Assert(err, Equals, ErrClosed)
+}
+
+func (s *MySuite) TestBlockingReadableAndWritable(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 2))
+	ready := func(ch <-chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		case <-time.After(5 * time.Millisecond):
+			return false
+		}
+	}
+
+	// when
+	readable := buffer.Readable()
+
+	// then
+	c.Assert(ready(buffer.Writable()), Equals, true)
+	c.Assert(ready(readable), Equals, false)
+	buffer.Offer(1)
+	c.Assert(ready(readable), Equals, true)
+
+	// when
+	buffer.Offer(2)
+	writable := buffer.Writable()
+
+	// then
+	c.Assert(ready(writable), Equals, false)
+	buffer.Poll()
+	c.Assert(ready(writable), Equals, true)
+}
+
+func (s *MySuite) TestBlockingWritableWhenFull(c *C) {
+	for _, t := range append(bufferSet, Relaxed) {
+		// given a buffer offered until it refuses
+		buffer := NewBlocking[int](New[int](t, 4, WithShards(2)))
+		for buffer.Offer(0) {
+		}
+
+		// when
+		writable := buffer.Writable()
+
+		// then
+		select {
+		case <-writable:
+			c.Fatalf("%s: writable when full, %s", t, buffer)
+		default:
+		}
+		buffer.Poll()
+		select {
+		case <-writable:
+		case <-time.After(time.Second):
+			c.Fatalf("%s: not writable once polled", t)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package lfring
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Broker is an in-process broker of Kafka-like semantics over Multicast: every topic is a
+// Multicast that retains the latest values by the retention capacity, and every consumer
+// group of a topic has its own offset. The members of a group share the offset, so they
+// compete for the values, while the groups observe the whole topic independently.
+//
+// Publishing never blocks, a group that falls behind the retention skips to the earliest
+// retained value, see Lag for the monitoring.
+type Broker[T any] struct {
+	retention uint64
+	// of name to *brokerTopic[T], looked up without lock, only created under mu
+	topics sync.Map
+
+	// guards the creation of topics and the groups of every topic
+	mu sync.Mutex
+}
+
+type brokerTopic[T any] struct {
+	ring   *Multicast[T]
+	groups map[string]*Group[T]
+}
+
+// NewBroker build a Broker whose topics retain retention values each, retention expands to
+// power-of-two as New does.
+func NewBroker[T any](retention uint64) *Broker[T] {
+	return &Broker[T]{retention: retention}
+}
+
+// Publish appends value to topic and returns its offset in topic, topic is created on the
+// first use.
+func (b *Broker[T]) Publish(topic string, value T) (offset uint64) {
+	return b.topic(topic).ring.Offer(value)
+}
+
+// Subscribe joins group of topic, the group starts from the next value to be published if
+// it's new. The groups are created on the first use and live as long as the broker.
+func (b *Broker[T]) Subscribe(topic, group string) *Group[T] {
+	g, _ := b.group(topic, group, nil)
+	return g
+}
+
+// SubscribeFrom joins group of topic and moves the group to offset, e.g. an offset saved in
+// OffsetStore before restart. If offset has been overwritten already the group goes on from
+// the earliest retained one.
+func (b *Broker[T]) SubscribeFrom(topic, group string, offset uint64) *Group[T] {
+	g, created := b.group(topic, group, &offset)
+	if !created {
+		g.Seek(offset)
+	}
+	return g
+}
+
+// topic returns the topic of name, it only locks to create the topic.
+func (b *Broker[T]) topic(name string) *brokerTopic[T] {
+	if t, ok := b.topics.Load(name); ok {
+		return t.(*brokerTopic[T])
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.topicLocked(name)
+}
+
+func (b *Broker[T]) topicLocked(name string) *brokerTopic[T] {
+	if t, ok := b.topics.Load(name); ok {
+		return t.(*brokerTopic[T])
+	}
+	t := &brokerTopic[T]{ring: NewMulticast[T](b.retention), groups: make(map[string]*Group[T])}
+	b.topics.Store(name, t)
+	return t
+}
+
+func (b *Broker[T]) group(topic, name string, from *uint64) (g *Group[T], created bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t := b.topicLocked(topic)
+	if g, ok := t.groups[name]; ok {
+		return g, false
+	}
+
+	g = &Group[T]{ring: t.ring, next: t.ring.Tail()}
+	if from != nil {
+		g.next = *from
+	}
+	t.groups[name] = g
+	return g, true
+}
+
+// GroupLag is the progress of a consumer group, see Broker.Lags.
+type GroupLag struct {
+	Topic  string
+	Group  string
+	Offset uint64
+	Lag    uint64
+}
+
+// Lags returns the progress of every group, sorted by topic and group.
+func (b *Broker[T]) Lags() []GroupLag {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var lags []GroupLag
+	b.topics.Range(func(topic, t any) bool {
+		for name, g := range t.(*brokerTopic[T]).groups {
+			lags = append(lags, GroupLag{Topic: topic.(string), Group: name, Offset: g.Offset(), Lag: g.Lag()})
+		}
+		return true
+	})
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Group < lags[j].Group
+	})
+	return lags
+}
+
+// Group is a consumer group of a Broker topic, it's safe for concurrent use by the members.
+type Group[T any] struct {
+	// first for the 64-bit atomics on 32-bit platforms
+	next uint64
+	ring *Multicast[T]
+}
+
+// Poll returns the value at the offset of group along with the offset, and moves the group
+// on, return false if there's no more published value yet or the claim lost in contention.
+func (g *Group[T]) Poll() (value T, offset uint64, success bool) {
+	return g.ring.pollAt(&g.next)
+}
+
+// Offset returns the offset of the value next Poll returns, to persist by OffsetStore.
+func (g *Group[T]) Offset() uint64 {
+	return atomic.LoadUint64(&g.next)
+}
+
+// Seek moves the group to offset, forward or backward.
+func (g *Group[T]) Seek(offset uint64) {
+	atomic.StoreUint64(&g.next, offset)
+}
+
+// Lag returns how many published values the group hasn't polled yet, it's larger than the
+// retention once the group fell behind, the next Poll skips the overwritten ones.
+func (g *Group[T]) Lag() uint64 {
+	tail := g.ring.Tail()
+	if next := atomic.LoadUint64(&g.next); tail > next {
+		return tail - next
+	}
+	return 0
+}
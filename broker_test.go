@@ -0,0 +1,108 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+func (s *MySuite) TestBrokerGroups(c *C) {
+	// given
+	broker := NewBroker[string](8)
+	billing := broker.Subscribe("orders", "billing")
+	audit := broker.Subscribe("orders", "audit")
+
+	// when
+	broker.Publish("orders", "a")
+	offset := broker.Publish("orders", "b")
+	broker.Publish("users", "x")
+	first, firstOffset, _ := billing.Poll()
+	second, _, _ := broker.Subscribe("orders", "billing").Poll()
+	_, _, drained := billing.Poll()
+	fromAudit, _, _ := audit.Poll()
+
+	// then
+	c.Assert(offset, Equals, uint64(1))
+	c.Assert(first, Equals, "a")
+	c.Assert(firstOffset, Equals, uint64(0))
+	c.Assert(second, Equals, "b")
+	c.Assert(drained, Equals, false)
+	c.Assert(fromAudit, Equals, "a")
+	c.Assert(broker.Lags(), DeepEquals, []GroupLag{
+		{Topic: "orders", Group: "audit", Offset: 1, Lag: 1},
+		{Topic: "orders", Group: "billing", Offset: 2, Lag: 0},
+	})
+}
+
+func (s *MySuite) TestBrokerSubscribeFrom(c *C) {
+	// given
+	broker := NewBroker[int](4)
+	for i := 0; i < 6; i++ {
+		broker.Publish("t", i)
+	}
+
+	// when
+	replay := broker.SubscribeFrom("t", "g", 3)
+	v, _, _ := replay.Poll()
+	overwritten := broker.SubscribeFrom("t", "g", 0)
+	earliest, offset, _ := overwritten.Poll()
+
+	// then
+	c.Assert(v, Equals, 3)
+	c.Assert(overwritten, Equals, replay)
+	c.Assert(earliest, Equals, 2)
+	c.Assert(offset, Equals, uint64(2))
+	c.Assert(replay.Lag(), Equals, uint64(3))
+}
+
+func (s *MySuite) TestBrokerGroupMembersConcurrency(c *C) {
+	// given
+	const total, members = 1000, 4
+	broker := NewBroker[int](2048)
+	group := broker.Subscribe("t", "g")
+	for i := 0; i < total; i++ {
+		broker.Publish("t", i)
+	}
+
+	// when the members compete for the values
+	var wg sync.WaitGroup
+	var polled int64
+	seen := make([]int32, total)
+	wg.Add(members)
+	for i := 0; i < members; i++ {
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt64(&polled) < total {
+				if v, _, ok := group.Poll(); ok {
+					atomic.AddInt32(&seen[v], 1)
+					atomic.AddInt64(&polled, 1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// then every value is polled once
+	for i := range seen {
+		c.Assert(seen[i], Equals, int32(1))
+	}
+}
+
+func (s *MySuite) TestBrokerPublishDoesNotLock(c *C) {
+	// given a topic, and the lock of topics and groups held
+	b := NewBroker[int](8)
+	g := b.Subscribe("orders", "billing")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// when
+	b.Publish("orders", 1)
+
+	// then
+	v, _, ok := g.Poll()
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 1)
+}
@@ -0,0 +1,158 @@
+package lfring
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Budgeted wraps a RingBuffer with the size accounting of values and a total bytes budget
+// enforced at offer, so a spike of payload sizes is rejected rather than OOM the process
+// while the ring is still far from full by count:
+//
+//	buffer := lfring.NewBudgeted(lfring.New[[]byte](lfring.NodeBased, 4096), 64<<20, nil)
+//
+// The size of value is reserved before offered and given back when polled. A value larger
+// than the budget (or than the budget left) is rejected, the concurrent reservations may
+// reject a little early but never overrun the budget. The methods other than offer and poll
+// of the wrapped buffer are available as is.
+type Budgeted[T any] struct {
+	RingBuffer[T]
+	sizeOf   func(T) int
+	budget   int64
+	used     atomic.Int64
+	rejected atomic.Uint64
+}
+
+// NewBudgeted wraps buffer with the budget in bytes. sizeOf returns the size of a value, nil
+// means the length of []byte and string values, and the shallow size (unsafe.Sizeof) of the
+// other types.
+func NewBudgeted[T any](buffer RingBuffer[T], budget int64, sizeOf func(T) int) *Budgeted[T] {
+	if sizeOf == nil {
+		sizeOf = defaultSizeOf[T]()
+	}
+	return &Budgeted[T]{RingBuffer: buffer, sizeOf: sizeOf, budget: budget}
+}
+
+func defaultSizeOf[T any]() func(T) int {
+	var zero T
+	switch any(zero).(type) {
+	case []byte:
+		return func(v T) int { return len(any(v).([]byte)) }
+	case string:
+		return func(v T) int { return len(any(v).(string)) }
+	default:
+		size := int(unsafe.Sizeof(zero))
+		return func(T) int { return size }
+	}
+}
+
+// Offer a value, return false if its size doesn't fit the budget left, buffer is full or the
+// claim lost in contention.
+func (b *Budgeted[T]) Offer(value T) (success bool) {
+	size := int64(b.sizeOf(value))
+	if !b.reserve(size) {
+		return false
+	}
+	if !b.RingBuffer.Offer(value) {
+		b.used.Add(-size)
+		return false
+	}
+	return true
+}
+
+// SingleProducerOffer offers values from valueSupplier until finish, buffer full, or a value
+// doesn't fit the budget left, which is dropped (and counted by Rejected). The caller must be
+// the only producer.
+func (b *Budgeted[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	b.RingBuffer.SingleProducerOffer(func() (v T, finish bool) {
+		if v, finish = valueSupplier(); finish {
+			return
+		}
+		if !b.reserve(int64(b.sizeOf(v))) {
+			return v, true
+		}
+		return v, false
+	})
+}
+
+func (b *Budgeted[T]) reserve(size int64) bool {
+	if b.used.Add(size) > b.budget {
+		b.used.Add(-size)
+		b.rejected.Add(1)
+		return false
+	}
+	return true
+}
+
+func (b *Budgeted[T]) release(values []T) {
+	var size int64
+	for _, v := range values {
+		size += int64(b.sizeOf(v))
+	}
+	b.used.Add(-size)
+}
+
+// Poll head value, gives back its size.
+func (b *Budgeted[T]) Poll() (value T, success bool) {
+	if value, success = b.RingBuffer.Poll(); success {
+		b.used.Add(-int64(b.sizeOf(value)))
+	}
+	return
+}
+
+// PollNBatched polls at most n values, see PollBatchInto.
+func (b *Budgeted[T]) PollNBatched(n uint64) (values []T, count uint64) {
+	values, count = b.RingBuffer.PollNBatched(n)
+	b.release(values[:count])
+	return
+}
+
+// PollBatchInto fills dst with the head values, returns how many values are filled.
+func (b *Budgeted[T]) PollBatchInto(dst []T) (count uint64) {
+	count = b.RingBuffer.PollBatchInto(dst)
+	b.release(dst[:count])
+	return count
+}
+
+// SingleConsumerPoll passes every value in buffer to valueConsumer, the caller must be the
+// only consumer.
+func (b *Budgeted[T]) SingleConsumerPoll(valueConsumer func(T)) {
+	b.RingBuffer.SingleConsumerPoll(func(v T) {
+		b.used.Add(-int64(b.sizeOf(v)))
+		valueConsumer(v)
+	})
+}
+
+// SingleConsumerPollVec fills ret with values in buffer, the caller must be the only
+// consumer.
+func (b *Budgeted[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
+	validCnt = b.RingBuffer.SingleConsumerPollVec(ret)
+	b.release(ret[:validCnt])
+	return validCnt
+}
+
+// MemoryUsage is the usage of the wrapped buffer (if it reports), with the bytes accounted
+// as Payload.
+func (b *Budgeted[T]) MemoryUsage() MemoryUsage {
+	var u MemoryUsage
+	if m, ok := b.RingBuffer.(MemoryReporter); ok {
+		u = m.MemoryUsage()
+	}
+	u.Payload += uint64(max(b.used.Load(), 0))
+	return u
+}
+
+// Used returns the bytes reserved by the values not polled yet.
+func (b *Budgeted[T]) Used() int64 {
+	return b.used.Load()
+}
+
+// Budget returns the total bytes budget.
+func (b *Budgeted[T]) Budget() int64 {
+	return b.budget
+}
+
+// Rejected returns how many values were rejected by the budget.
+func (b *Budgeted[T]) Rejected() uint64 {
+	return b.rejected.Load()
+}
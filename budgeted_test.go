@@ -0,0 +1,67 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestBudgetedRejectOverBudget(c *C) {
+	// given
+	buffer := NewBudgeted(New[[]byte](NodeBased, 8), 10, nil)
+
+	// when
+	results := []bool{
+		buffer.Offer(make([]byte, 6)),
+		buffer.Offer(make([]byte, 6)),
+		buffer.Offer(make([]byte, 4)),
+		buffer.Offer(make([]byte, 11)),
+	}
+	usedFull := buffer.Used()
+	v, _ := buffer.Poll()
+
+	// then
+	c.Assert(results, DeepEquals, []bool{true, false, true, false})
+	c.Assert(usedFull, Equals, int64(10))
+	c.Assert(len(v), Equals, 6)
+	c.Assert(buffer.Used(), Equals, int64(4))
+	c.Assert(buffer.Rejected(), Equals, uint64(2))
+	c.Assert(buffer.Offer(make([]byte, 6)), Equals, true)
+}
+
+func (s *MySuite) TestBudgetedGiveBackOnBatchPoll(c *C) {
+	// given
+	buffer := NewBudgeted(New[string](NodeBased, 4), 100, func(v string) int { return len(v) * 2 })
+	i := 0
+	buffer.SingleProducerOffer(func() (string, bool) {
+		i++
+		return "abcde", i > 3
+	})
+
+	// when
+	used := buffer.Used()
+	dst := make([]string, 2)
+	count := buffer.SingleConsumerPollVec(dst)
+	values, _ := buffer.PollNBatched(4)
+
+	// then
+	c.Assert(used, Equals, int64(30))
+	c.Assert(count, Equals, uint64(2))
+	c.Assert(values, DeepEquals, []string{"abcde"})
+	c.Assert(buffer.Used(), Equals, int64(0))
+}
+
+func (s *MySuite) TestBudgetedStopSingleProducerOfferOverBudget(c *C) {
+	// given
+	buffer := NewBudgeted(New[int64](NodeBased, 8), 16, nil)
+
+	// when
+	i := int64(0)
+	buffer.SingleProducerOffer(func() (int64, bool) {
+		i++
+		return i, false
+	})
+
+	// then
+	c.Assert(buffer.Len(), Equals, uint64(2))
+	c.Assert(buffer.Used(), Equals, int64(16))
+	c.Assert(buffer.Rejected(), Equals, uint64(1))
+}
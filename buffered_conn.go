@@ -0,0 +1,132 @@
+package lfring
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// BufferedConn interposes a ByteRing between the writes of application and the wrapped
+// net.Conn: Write copies into the ring and returns, a flusher goroutine writes whatever
+// accumulated to the connection, so the small writes during a syscall are coalesced into
+// the next larger one. Write blocks only while the ring is full.
+//
+// Read and the other methods go to the wrapped connection directly. An error of the
+// connection write is returned by the next Write, Flush or Close.
+type BufferedConn struct {
+	net.Conn
+	ring *ByteRing
+
+	mu       sync.Mutex
+	closing  int32
+	err      atomic.Pointer[error]
+	readable notifier
+	writable notifier
+	done     chan struct{}
+}
+
+// NewBufferedConn wraps conn with a ring of size bytes (expands to power-of-two), and starts
+// the flusher.
+func NewBufferedConn(conn net.Conn, size uint64) *BufferedConn {
+	c := &BufferedConn{
+		Conn: conn,
+		ring: NewByteRing(size),
+		done: make(chan struct{}),
+	}
+	go c.flush()
+	return c
+}
+
+// Write copies p into the ring, blocks while the ring is full. It returns ErrClosed after
+// Close, or the error of a previous connection write.
+func (c *BufferedConn) Write(p []byte) (n int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		if err = c.error(); err != nil {
+			return n, err
+		}
+		if atomic.LoadInt32(&c.closing) == 1 {
+			return n, ErrClosed
+		}
+
+		n += c.ring.POD.Write(p[n:])
+		c.readable.broadcast()
+		if n == len(p) {
+			return n, nil
+		}
+
+		parked := c.writable.wait()
+		if c.ring.Len() < c.ring.Cap() || c.error() != nil {
+			continue
+		}
+		select {
+		case <-parked:
+		case <-c.done:
+		}
+	}
+}
+
+// Flush blocks until the flusher wrote everything in the ring to the connection, it returns
+// the error of connection write if any.
+func (c *BufferedConn) Flush() error {
+	for {
+		parked := c.writable.wait()
+		if c.ring.Len() == 0 || c.error() != nil {
+			return c.error()
+		}
+		select {
+		case <-parked:
+		case <-c.done:
+			return c.error()
+		}
+	}
+}
+
+// Close flushes the ring, stops the flusher, then closes the connection. It returns
+// ErrClosed if closed already.
+func (c *BufferedConn) Close() error {
+	if !atomic.CompareAndSwapInt32(&c.closing, 0, 1) {
+		return ErrClosed
+	}
+
+	c.mu.Lock()
+	c.readable.broadcast()
+	c.mu.Unlock()
+	<-c.done
+
+	err := c.error()
+	if closeErr := c.Conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (c *BufferedConn) error() error {
+	if err := c.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (c *BufferedConn) flush() {
+	defer close(c.done)
+	for {
+		parked := c.readable.wait()
+		if c.ring.Len() == 0 {
+			if atomic.LoadInt32(&c.closing) == 1 {
+				return
+			}
+			<-parked
+			continue
+		}
+
+		_, err := c.ring.WriteTo(c.Conn)
+		c.writable.broadcast()
+		if err != nil {
+			c.err.Store(&err)
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package lfring
+
+import (
+	"errors"
+	. "gopkg.in/check.v1"
+	"io"
+	"net"
+	"sync"
+)
+
+// countingConn counts the writes reach the connection.
+type countingConn struct {
+	net.Conn
+	mu     sync.Mutex
+	writes int
+	data   []byte
+	err    error
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.writes++
+	c.data = append(c.data, p...)
+	return len(p), nil
+}
+
+func (c *countingConn) Close() error {
+	return nil
+}
+
+func (s *MySuite) TestBufferedConnCoalescesWrites(c *C) {
+	// given
+	underlying := &countingConn{}
+	conn := NewBufferedConn(underlying, 16)
+
+	// when
+	var expected []byte
+	for i := 0; i < 100; i++ {
+		n, err := conn.Write([]byte{byte(i)})
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, 1)
+		expected = append(expected, byte(i))
+	}
+	c.Assert(conn.Flush(), IsNil)
+
+	// then
+	underlying.mu.Lock()
+	c.Assert(underlying.data, DeepEquals, expected)
+	c.Assert(underlying.writes <= 100, Equals, true)
+	underlying.mu.Unlock()
+	c.Assert(conn.Close(), IsNil)
+	_, err := conn.Write([]byte{1})
+	c.Assert(err, Equals, ErrClosed)
+	c.Assert(conn.Close(), Equals, ErrClosed)
+}
+
+func (s *MySuite) TestBufferedConnLargeWriteThroughPipe(c *C) {
+	// given
+	client, server := net.Pipe()
+	conn := NewBufferedConn(client, 8)
+	payload := make([]byte, 1000)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	received := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(server)
+		received <- data
+	}()
+
+	// when
+	n, err := conn.Write(payload)
+	c.Assert(conn.Close(), IsNil)
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, len(payload))
+	c.Assert(<-received, DeepEquals, payload)
+}
+
+func (s *MySuite) TestBufferedConnReportsWriteError(c *C) {
+	// given
+	failure := errors.New("failure")
+	conn := NewBufferedConn(&countingConn{err: failure}, 8)
+
+	// when
+	_, _ = conn.Write([]byte("abc"))
+	flushErr := conn.Flush()
+
+	// then
+	c.Assert(flushErr, Equals, failure)
+	_, err := conn.Write([]byte("abc"))
+	c.Assert(err, Equals, failure)
+	c.Assert(conn.Close(), Equals, failure)
+}
@@ -0,0 +1,172 @@
+package lfring
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+)
+
+// ErrFull is returned when ByteRing becomes full before the source is drained.
+var ErrFull = errors.New("lfring: buffer is full")
+
+// ByteRing is a single-producer single-consumer ring buffer of bytes, built on POD, for
+// streams like network and log data: Write and Read copy in bulk (as the io.Writer and
+// io.Reader which don't block, like bytes.Buffer), ReadView reads in place, WriteVec and
+// ReadVec move multi-segment packets without coalescing, ReadFrom and WriteTo let io.Copy
+// move bytes straight in and out of the storage.
+type ByteRing struct {
+	*POD[byte]
+}
+
+// NewByteRing build a ByteRing with capacity, expands to power-of-two as New does.
+func NewByteRing(capacity uint64) *ByteRing {
+	pod, _ := NewPOD[byte](capacity)
+	return &ByteRing{POD: pod}
+}
+
+// Write implements io.Writer, it writes p as many bytes as ring can hold, returns ErrFull if
+// not all written. It must be called by the only producer.
+func (r *ByteRing) Write(p []byte) (n int, err error) {
+	if n = r.POD.Write(p); n < len(p) {
+		return n, ErrFull
+	}
+	return n, nil
+}
+
+// Read implements io.Reader, it reads to p as many bytes as ring has, returns io.EOF if ring
+// is empty, like bytes.Buffer. It must be called by the only consumer.
+func (r *ByteRing) Read(p []byte) (n int, err error) {
+	if n = r.POD.Read(p); n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// ReadView returns a view of at most n bytes at head without copying, which aliases the
+// storage of ring, so a parser can decode in place and call commit to consume the view only
+// after success. The view is shorter than n if there are fewer bytes, or the bytes wrap
+// around the end of storage (then the rest come with the next view), it's empty only if the
+// ring is empty.
+//
+// The view stays valid until commit, the producer never overwrites it before that. Like
+// Read, it must be called by the only consumer, and there's at most one view at a time.
+func (r *ByteRing) ReadView(n int) (view []byte, commit func()) {
+	head := r.head
+	tail := atomic.LoadUint64(&r.tail)
+	start := int(head & r.mask)
+	n = min(n, int(tail-head), len(r.storage)-start)
+	view = r.storage[start : start+n : start+n]
+
+	committed := false
+	return view, func() {
+		if committed {
+			return
+		}
+		committed = true
+		atomic.StoreUint64(&r.head, head+uint64(n))
+	}
+}
+
+// WriteVec writes the segments of bufs in order as many bytes as ring can hold, returns how
+// many bytes are written, so a multi-segment packet gets in without coalescing it first.
+// bufs itself is not consumed. Like Write, it must be called by the only producer.
+func (r *ByteRing) WriteVec(bufs net.Buffers) (n int64) {
+	tail := r.tail
+	free := r.mask + 1 - (tail - atomic.LoadUint64(&r.head))
+	for _, buf := range bufs {
+		if free == 0 {
+			break
+		}
+		m := min(uint64(len(buf)), free)
+		r.copyIn(tail, buf[:m])
+		tail += m
+		free -= m
+		n += int64(m)
+	}
+
+	atomic.StoreUint64(&r.tail, tail)
+	return n
+}
+
+// ReadVec returns at most n bytes at head as the spans aliasing the storage (two at most,
+// when the bytes wrap around), along with commit to consume them, see ReadView. The spans
+// can go to a writev syscall as is:
+//
+//	bufs, commit := ring.ReadVec(math.MaxInt)
+//	if _, err := bufs.WriteTo(conn); err == nil {
+//		commit()
+//	}
+func (r *ByteRing) ReadVec(n int) (bufs net.Buffers, commit func()) {
+	head := r.head
+	tail := atomic.LoadUint64(&r.tail)
+	n = min(n, int(tail-head))
+	start := int(head & r.mask)
+	if first := min(n, len(r.storage)-start); first > 0 {
+		bufs = append(bufs, r.storage[start:start+first:start+first])
+		if rest := n - first; rest > 0 {
+			bufs = append(bufs, r.storage[:rest:rest])
+		}
+	}
+
+	committed := false
+	return bufs, func() {
+		if committed {
+			return
+		}
+		committed = true
+		atomic.StoreUint64(&r.head, head+uint64(n))
+	}
+}
+
+// ReadFrom implements io.ReaderFrom, it reads from src into the free region of storage
+// directly (the free region is two spans at most), until EOF (returns nil), an error of src,
+// or ring becomes full (returns ErrFull). So io.Copy(ring, src) doesn't need an intermediate
+// buffer. It must be called by the only producer.
+func (r *ByteRing) ReadFrom(src io.Reader) (n int64, err error) {
+	for {
+		tail := r.tail
+		free := r.mask + 1 - (tail - atomic.LoadUint64(&r.head))
+		if free == 0 {
+			return n, ErrFull
+		}
+
+		start := tail & r.mask
+		span := r.storage[start : start+min(free, r.mask+1-start)]
+		m, err := src.Read(span)
+		if m > 0 {
+			atomic.StoreUint64(&r.tail, tail+uint64(m))
+			n += int64(m)
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// WriteTo implements io.WriterTo, it writes the bytes of storage to dst directly (two spans
+// at most), until ring is empty (returns nil) or an error of dst. So io.Copy(dst, ring)
+// doesn't need an intermediate buffer. It must be called by the only consumer.
+func (r *ByteRing) WriteTo(dst io.Writer) (n int64, err error) {
+	for {
+		view, _ := r.ReadView(int(r.mask + 1))
+		if len(view) == 0 {
+			return n, nil
+		}
+
+		m, err := dst.Write(view)
+		if m > 0 {
+			atomic.StoreUint64(&r.head, r.head+uint64(m))
+			n += int64(m)
+		}
+		if err != nil {
+			return n, err
+		}
+		if m < len(view) {
+			return n, io.ErrShortWrite
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package lfring
+
+import (
+	"bytes"
+	. "gopkg.in/check.v1"
+	"io"
+	"net"
+	"strings"
+)
+
+func (s *MySuite) TestByteRingReadViewCommitsOnSuccess(c *C) {
+	// given
+	ring := NewByteRing(8)
+	ring.Write([]byte("hello"))
+
+	// when
+	view, _ := ring.ReadView(3)
+
+	// then not committed, the view is read again
+	c.Assert(string(view), Equals, "hel")
+	c.Assert(ring.Len(), Equals, uint64(5))
+
+	// when
+	view, commit := ring.ReadView(3)
+	commit()
+	commit()
+
+	// then
+	c.Assert(string(view), Equals, "hel")
+	c.Assert(ring.Len(), Equals, uint64(2))
+}
+
+func (s *MySuite) TestByteRingReadViewAtWrap(c *C) {
+	// given
+	ring := NewByteRing(8)
+	ring.Write([]byte("012345"))
+	dst := make([]byte, 6)
+	ring.Read(dst)
+	ring.Write([]byte("abcdef"))
+
+	// when
+	first, commitFirst := ring.ReadView(6)
+	commitFirst()
+	second, commitSecond := ring.ReadView(6)
+	commitSecond()
+	empty, _ := ring.ReadView(6)
+
+	// then
+	c.Assert(string(first), Equals, "ab")
+	c.Assert(string(second), Equals, "cdef")
+	c.Assert(len(empty), Equals, 0)
+	c.Assert(cap(first), Equals, 2)
+}
+
+func (s *MySuite) TestByteRingWriteVecAndReadVec(c *C) {
+	// given
+	ring := NewByteRing(8)
+	ring.Write([]byte("xxxxx"))
+	ring.Read(make([]byte, 5))
+
+	// when
+	written := ring.WriteVec(net.Buffers{[]byte("head:"), []byte("body"), []byte("tail")})
+	bufs, commit := ring.ReadVec(100)
+
+	// then
+	c.Assert(written, Equals, int64(8))
+	c.Assert(len(bufs), Equals, 2)
+	c.Assert(string(bufs[0]), Equals, "hea")
+	c.Assert(string(bufs[1]), Equals, "d:bod")
+	c.Assert(ring.Len(), Equals, uint64(8))
+
+	// when
+	var out bytes.Buffer
+	_, err := bufs.WriteTo(&out)
+	commit()
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(out.String(), Equals, "head:bod")
+	c.Assert(ring.Len(), Equals, uint64(0))
+	empty, _ := ring.ReadVec(100)
+	c.Assert(len(empty), Equals, 0)
+}
+
+func (s *MySuite) TestByteRingReadFromAndWriteTo(c *C) {
+	// given
+	ring := NewByteRing(8)
+	ring.Write([]byte("xxxxxx"))
+	ring.Read(make([]byte, 6))
+
+	// when
+	readFrom, err := io.Copy(ring, strings.NewReader("abcde"))
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(readFrom, Equals, int64(5))
+
+	// when
+	var out bytes.Buffer
+	wroteTo, err := io.Copy(&out, ring)
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(wroteTo, Equals, int64(5))
+	c.Assert(out.String(), Equals, "abcde")
+	c.Assert(ring.Len(), Equals, uint64(0))
+}
+
+func (s *MySuite) TestByteRingReadFromUntilFull(c *C) {
+	// given
+	ring := NewByteRing(4)
+
+	// when
+	n, err := ring.ReadFrom(strings.NewReader("abcdef"))
+
+	// then
+	c.Assert(err, Equals, ErrFull)
+	c.Assert(n, Equals, int64(4))
+	view, _ := ring.ReadView(4)
+	c.Assert(string(view), Equals, "abcd")
+}
@@ -0,0 +1,124 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNoResponse is returned by Call when the responses ring closed before the response
+// arrived.
+var ErrNoResponse = errors.New("lfring: no response")
+
+// Request is a request through the requests ring of Caller, the responder must answer with
+// a Response of the same ID.
+type Request[Q any] struct {
+	ID   uint64
+	Body Q
+}
+
+// Response is the answer to the Request of ID, Err is returned by Call as is.
+type Response[R any] struct {
+	ID   uint64
+	Body R
+	Err  error
+}
+
+// Caller turns a pair of rings into an in-process RPC channel: Call sends a request through
+// one ring, and waits the response of the same ID on the other. The IDs are assigned and
+// correlated internally, a dispatcher goroutine completes the Future of the waiting calls,
+// so the responses may come back in any order, e.g. by several responders.
+//
+// The dispatcher must be the only consumer of responses, it exits once responses is closed,
+// see Close.
+type Caller[Q, R any] struct {
+	requests  *Blocking[Request[Q]]
+	responses *Blocking[Response[R]]
+	nextID    uint64
+	pending   sync.Map
+	done      chan struct{}
+}
+
+// NewCaller build a Caller over requests and responses, and starts the dispatcher.
+func NewCaller[Q, R any](requests *Blocking[Request[Q]], responses *Blocking[Response[R]]) *Caller[Q, R] {
+	c := &Caller[Q, R]{
+		requests:  requests,
+		responses: responses,
+		done:      make(chan struct{}),
+	}
+	go c.dispatch()
+	return c
+}
+
+func (c *Caller[Q, R]) dispatch() {
+	for {
+		response, err := c.responses.PollWait()
+		if err != nil {
+			break
+		}
+		// the call has given up if not found
+		if future, ok := c.pending.LoadAndDelete(response.ID); ok {
+			future.(*Future[R]).Complete(response.Body, response.Err)
+		}
+	}
+
+	// the calls stored before done closed are visible here, the others see done closed
+	close(c.done)
+	c.pending.Range(func(id, future any) bool {
+		future.(*Future[R]).Complete(*new(R), ErrNoResponse)
+		return true
+	})
+}
+
+// Call sends body as a request and waits its response, the timeout is given by ctx (e.g.
+// context.WithTimeout). It returns the error of the response, ctx.Err() if ctx done before
+// answered, ErrClosed if requests is closed, or ErrNoResponse if responses is closed. The
+// response of a call gave up is discarded.
+func (c *Caller[Q, R]) Call(ctx context.Context, body Q) (R, error) {
+	var zero R
+	id := atomic.AddUint64(&c.nextID, 1)
+	future := NewFuture[R]()
+	c.pending.Store(id, future)
+	defer c.pending.Delete(id)
+	select {
+	case <-c.done:
+		return zero, ErrNoResponse
+	default:
+	}
+
+	if err := c.requests.Send(ctx, Request[Q]{ID: id, Body: body}); err != nil {
+		return zero, err
+	}
+	return future.Wait(ctx)
+}
+
+// Close closes responses, and waits the dispatcher exits. The calls waiting get
+// ErrNoResponse.
+func (c *Caller[Q, R]) Close() error {
+	err := c.responses.Close()
+	<-c.done
+	return err
+}
+
+// Serve answers the requests by handler until ctx done (returns ctx.Err()) or requests
+// closed and drained (returns nil). More than one Serve can share the rings.
+func Serve[Q, R any](ctx context.Context, requests *Blocking[Request[Q]], responses *Blocking[Response[R]], handler func(Q) (R, error)) error {
+	for {
+		request, err := requests.Recv(ctx)
+		if err == ErrClosed {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		body, err := handler(request.Body)
+		if err := responses.Send(ctx, Response[R]{ID: request.ID, Body: body, Err: err}); err != nil {
+			if err == ErrClosed {
+				return nil
+			}
+			return err
+		}
+	}
+}
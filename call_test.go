@@ -0,0 +1,68 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func newCallRings() (*Blocking[Request[int]], *Blocking[Response[string]]) {
+	return NewBlocking(New[Request[int]](NodeBased, 8)), NewBlocking(New[Response[string]](NodeBased, 8))
+}
+
+func (s *MySuite) TestCallCorrelatesResponses(c *C) {
+	// given
+	requests, responses := newCallRings()
+	caller := NewCaller(requests, responses)
+	errOdd := errors.New("odd")
+	go Serve(context.Background(), requests, responses, func(q int) (string, error) {
+		if q%2 == 1 {
+			return "", errOdd
+		}
+		return strconv.Itoa(q), nil
+	})
+	var wg sync.WaitGroup
+
+	// when
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			defer wg.Done()
+			r, err := caller.Call(context.Background(), i)
+
+			// then
+			if i%2 == 1 {
+				c.Check(err, Equals, errOdd)
+			} else {
+				c.Check(err, IsNil)
+				c.Check(r, Equals, strconv.Itoa(i))
+			}
+		}(i)
+	}
+	wg.Wait()
+	requests.Close()
+	c.Assert(caller.Close(), IsNil)
+}
+
+func (s *MySuite) TestCallTimeout(c *C) {
+	// given nobody serves
+	requests, responses := newCallRings()
+	caller := NewCaller(requests, responses)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := caller.Call(ctx, 1)
+	// the late response is discarded
+	request, _ := requests.Poll()
+	responses.Put(Response[string]{ID: request.ID, Body: "late"})
+	c.Assert(caller.Close(), IsNil)
+	_, closed := caller.Call(context.Background(), 2)
+
+	// then
+	c.Assert(err, Equals, context.DeadlineExceeded)
+	c.Assert(closed, Equals, ErrNoResponse)
+}
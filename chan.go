@@ -0,0 +1,73 @@
+package lfring
+
+import (
+	"context"
+)
+
+// Chan is a channel-like adapter over a NodeBased ring buffer, intended as the drop-in
+// replacement target when migrating from a buffered channel:
+//
+//	ch <- v              err := c.Send(ctx, v)
+//	v, ok := <-ch        v, err := c.Recv(ctx)
+//	select default       c.TrySend(v) / c.TryRecv()
+//	close(ch)            c.Close()
+//	len(ch), cap(ch)     c.Len(), c.Cap()
+//
+// Like a channel, values are received in FIFO order (the order senders claim slots), a
+// full Chan blocks senders and an empty one blocks receivers, and receivers drain the rest
+// values after Close. Unlike a channel: capacity expands to power-of-two and can't be zero,
+// Send on a closed Chan returns ErrClosed rather than panics, Recv reports the drained closed
+// Chan by ErrClosed, and both return ctx.Err() once ctx done.
+type Chan[T any] struct {
+	b *Blocking[T]
+}
+
+// NewChan build a Chan with capacity, see NewBlocking for the options.
+func NewChan[T any](capacity uint64, opts ...Option) *Chan[T] {
+	return &Chan[T]{b: NewBlocking[T](New[T](NodeBased, capacity), opts...)}
+}
+
+// Send value, blocks while Chan is full, see Blocking.Send.
+func (c *Chan[T]) Send(ctx context.Context, value T) error {
+	return c.b.Send(ctx, value)
+}
+
+// Recv a value, blocks while Chan is empty, see Blocking.Recv.
+func (c *Chan[T]) Recv(ctx context.Context) (value T, err error) {
+	return c.b.Recv(ctx)
+}
+
+// TrySend value without blocking, return false if Chan is full or closed.
+func (c *Chan[T]) TrySend(value T) bool {
+	return c.b.Offer(value)
+}
+
+// TryRecv a value without blocking, return false if Chan is empty.
+func (c *Chan[T]) TryRecv() (value T, success bool) {
+	return c.b.Poll()
+}
+
+// Readable returns a channel that closed once Chan becomes non-empty, see Blocking.Readable.
+func (c *Chan[T]) Readable() <-chan struct{} {
+	return c.b.Readable()
+}
+
+// Writable returns a channel that closed once Chan has free space, see Blocking.Readable.
+func (c *Chan[T]) Writable() <-chan struct{} {
+	return c.b.Writable()
+}
+
+// Close closes Chan, it returns ErrClosed if closed already.
+func (c *Chan[T]) Close() error {
+	return c.b.Close()
+}
+
+// Len returns the approximate number of values in Chan.
+func (c *Chan[T]) Len() uint64 {
+	return c.b.Len()
+}
+
+// Cap returns the real (power-of-two) capacity.
+func (c *Chan[T]) Cap() uint64 {
+	return c.b.Cap()
+}
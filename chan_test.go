@@ -0,0 +1,79 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"sync"
+	"time"
+)
+
+func (s *MySuite) TestChanSendRecvInOrder(c *C) {
+	// given
+	ch := NewChan[int](4)
+	ctx := context.Background()
+
+	// when
+	go func() {
+		for i := 0; i < 100; i++ {
+			c.Check(ch.Send(ctx, i), IsNil)
+		}
+		ch.Close()
+	}()
+
+	// then
+	for i := 0; i < 100; i++ {
+		v, err := ch.Recv(ctx)
+		c.Assert(err, IsNil)
+		c.Assert(v, Equals, i)
+	}
+	_, err := ch.Recv(ctx)
+	c.Assert(err, Equals, ErrClosed)
+	c.Assert(ch.Send(ctx, 1), Equals, ErrClosed)
+}
+
+func (s *MySuite) TestChanRecvCancelled(c *C) {
+	// given
+	ch := NewChan[int](4)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := ch.Recv(ctx)
+
+	// then
+	c.Assert(err, Equals, context.DeadlineExceeded)
+}
+
+func (s *MySuite) TestChanMultiSenders(c *C) {
+	// given
+	ch := NewChan[int](2)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+
+	// when
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				ch.Send(ctx, p*50+i)
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		ch.Close()
+	}()
+
+	// then
+	seen := make(map[int]bool)
+	for {
+		v, err := ch.Recv(ctx)
+		if err != nil {
+			break
+		}
+		seen[v] = true
+	}
+	c.Assert(len(seen), Equals, 200)
+	c.Assert(ch.Cap(), Equals, uint64(2))
+}
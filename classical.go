@@ -2,30 +2,46 @@ package lfring
 
 import (
 	"sync/atomic"
+	"unsafe"
 )
 
+// classical defines a multi-producer multi-consumer ring buffer that checks full / empty by
+// head and tail, and publishes by the nil-ness of slots.
+//
+// Producers load head and consumers load tail on every call, which is a cache miss whenever
+// the other side moved. Hence each side keeps the last observed opponent index in its own
+// cache line: cachedHead can only be behind the real head, so if it already proves there's
+// space, the real head must prove it too, and so does cachedTail for values. The real index
+// is loaded (and cached) only when the cached one shows full / empty.
 type classical[T any] struct {
-	head     uint64
-	tail     uint64
-	capacity uint64
-	mask     uint64
-	element  []*T
+	head       uint64
+	cachedTail uint64
+	_padding0  [48]byte
+	tail       uint64
+	cachedHead uint64
+	_padding1  [48]byte
+	capacity   uint64
+	mask       uint64
+	element    []*T
 }
 
-func newClassical[T any](capacity uint64) RingBuffer[T] {
+func newClassical[T any](capacity uint64, c *config) RingBuffer[T] {
+	element := make([]*T, capacity)
+	if c.hugePages {
+		adviseHugePages(unsafe.Pointer(unsafe.SliceData(element)), uintptr(capacity)*unsafe.Sizeof(element[0]))
+	}
 	return &classical[T]{
 		head:     uint64(0),
 		tail:     uint64(0),
 		capacity: capacity,
 		mask:     capacity - 1,
-		element:  make([]*T, capacity),
+		element:  element,
 	}
 }
 
 func (r *classical[T]) Offer(value T) (success bool) {
 	oldTail := atomic.LoadUint64(&r.tail)
-	oldHead := atomic.LoadUint64(&r.head)
-	if r.isFull(oldTail, oldHead) {
+	if r.isFull(oldTail, r.headFor(oldTail)) {
 		return false
 	}
 
@@ -43,9 +59,71 @@ func (r *classical[T]) Offer(value T) (success bool) {
 	return true
 }
 
+// OfferIf offers value only if cond holds for the occupancy at the claim, see
+// ConditionalProducer.
+func (r *classical[T]) OfferIf(value T, cond func(len, cap uint64) bool) (success bool) {
+	oldTail := atomic.LoadUint64(&r.tail)
+	oldHead := atomic.LoadUint64(&r.head)
+	if r.isFull(oldTail, oldHead) || !cond(occupancy(r.capacity, oldHead, oldTail), r.capacity) {
+		return false
+	}
+
+	newTail := oldTail + 1
+	// not published yet
+	if r.element[newTail&r.mask] != nil {
+		return false
+	}
+	if !atomic.CompareAndSwapUint64(&r.tail, oldTail, newTail) {
+		return false
+	}
+
+	r.element[newTail&r.mask] = &value
+	return true
+}
+
+// OfferAllOrNothing offers all values or none of them, see BatchProducer.
+func (r *classical[T]) OfferAllOrNothing(values []T) (success bool) {
+	n := uint64(len(values))
+	if n == 0 {
+		return true
+	}
+
+	oldTail := atomic.LoadUint64(&r.tail)
+	// isFull for n values
+	if oldTail+n-atomic.LoadUint64(&r.head) > r.capacity-1 {
+		return false
+	}
+	for i := uint64(1); i <= n; i++ {
+		// not published yet
+		if r.element[(oldTail+i)&r.mask] != nil {
+			return false
+		}
+	}
+	if !atomic.CompareAndSwapUint64(&r.tail, oldTail, oldTail+n) {
+		return false
+	}
+
+	for i, value := range values {
+		r.element[(oldTail+uint64(i)+1)&r.mask] = &value
+	}
+	return true
+}
+
+// OfferReject offers value, see OverwritingProducer.
+func (r *classical[T]) OfferReject(value T) (success bool) {
+	return r.Offer(value)
+}
+
+// OfferOverwrite offers value, drops the oldest if full, see OverwritingProducer.
+func (r *classical[T]) OfferOverwrite(value T) (dropped T, overwritten bool) {
+	return offerOverwrite[T](r, value, func() bool {
+		return r.isFull(atomic.LoadUint64(&r.tail), atomic.LoadUint64(&r.head))
+	})
+}
+
 func (r *classical[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
 	oldTail := r.tail
-	oldHead := atomic.LoadUint64(&r.head)
+	oldHead := r.headFor(oldTail)
 	if r.isFull(oldTail, oldHead) {
 		return
 	}
@@ -68,9 +146,8 @@ func (r *classical[T]) SingleProducerOffer(valueSupplier func() (v T, finish boo
 }
 
 func (r *classical[T]) Poll() (value T, success bool) {
-	oldTail := atomic.LoadUint64(&r.tail)
 	oldHead := atomic.LoadUint64(&r.head)
-	if r.isEmpty(oldTail, oldHead) {
+	if r.isEmpty(r.tailFor(oldHead), oldHead) {
 		return
 	}
 
@@ -89,8 +166,8 @@ func (r *classical[T]) Poll() (value T, success bool) {
 }
 
 func (r *classical[T]) SingleConsumerPoll(valueConsumer func(T)) {
-	oldTail := atomic.LoadUint64(&r.tail)
 	oldHead := r.head
+	oldTail := r.tailFor(oldHead)
 	if r.isEmpty(oldTail, oldHead) {
 		return
 	}
@@ -110,14 +187,14 @@ func (r *classical[T]) SingleConsumerPoll(valueConsumer func(T)) {
 }
 
 func (r *classical[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
-	oldTail := atomic.LoadUint64(&r.tail)
 	oldHead := r.head
+	oldTail := r.tailFor(oldHead)
 	if r.isEmpty(oldTail, oldHead) {
 		return
 	}
 
 	currHead := oldHead + 1
-	for ; currHead <= oldTail; currHead++ {
+	for ; currHead <= oldTail && currHead-oldHead <= uint64(len(ret)); currHead++ {
 		currNode := r.element[currHead&r.mask]
 		// not published yet
 		if currNode == nil {
@@ -132,6 +209,78 @@ func (r *classical[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
 	return currHead - oldHead - 1
 }
 
+// headFor returns cachedHead if it proves there's space after tail, otherwise loads head and
+// caches it.
+func (r *classical[T]) headFor(tail uint64) uint64 {
+	if head := atomic.LoadUint64(&r.cachedHead); !r.isFull(tail, head) {
+		return head
+	}
+	head := atomic.LoadUint64(&r.head)
+	atomic.StoreUint64(&r.cachedHead, head)
+	return head
+}
+
+// tailFor returns cachedTail if it proves there're values after head, otherwise loads tail
+// and caches it.
+func (r *classical[T]) tailFor(head uint64) uint64 {
+	if tail := atomic.LoadUint64(&r.cachedTail); !r.isEmpty(tail, head) {
+		return tail
+	}
+	tail := atomic.LoadUint64(&r.tail)
+	atomic.StoreUint64(&r.cachedTail, tail)
+	return tail
+}
+
+func (r *classical[T]) Len() uint64 {
+	return r.State().Occupancy
+}
+
+func (r *classical[T]) SizeConsistent() (size uint64, ok bool) {
+	return consistentSize(r.capacity, &r.head, &r.tail)
+}
+
+func (r *classical[T]) Layout() Layout {
+	return Layout{
+		CacheLine: cacheLineSize,
+		Head:      unsafe.Offsetof(r.head),
+		Tail:      unsafe.Offsetof(r.tail),
+		Mask:      unsafe.Offsetof(r.mask),
+		Element:   unsafe.Offsetof(r.element),
+		SlotSize:  unsafe.Sizeof(r.element[0]),
+	}
+}
+
+func (r *classical[T]) prefault() {
+	for i := range r.element {
+		r.element[i] = nil
+	}
+}
+
+// MemoryUsage counts the values in buffer as Payload, as every offer boxes a copy of value.
+func (r *classical[T]) MemoryUsage() MemoryUsage {
+	var zero T
+	return MemoryUsage{
+		Header:   uint64(unsafe.Sizeof(*r)),
+		Slots:    r.capacity * uint64(unsafe.Sizeof(r.element[0])),
+		Payload:  r.Len() * uint64(unsafe.Sizeof(zero)),
+		Segments: 1,
+	}
+}
+
+func (r *classical[T]) Cap() uint64 {
+	return r.capacity
+}
+
+func (r *classical[T]) State() State {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	return newState(Classical, r.capacity, head, tail)
+}
+
+func (r *classical[T]) String() string {
+	return r.State().String()
+}
+
 // isFull check whether buffer is full by compare (tail - head).
 // Because of none-sync read of tail and head, the tail maybe smaller than head(which is
 // never happened in the view of buffer):
@@ -146,6 +295,11 @@ func (r *classical[T]) isFull(tail uint64, head uint64) bool {
 	return tail-head >= r.capacity-1
 }
 
+// full check whether buffer is full by the current tail and head, see fuller.
+func (r *classical[T]) full() bool {
+	return r.isFull(atomic.LoadUint64(&r.tail), atomic.LoadUint64(&r.head))
+}
+
 // isEmpty check whether buffer is empty by compare (tail - head).
 // Same as isFull, the tail also may be smaller than head at thread view, which can be lead
 // to wrong result:
@@ -161,9 +315,52 @@ func (r *classical[T]) isEmpty(tail uint64, head uint64) bool {
 	return (tail < head) || (tail-head == 0)
 }
 
-// YET TO IMPLEMENT
-// Alternative optimized version that tries to batch claim multiple positions
-// This is more complex but could be even faster under high contention
+// PollNBatched polls at most n values, see PollBatchInto.
 func (r *classical[T]) PollNBatched(n uint64) (values []T, count uint64) {
-	return nil, 0
-}
\ No newline at end of file
+	if n == 0 {
+		return nil, 0
+	}
+
+	values = make([]T, n)
+	count = r.PollBatchInto(values)
+	return values[:count], count
+}
+
+// PollBatchInto fills dst with the head values, returns how many values are filled.
+// It claims all the consecutive published values (at most len(dst)) by one CAS of head.
+func (r *classical[T]) PollBatchInto(dst []T) (count uint64) {
+	n := uint64(len(dst))
+	for count < n {
+		oldTail := atomic.LoadUint64(&r.tail)
+		oldHead := atomic.LoadUint64(&r.head)
+		if r.isEmpty(oldTail, oldHead) {
+			break
+		}
+
+		available := uint64(0)
+		for available < n-count && oldHead+available < oldTail {
+			// not published yet
+			if r.element[(oldHead+available+1)&r.mask] == nil {
+				break
+			}
+			available++
+		}
+
+		if available == 0 {
+			break
+		}
+
+		if !atomic.CompareAndSwapUint64(&r.head, oldHead, oldHead+available) {
+			continue
+		}
+
+		for i := uint64(1); i <= available; i++ {
+			idx := (oldHead + i) & r.mask
+			dst[count+i-1] = *r.element[idx]
+			r.element[idx] = nil
+		}
+		count += available
+	}
+
+	return count
+}
@@ -0,0 +1,106 @@
+package lfring
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrSlotTooSmall is returned by Codec.Marshal when the value doesn't fit the slot.
+var ErrSlotTooSmall = errors.New("lfring: value doesn't fit the slot")
+
+// Codec moves values of T in and out of the byte regions of slots, for the rings whose
+// storage is not on the Go heap (e.g. the shared-memory ring of the shm subpackage), where a
+// pointer means nothing to the other process. Marshal must not retain slot, Unmarshal must
+// copy out what it keeps, the region is reused once the call returns.
+type Codec[T any] interface {
+	// Marshal writes v into slot, returns the number of bytes written, or ErrSlotTooSmall if v
+	// doesn't fit.
+	Marshal(slot []byte, v T) (n int, err error)
+	// Unmarshal reads a value from the bytes written by Marshal.
+	Unmarshal(data []byte) (v T, err error)
+}
+
+// BinaryCodec returns a Codec of the fixed-size T (numbers, and arrays and structs of them)
+// by encoding/binary in little-endian, the byte layout is the same as encoding/binary, so
+// peers in other languages can read it as a packed struct.
+func BinaryCodec[T any]() Codec[T] {
+	return binaryCodec[T]{}
+}
+
+type binaryCodec[T any] struct{}
+
+func (binaryCodec[T]) Marshal(slot []byte, v T) (n int, err error) {
+	if size := binary.Size(v); size > len(slot) {
+		return 0, ErrSlotTooSmall
+	}
+	return binary.Encode(slot, binary.LittleEndian, v)
+}
+
+func (binaryCodec[T]) Unmarshal(data []byte) (v T, err error) {
+	_, err = binary.Decode(data, binary.LittleEndian, &v)
+	return v, err
+}
+
+// BytesCodec returns a Codec of []byte, Unmarshal returns a copy.
+func BytesCodec() Codec[[]byte] {
+	return bytesCodec{}
+}
+
+type bytesCodec struct{}
+
+func (bytesCodec) Marshal(slot []byte, v []byte) (n int, err error) {
+	if len(v) > len(slot) {
+		return 0, ErrSlotTooSmall
+	}
+	return copy(slot, v), nil
+}
+
+func (bytesCodec) Unmarshal(data []byte) (v []byte, err error) {
+	return append([]byte(nil), data...), nil
+}
+
+// StringCodec returns a Codec of string.
+func StringCodec() Codec[string] {
+	return stringCodec{}
+}
+
+type stringCodec struct{}
+
+func (stringCodec) Marshal(slot []byte, v string) (n int, err error) {
+	if len(v) > len(slot) {
+		return 0, ErrSlotTooSmall
+	}
+	return copy(slot, v), nil
+}
+
+func (stringCodec) Unmarshal(data []byte) (v string, err error) {
+	return string(data), nil
+}
+
+// FuncCodec returns a Codec of the marshal and unmarshal functions, e.g. of a protobuf or a
+// hand-written format. marshal appends the encoding of v to the slot (of zero length), it
+// fails with ErrSlotTooSmall if it grows beyond the slot capacity.
+func FuncCodec[T any](marshal func(dst []byte, v T) ([]byte, error), unmarshal func(data []byte) (T, error)) Codec[T] {
+	return funcCodec[T]{marshal: marshal, unmarshal: unmarshal}
+}
+
+type funcCodec[T any] struct {
+	marshal   func(dst []byte, v T) ([]byte, error)
+	unmarshal func(data []byte) (T, error)
+}
+
+func (c funcCodec[T]) Marshal(slot []byte, v T) (n int, err error) {
+	b, err := c.marshal(slot[:0], v)
+	if err != nil {
+		return 0, err
+	}
+	// reallocated, the slot is too small
+	if len(b) > 0 && (len(b) > len(slot) || &b[0] != &slot[0]) {
+		return 0, ErrSlotTooSmall
+	}
+	return len(b), nil
+}
+
+func (c funcCodec[T]) Unmarshal(data []byte) (v T, err error) {
+	return c.unmarshal(data)
+}
@@ -0,0 +1,71 @@
+package lfring
+
+import (
+	"encoding/binary"
+	. "gopkg.in/check.v1"
+	"strconv"
+)
+
+type codecSample struct {
+	ID    uint32
+	Score float64
+	Tags  [2]int16
+}
+
+func (s *MySuite) TestBinaryCodec(c *C) {
+	// given
+	codec := BinaryCodec[codecSample]()
+	slot := make([]byte, 32)
+	sample := codecSample{ID: 7, Score: 1.5, Tags: [2]int16{-1, 2}}
+
+	// when
+	n, err := codec.Marshal(slot, sample)
+	decoded, decodeErr := codec.Unmarshal(slot[:n])
+	_, tooSmall := codec.Marshal(slot[:8], sample)
+
+	// then little-endian packed
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 16)
+	c.Assert(binary.LittleEndian.Uint32(slot), Equals, uint32(7))
+	c.Assert(decodeErr, IsNil)
+	c.Assert(decoded, Equals, sample)
+	c.Assert(tooSmall, Equals, ErrSlotTooSmall)
+}
+
+func (s *MySuite) TestBytesAndStringCodec(c *C) {
+	// given
+	slot := make([]byte, 4)
+
+	// when
+	n, _ := BytesCodec().Marshal(slot, []byte("ab"))
+	b, _ := BytesCodec().Unmarshal(slot[:n])
+	slot[0] = 'x'
+	m, _ := StringCodec().Marshal(slot, "cd")
+	str, _ := StringCodec().Unmarshal(slot[:m])
+	_, tooSmall := StringCodec().Marshal(slot, "abcde")
+
+	// then copied out
+	c.Assert(b, DeepEquals, []byte("ab"))
+	c.Assert(str, Equals, "cd")
+	c.Assert(tooSmall, Equals, ErrSlotTooSmall)
+}
+
+func (s *MySuite) TestFuncCodec(c *C) {
+	// given
+	codec := FuncCodec(func(dst []byte, v int) ([]byte, error) {
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	}, func(data []byte) (int, error) {
+		return strconv.Atoi(string(data))
+	})
+	slot := make([]byte, 3)
+
+	// when
+	n, err := codec.Marshal(slot, 123)
+	v, _ := codec.Unmarshal(slot[:n])
+	_, tooSmall := codec.Marshal(slot, 1234)
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, 123)
+	c.Assert(tooSmall, Equals, ErrSlotTooSmall)
+}
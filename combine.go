@@ -0,0 +1,68 @@
+package lfring
+
+import (
+	"context"
+)
+
+// combineBatches bounds how many full batches a delivery of Combine folds, so a saturated
+// input still gets delivered regularly.
+const combineBatches = 8
+
+// Combine is a consuming stage that coalesces the pending values into one before delivering
+// downstream, e.g. invalidation events of the same cache: every pass drains whatever pending
+// and folds it by merge, then deliver gets the single result. So the downstream load is
+// bounded by the passes rather than the values, the more bursty the input, the more values
+// a delivery represents.
+//
+// It drains by batches of WithBatchSize, and keeps folding while batches come back full
+// (more values are likely pending), at most combineBatches of them. Like SingleConsumerStream
+// it waits by WithWaitStrategy across empty periods, and returns when ctx done (ctx.Err()),
+// or consumer closed and drained (nil). The caller must be the only consumer.
+func Combine[T any](ctx context.Context, consumer Consumer[T], merge func(acc T, next T) T, deliver func(T), opts ...Option) error {
+	c := newConfig(opts)
+	batch := make([]T, c.batchSize)
+	done := ctx.Done()
+	closer, _ := consumer.(closable)
+	for attempt := 0; ; {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+
+		if acc, ok := combineOnce(consumer, batch, merge); ok {
+			attempt = 0
+			deliver(acc)
+			continue
+		}
+
+		if closer != nil && closer.Closed() {
+			// values may be published right before closed
+			if acc, ok := combineOnce(consumer, batch, merge); ok {
+				deliver(acc)
+				continue
+			}
+			return nil
+		}
+		attempt++
+		c.wait.Wait(attempt)
+	}
+}
+
+// combineOnce folds the pending values until a batch comes back short, return false if
+// there's no value.
+func combineOnce[T any](consumer Consumer[T], batch []T, merge func(T, T) T) (acc T, ok bool) {
+	for i := 0; ; i++ {
+		validCnt := consumer.SingleConsumerPollVec(batch)
+		for _, v := range batch[:validCnt] {
+			if !ok {
+				acc, ok = v, true
+				continue
+			}
+			acc = merge(acc, v)
+		}
+		if validCnt < uint64(len(batch)) || i == combineBatches-1 {
+			return acc, ok
+		}
+	}
+}
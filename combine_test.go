@@ -0,0 +1,66 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestCombineCoalescesBurst(c *C) {
+	// given a burst larger than a batch
+	buffer := NewBlocking(New[int](NodeBased, 16))
+	for i := 1; i <= 10; i++ {
+		buffer.Put(i)
+	}
+	buffer.Close()
+	var delivered []int
+
+	// when
+	err := Combine(context.Background(), buffer, func(acc, next int) int { return acc + next }, func(v int) {
+		delivered = append(delivered, v)
+	}, WithBatchSize(4))
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(delivered, DeepEquals, []int{55})
+}
+
+func (s *MySuite) TestCombineDeliversPerPass(c *C) {
+	// given
+	buffer := NewBlocking(New[int](NodeBased, 16))
+	buffer.Put(1)
+	var delivered []int
+	deliver := func(v int) {
+		delivered = append(delivered, v)
+		if v == 1 {
+			buffer.Put(2)
+			buffer.Put(3)
+			return
+		}
+		buffer.Close()
+	}
+
+	// when
+	err := Combine(context.Background(), buffer, func(acc, next int) int { return acc*10 + next }, deliver)
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(delivered, DeepEquals, []int{1, 23})
+}
+
+func (s *MySuite) TestCombineBoundedFold(c *C) {
+	// given
+	buffer := NewBlocking(New[int](NodeBased, 64))
+	for i := 0; i < 2*combineBatches+1; i++ {
+		buffer.Put(1)
+	}
+	buffer.Close()
+	var delivered []int
+
+	// when
+	Combine(context.Background(), buffer, func(acc, next int) int { return acc + next }, func(v int) {
+		delivered = append(delivered, v)
+	}, WithBatchSize(1))
+
+	// then
+	c.Assert(delivered, DeepEquals, []int{combineBatches, combineBatches, 1})
+}
@@ -20,6 +20,18 @@ func (s *MySuite) TestHybridMpmcConcurrencyRW(c *C) {
 	})
 }
 
+func (s *MySuite) TestFetchAddMpmcConcurrencyRW(c *C) {
+	MPMCConcurrencyRW(c, FetchAdd, func(buffer RingBuffer[*string]) uint64 {
+		return atomic.LoadUint64(&buffer.(*fetchAdd[*string]).head)
+	})
+}
+
+func (s *MySuite) TestFetchAddMpscConcurrencyRW(c *C) {
+	MPSCConcurrencyRW(c, FetchAdd, func(buffer RingBuffer[*string]) uint64 {
+		return atomic.LoadUint64(&buffer.(*fetchAdd[*string]).head)
+	})
+}
+
 func (s *MySuite) TestNodeMpscConcurrencyRW(c *C) {
 	MPSCConcurrencyRW(c, NodeBased, func(buffer RingBuffer[*string]) uint64 {
 		return atomic.LoadUint64(&buffer.(*nodeBased[*string]).head)
@@ -415,3 +427,273 @@ func initDataSource() []string {
 	}
 	return sourceArray
 }
+
+func (s *MySuite) TestRequeueConcurrency(c *C) {
+	// given
+	const total = 2000
+	buffer := New[int](NodeBased, 16)
+	var wg sync.WaitGroup
+	var consumed int64
+
+	// when a producer offers distinct values, a consumer polls and puts back every other value
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; {
+			if buffer.Offer(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	seen := make([]int32, total)
+	go func() {
+		defer wg.Done()
+		requeuer := buffer.(Requeuer[int])
+		for atomic.LoadInt64(&consumed) < total {
+			v, ok := buffer.Poll()
+			if !ok {
+				runtime.Gosched()
+				continue
+			}
+			if v%2 == 0 && atomic.LoadInt32(&seen[v]) == 0 {
+				atomic.StoreInt32(&seen[v], -1)
+				if requeuer.Requeue(v) {
+					continue
+				}
+			}
+			atomic.AddInt32(&seen[v], 2)
+			atomic.AddInt64(&consumed, 1)
+		}
+	}()
+	wg.Wait()
+
+	// then every value is consumed once
+	for i := range seen {
+		c.Assert(seen[i] == 1 || seen[i] == 2, Equals, true, Commentf("%d: %d", i, seen[i]))
+	}
+}
+
+func (s *MySuite) TestOfferAllOrNothingConcurrency(c *C) {
+	for _, t := range []BufferType{NodeBased, FetchAdd} {
+		// given
+		const producers, groups, size = 4, 200, 3
+		buffer := New[int](t, 16)
+		var wg sync.WaitGroup
+
+		// when producers offer groups of consecutive values
+		wg.Add(producers)
+		for p := 0; p < producers; p++ {
+			go func(p int) {
+				defer wg.Done()
+				producer := buffer.(BatchProducer[int])
+				group := make([]int, size)
+				for g := 0; g < groups; g++ {
+					for i := range group {
+						group[i] = ((p*groups)+g)*size + i
+					}
+					for !producer.OfferAllOrNothing(group) {
+						runtime.Gosched()
+					}
+				}
+			}(p)
+		}
+
+		// then every group is polled contiguously
+		prev := -1
+		for polled := 0; polled < producers*groups*size; {
+			v, ok := buffer.Poll()
+			if !ok {
+				runtime.Gosched()
+				continue
+			}
+			if v%size != 0 {
+				c.Assert(v, Equals, prev+1, Commentf("%s", t))
+			}
+			prev = v
+			polled++
+		}
+		wg.Wait()
+	}
+}
+
+func (s *MySuite) TestFetchAddOffersNeverFailForContention(c *C) {
+	// given a buffer with room for all the values
+	const producers, rounds = 4, 100
+	buffer := New[int](FetchAdd, 4096)
+	var wg sync.WaitGroup
+	var failed int64
+
+	// when producers offer by every kind of offer concurrently
+	wg.Add(producers)
+	for p := 0; p < producers; p++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				_, overwritten := buffer.(OverwritingProducer[int]).OfferOverwrite(i)
+				ok := buffer.(ConditionalProducer[int]).OfferIf(i, func(len, cap uint64) bool { return true }) &&
+					buffer.(BatchProducer[int]).OfferAllOrNothing([]int{i, i}) &&
+					buffer.(OverwritingProducer[int]).OfferReject(i) &&
+					buffer.(Requeuer[int]).Requeue(i) && !overwritten
+				if !ok {
+					atomic.AddInt64(&failed, 1)
+				}
+				runtime.Gosched()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// then
+	c.Assert(atomic.LoadInt64(&failed), Equals, int64(0))
+	c.Assert(buffer.Len(), Equals, uint64(producers*rounds*6))
+}
+
+func (s *MySuite) TestMulticastReadWhileOffered(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	// given a small ring lapped many times, with values of several words
+	const producers, rounds = 2, 20000
+	m := NewMulticast[[4]uint64](8)
+	var wg sync.WaitGroup
+	var torn int64
+
+	// when cursors and consumers read while producers overwrite
+	wg.Add(producers)
+	for p := 0; p < producers; p++ {
+		go func() {
+			defer wg.Done()
+			for i := uint64(0); i < rounds; i++ {
+				m.Offer([4]uint64{i, i, i, i})
+			}
+		}()
+	}
+	done := make(chan struct{})
+	check := func(v [4]uint64) {
+		if v != [4]uint64{v[0], v[0], v[0], v[0]} {
+			atomic.AddInt64(&torn, 1)
+		}
+	}
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		cursor := m.CursorFrom(0)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if v, ok := cursor.Next(); ok {
+				check(v)
+			}
+		}
+	}()
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if v, ok := m.Poll(); ok {
+				check(v)
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	// then
+	c.Assert(atomic.LoadInt64(&torn), Equals, int64(0))
+	c.Assert(m.Tail(), Equals, uint64(producers*rounds))
+}
+
+func (s *MySuite) TestLatestStoreLoadConcurrency(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	// given
+	const writers, rounds = 2, 2000
+	var latest Latest[[4]uint64]
+	var wg sync.WaitGroup
+	var torn, backwards int64
+
+	// when a reader loads while writers store
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := uint64(1); i <= rounds; i++ {
+				latest.Store([4]uint64{i, i, i, i})
+			}
+		}()
+	}
+	done := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		var last uint64
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			v, version := latest.Load()
+			if v != [4]uint64{v[0], v[0], v[0], v[0]} {
+				atomic.AddInt64(&torn, 1)
+			}
+			if version < last {
+				atomic.AddInt64(&backwards, 1)
+			}
+			last = version
+		}
+	}()
+	wg.Wait()
+	close(done)
+	<-read
+
+	// then
+	_, version := latest.Load()
+	c.Assert(atomic.LoadInt64(&torn), Equals, int64(0))
+	c.Assert(atomic.LoadInt64(&backwards), Equals, int64(0))
+	c.Assert(version, Equals, uint64(writers*rounds))
+}
+
+func (s *MySuite) TestFrameRingPullWhilePushed(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	for _, policy := range []FramePolicy{FrameDropNewest, FrameDropOldest} {
+		// given a producer laps a small ring
+		const frames = 20000
+		ring := NewFrameRing[uint64](2, 4, policy)
+		done := make(chan struct{})
+		var torn int64
+
+		// when the consumer pulls meanwhile
+		go func() {
+			defer close(done)
+			for i := uint64(1); i <= frames; i++ {
+				ring.Push([]uint64{i, i, i, i})
+				if i%8 == 0 {
+					runtime.Gosched()
+				}
+			}
+		}()
+		dst := make([]uint64, 4)
+		for pulling := true; pulling; {
+			select {
+			case <-done:
+				pulling = false
+			default:
+			}
+			if ring.Pull(dst) && dst[3] != dst[0] {
+				atomic.AddInt64(&torn, 1)
+			}
+		}
+
+		// then
+		c.Assert(atomic.LoadInt64(&torn), Equals, int64(0), Commentf("policy: %d", policy))
+	}
+}
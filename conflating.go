@@ -0,0 +1,110 @@
+package lfring
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Conflating is a queue of keys whose pending value is replaced rather than queued again,
+// e.g. the market data feeds where only the freshest quote of a symbol matters: Offer of a
+// key that is pending replaces its value in place, so consumers always get the newest value
+// per key, and memory is bounded by the number of distinct keys rather than the rate.
+//
+// Keys are queued in a NodeBased ring by the order they became pending, values are kept in a
+// sync.Map of key to the pending value. The number of pending keys is reserved up to the
+// capacity ahead of queueing, so the ring never overflows.
+type Conflating[K comparable, V any] struct {
+	keys      RingBuffer[K]
+	pending   sync.Map
+	reserved  atomic.Uint64
+	conflated atomic.Uint64
+}
+
+type conflatingEntry[V any] struct {
+	// value is nil once taken by consumer
+	value atomic.Pointer[V]
+}
+
+// NewConflating build a Conflating holds at most capacity (expands to power-of-two) pending
+// keys, in a NodeBased ring built with opts.
+func NewConflating[K comparable, V any](capacity uint64, opts ...Option) *Conflating[K, V] {
+	return &Conflating[K, V]{keys: New[K](NodeBased, capacity, opts...)}
+}
+
+// Offer value of key, it replaces the pending value of key if any. It returns false if there
+// are Cap pending keys already.
+func (q *Conflating[K, V]) Offer(key K, value V) (success bool) {
+	v := &value
+	for {
+		if e, ok := q.pending.Load(key); ok {
+			entry := e.(*conflatingEntry[V])
+			if old := entry.value.Load(); old != nil && entry.value.CompareAndSwap(old, v) {
+				q.conflated.Add(1)
+				return true
+			}
+			// taken by consumer, help to remove it
+			q.pending.CompareAndDelete(key, e)
+			continue
+		}
+
+		if !q.reserve() {
+			return false
+		}
+		entry := &conflatingEntry[V]{}
+		entry.value.Store(v)
+		if _, loaded := q.pending.LoadOrStore(key, entry); loaded {
+			q.reserved.Add(^uint64(0))
+			continue
+		}
+		// the ring has room as reserved, it only fails by contention
+		for !q.keys.Offer(key) {
+			cpuRelax()
+			runtime.Gosched()
+		}
+		return true
+	}
+}
+
+func (q *Conflating[K, V]) reserve() bool {
+	for {
+		reserved := q.reserved.Load()
+		if reserved >= q.keys.Cap() {
+			return false
+		}
+		if q.reserved.CompareAndSwap(reserved, reserved+1) {
+			return true
+		}
+	}
+}
+
+// Poll the key pending for the longest, with its newest value, return false if there's no
+// pending key or the claim lost in contention.
+func (q *Conflating[K, V]) Poll() (key K, value V, success bool) {
+	key, success = q.keys.Poll()
+	if !success {
+		return
+	}
+
+	e, _ := q.pending.Load(key)
+	entry := e.(*conflatingEntry[V])
+	value = *entry.value.Swap(nil)
+	q.pending.CompareAndDelete(key, e)
+	q.reserved.Add(^uint64(0))
+	return key, value, true
+}
+
+// Len returns the approximate number of pending keys.
+func (q *Conflating[K, V]) Len() uint64 {
+	return q.keys.Len()
+}
+
+// Cap returns how many keys can be pending.
+func (q *Conflating[K, V]) Cap() uint64 {
+	return q.keys.Cap()
+}
+
+// Conflated returns how many values replaced a pending one.
+func (q *Conflating[K, V]) Conflated() uint64 {
+	return q.conflated.Load()
+}
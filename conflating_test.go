@@ -0,0 +1,77 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+func (s *MySuite) TestConflatingReplacesPending(c *C) {
+	// given
+	q := NewConflating[string, float64](2)
+
+	// when
+	q.Offer("AAPL", 1)
+	q.Offer("MSFT", 2)
+	q.Offer("AAPL", 3)
+	full := q.Offer("GOOG", 4)
+	k1, v1, _ := q.Poll()
+	q.Offer("AAPL", 5)
+	k2, v2, _ := q.Poll()
+	k3, v3, _ := q.Poll()
+	_, _, empty := q.Poll()
+
+	// then
+	c.Assert(full, Equals, false)
+	c.Assert([]string{k1, k2, k3}, DeepEquals, []string{"AAPL", "MSFT", "AAPL"})
+	c.Assert([]float64{v1, v2, v3}, DeepEquals, []float64{3, 2, 5})
+	c.Assert(empty, Equals, false)
+	c.Assert(q.Conflated(), Equals, uint64(1))
+	c.Assert(q.Len(), Equals, uint64(0))
+}
+
+func (s *MySuite) TestConflatingConcurrency(c *C) {
+	// given
+	const producers, perProducer, keys = 4, 1000, 8
+	q := NewConflating[string, int](keys)
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	// when every producer offers increasing values of the keys
+	wg.Add(producers)
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.Offer(strconv.Itoa(p*keys/producers+i%(keys/producers)), i)
+			}
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// then the values of a key never go back
+	last := make(map[string]int)
+	for {
+		key, value, ok := q.Poll()
+		if !ok {
+			select {
+			case <-done:
+				if q.Len() == 0 {
+					c.Assert(len(last), Equals, keys)
+					return
+				}
+			default:
+			}
+			runtime.Gosched()
+			continue
+		}
+		if prev, seen := last[key]; seen {
+			c.Assert(value > prev, Equals, true)
+		}
+		last[key] = value
+	}
+}
@@ -0,0 +1,293 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"log/slog"
+	"strconv"
+	"sync"
+)
+
+// closable is implemented by the consumers can be closed, e.g. Blocking.
+type closable interface {
+	Closed() bool
+}
+
+// SingleConsumerPollContext passes values in consumer to valueConsumer until consumer is
+// empty (returns nil) or ctx done (returns ctx.Err()). Unlike SingleConsumerPoll, it drains
+// by batches of WithBatchSize and checks ctx between batches, so it stops promptly even if
+// producers keep the buffer busy. The caller must be the only consumer.
+func SingleConsumerPollContext[T any](ctx context.Context, consumer Consumer[T], valueConsumer func(T), opts ...Option) error {
+	batch := make([]T, newConfig(opts).batchSize)
+	done := ctx.Done()
+	for {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+
+		validCnt := consumer.SingleConsumerPollVec(batch)
+		if validCnt == 0 {
+			return nil
+		}
+		for i := uint64(0); i < validCnt; i++ {
+			valueConsumer(batch[i])
+		}
+	}
+}
+
+// PollSeq returns a sequence that lazily polls up to max values currently available from
+// consumer, it ends at the first failed Poll, so a drain pass can be ranged over without
+// materializing a slice:
+//
+//	for v := range lfring.PollSeq(buffer, 64) {
+//		handle(v)
+//	}
+//
+// Every value is polled right before yielded, breaking the loop leaves the rest in consumer.
+// The sequence can be ranged again for another pass.
+func PollSeq[T any](consumer Consumer[T], max int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < max; i++ {
+			v, success := consumer.Poll()
+			if !success || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// PollWhere polls consumer for the first value matching pred, the values that don't match
+// are routed to secondary rather than lost, e.g. a consumer takes its own kind and passes the
+// others on to the ring of another consumer. It scans at most WithBatchSize values per call,
+// return false if none of them matches or consumer is empty.
+//
+// A value that doesn't match is offered to secondary until accepted, waiting by
+// WithWaitStrategy, so a full secondary backpressures rather than drops.
+func PollWhere[T any](consumer Consumer[T], pred func(T) bool, secondary Producer[T], opts ...Option) (value T, success bool) {
+	c := newConfig(opts)
+	for i := uint64(0); i < c.batchSize; i++ {
+		v, ok := consumer.Poll()
+		if !ok {
+			return
+		}
+		if pred(v) {
+			return v, true
+		}
+
+		for attempt := 1; !secondary.Offer(v); attempt++ {
+			c.wait.Wait(attempt)
+		}
+	}
+	return
+}
+
+// SingleConsumerStream is the long-lived version of SingleConsumerPollContext: it keeps
+// passing values to valueConsumer across empty periods, waiting by WithWaitStrategy, and only
+// returns when ctx done (returns ctx.Err()), or consumer is closed (e.g. Blocking.Close) and
+// drained (returns nil). The caller must be the only consumer.
+func SingleConsumerStream[T any](ctx context.Context, consumer Consumer[T], valueConsumer func(T), opts ...Option) error {
+	return ConsumeBatches(ctx, consumer, func(batch []T) {
+		for _, v := range batch {
+			valueConsumer(v)
+		}
+	}, opts...)
+}
+
+// ConsumeBatches is SingleConsumerStream for tiny values and trivial handlers: it invokes
+// batchConsumer once per pass with whatever drained, at most WithBatchSize values, rather than
+// once per value. The batch is reused between calls, batchConsumer must not retain it. The
+// caller must be the only consumer. WithTrace emits a region per batch.
+func ConsumeBatches[T any](ctx context.Context, consumer Consumer[T], batchConsumer func(batch []T), opts ...Option) error {
+	c := newConfig(opts)
+	batch := make([]T, c.batchSize)
+	done := ctx.Done()
+	closer, _ := consumer.(closable)
+	for attempt := 0; ; {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+
+		if validCnt := consumer.SingleConsumerPollVec(batch); validCnt > 0 {
+			attempt = 0
+			region := startRegion(c.trace, ctx, RegionConsumeBatch)
+			batchConsumer(batch[:validCnt])
+			endRegion(region)
+			continue
+		}
+
+		if closer != nil && closer.Closed() {
+			// values may be published right before closed
+			return drainBatches(ctx, consumer, batch, batchConsumer)
+		}
+		attempt++
+		c.wait.Wait(attempt)
+	}
+}
+
+func drainBatches[T any](ctx context.Context, consumer Consumer[T], batch []T, batchConsumer func([]T)) error {
+	done := ctx.Done()
+	for {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+
+		validCnt := consumer.SingleConsumerPollVec(batch)
+		if validCnt == 0 {
+			return nil
+		}
+		batchConsumer(batch[:validCnt])
+	}
+}
+
+// Decision tells the consuming loop what to do after a value handled.
+type Decision int
+
+const (
+	// Continue goes on to the next value, the error returned along (if any) is ignored
+	Continue Decision = iota
+	// Stop ends the loop, the error returned along is returned by the loop
+	Stop
+	// Retry handles the value again, at most WithMaxRetries times, then dead letters it
+	Retry
+	// DeadLetter passes the value and error to WithDeadLetter, then goes on
+	DeadLetter
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Continue:
+		return "Continue"
+	case Stop:
+		return "Stop"
+	case Retry:
+		return "Retry"
+	case DeadLetter:
+		return "DeadLetter"
+	default:
+		return "Decision(" + strconv.Itoa(int(d)) + ")"
+	}
+}
+
+// DecisionHandler handles a value and decides what the consuming loop does next.
+type DecisionHandler[T any] func(value T) (Decision, error)
+
+// Consume polls values from consumer and hands them to handler, implementing the retry, dead
+// letter and termination decided by handler centrally. Like SingleConsumerStream it waits by
+// WithWaitStrategy across empty periods, and returns when ctx done (ctx.Err()), consumer
+// closed and drained (nil), or handler decides Stop (the error along).
+//
+// Values are taken by Poll one at a time, so that nothing polled is lost on Stop, and more
+// than one Consume can share a consumer.
+func Consume[T any](ctx context.Context, consumer Consumer[T], handler DecisionHandler[T], opts ...Option) error {
+	c := newConfig(opts)
+	l := newLogger(c)
+	done := ctx.Done()
+	closer, _ := consumer.(closable)
+	for attempt := 0; ; {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+
+		value, success := consumer.Poll()
+		if !success && closer != nil && closer.Closed() {
+			// values may be published right before closed
+			if value, success = consumer.Poll(); !success {
+				return nil
+			}
+		}
+		if !success {
+			attempt++
+			c.wait.Wait(attempt)
+			continue
+		}
+
+		attempt = 0
+		if stop, err := handleWithDecision(c, l, value, handler); stop {
+			return err
+		}
+	}
+}
+
+func handleWithDecision[T any](c *config, l logger, value T, handler DecisionHandler[T]) (stop bool, err error) {
+	for retries := 0; ; retries++ {
+		var decision Decision
+		decision, err = handler(value)
+		if decision == Retry && retries < c.maxRetries {
+			c.wait.Wait(retries + 1)
+			continue
+		}
+
+		switch decision {
+		case Stop:
+			return true, err
+		case Retry, DeadLetter:
+			if c.deadLetter != nil {
+				c.deadLetter(value, err)
+			} else {
+				l.log(slog.LevelWarn, "lfring: value dropped", slog.String("decision", decision.String()), slog.Any("error", err))
+			}
+		}
+		return false, nil
+	}
+}
+
+// ConsumeParallel runs n goroutines of Consume over consumer, passing values to handler
+// concurrently. The first handler error (or panic, as *PanicError) stops all the goroutines,
+// and the errors of every goroutine are returned joined by errors.Join. Without handler error
+// it returns like Consume: ctx.Err() when ctx done, nil when consumer closed and drained.
+func ConsumeParallel[T any](ctx context.Context, consumer Consumer[T], n int, handler func(T) error, opts ...Option) error {
+	if n < 1 {
+		n = 1
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	name := newConfig(opts).name
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go labeled(workerCtx, name, RoleConsumer, func(ctx context.Context) {
+			defer wg.Done()
+			if err := consumeWorker(ctx, consumer, handler, opts); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				cancel()
+			}
+		})
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func consumeWorker[T any](ctx context.Context, consumer Consumer[T], handler func(T) error, opts []Option) (err error) {
+	defer recoverAsError(&err)
+
+	err = Consume(ctx, consumer, func(v T) (Decision, error) {
+		if err := handler(v); err != nil {
+			return Stop, err
+		}
+		return Continue, nil
+	}, opts...)
+	// stopped by ctx, reported once by ConsumeParallel
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	return err
+}
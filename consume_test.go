@@ -0,0 +1,232 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+	"sync/atomic"
+	"time"
+)
+
+func (s *MySuite) TestSingleConsumerStreamAcrossEmptyPeriods(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 4))
+	var received []int
+	result := make(chan error)
+
+	// when
+	go func() {
+		result <- SingleConsumerStream[int](context.Background(), buffer, func(v int) {
+			received = append(received, v)
+		})
+	}()
+	for i := 0; i < 3; i++ {
+		buffer.Put(i)
+		// let the stream meet an empty buffer
+		time.Sleep(2 * time.Millisecond)
+	}
+	buffer.Put(3)
+	buffer.Close()
+
+	// then
+	c.Assert(<-result, IsNil)
+	c.Assert(received, DeepEquals, []int{0, 1, 2, 3})
+}
+
+func (s *MySuite) TestSingleConsumerStreamCancelled(c *C) {
+	// given
+	buffer := New[int](Classical, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	// when
+	err := SingleConsumerStream[int](ctx, buffer, func(int) {}, WithWaitStrategy(SleepingWait(1, time.Millisecond)))
+
+	// then
+	c.Assert(err, Equals, context.DeadlineExceeded)
+}
+
+func (s *MySuite) TestConsumeBatchesBoundedByBatchSize(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 16))
+	for i := 0; i < 10; i++ {
+		buffer.Put(i)
+	}
+	buffer.Close()
+	var sizes []int
+	var received []int
+
+	// when
+	err := ConsumeBatches[int](context.Background(), buffer, func(batch []int) {
+		sizes = append(sizes, len(batch))
+		received = append(received, batch...)
+	}, WithBatchSize(4))
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(sizes, DeepEquals, []int{4, 4, 2})
+	c.Assert(received, DeepEquals, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+}
+
+func (s *MySuite) TestConsumeWithDecisions(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 8))
+	for i := 0; i < 6; i++ {
+		buffer.Put(i)
+	}
+	failure := errors.New("failure")
+	tries := make(map[int]int)
+	var deadLetters []any
+	var handled []int
+
+	// when
+	err := Consume[int](context.Background(), buffer, func(v int) (Decision, error) {
+		tries[v]++
+		switch v {
+		case 1:
+			return Retry, failure
+		case 2:
+			if tries[v] < 2 {
+				return Retry, failure
+			}
+		case 3:
+			return DeadLetter, failure
+		case 4:
+			return Stop, failure
+		}
+		handled = append(handled, v)
+		return Continue, nil
+	}, WithMaxRetries(2), WithDeadLetter(func(v any, err error) {
+		c.Assert(err, Equals, failure)
+		deadLetters = append(deadLetters, v)
+	}))
+
+	// then
+	c.Assert(err, Equals, failure)
+	c.Assert(tries[1], Equals, 3)
+	c.Assert(tries[2], Equals, 2)
+	c.Assert(handled, DeepEquals, []int{0, 2})
+	c.Assert(deadLetters, DeepEquals, []any{1, 3})
+	c.Assert(MustPoll[int](buffer), Equals, 5)
+}
+
+func (s *MySuite) TestConsumeParallel(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 64))
+	for i := 0; i < 50; i++ {
+		buffer.Put(i)
+	}
+	buffer.Close()
+	var sum int64
+
+	// when
+	err := ConsumeParallel[int](context.Background(), buffer, 4, func(v int) error {
+		atomic.AddInt64(&sum, int64(v))
+		return nil
+	})
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(sum, Equals, int64(49*50/2))
+}
+
+func (s *MySuite) TestConsumeParallelStopsOnError(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 8))
+	failure := errors.New("failure")
+	buffer.Put(1)
+
+	// when
+	err := ConsumeParallel[int](context.Background(), buffer, 4, func(v int) error {
+		if v == 1 {
+			return failure
+		}
+		panic("unexpected")
+	})
+
+	// then
+	c.Assert(errors.Is(err, failure), Equals, true)
+}
+
+func (s *MySuite) TestConsumeParallelReportsPanic(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 8))
+	buffer.Put(1)
+
+	// when
+	err := ConsumeParallel[int](context.Background(), buffer, 2, func(int) error {
+		panic("boom")
+	})
+
+	// then
+	var panicErr *PanicError
+	c.Assert(errors.As(err, &panicErr), Equals, true)
+	c.Assert(panicErr.Value, Equals, "boom")
+}
+
+func (s *MySuite) TestPollSeq(c *C) {
+	// given
+	buffer := New[int](NodeBased, 8)
+	for i := 0; i < 6; i++ {
+		buffer.Offer(i)
+	}
+
+	// when
+	var first, second, third []int
+	for v := range PollSeq(buffer, 4) {
+		first = append(first, v)
+	}
+	for v := range PollSeq(buffer, 4) {
+		second = append(second, v)
+		break
+	}
+	for v := range PollSeq(buffer, 4) {
+		third = append(third, v)
+	}
+
+	// then
+	c.Assert(first, DeepEquals, []int{0, 1, 2, 3})
+	c.Assert(second, DeepEquals, []int{4})
+	c.Assert(third, DeepEquals, []int{5})
+	c.Assert(buffer.Len(), Equals, uint64(0))
+}
+
+func (s *MySuite) TestPollWhereRoutesOthers(c *C) {
+	// given
+	buffer := New[int](NodeBased, 8)
+	others := New[int](NodeBased, 8)
+	for _, v := range []int{1, 3, 4, 5, 6} {
+		buffer.Offer(v)
+	}
+	even := func(v int) bool { return v%2 == 0 }
+
+	// when
+	first, firstOk := PollWhere(buffer, even, others)
+	second, secondOk := PollWhere(buffer, even, others)
+	_, thirdOk := PollWhere(buffer, even, others)
+
+	// then
+	c.Assert([]any{first, firstOk, second, secondOk, thirdOk}, DeepEquals, []any{4, true, 6, true, false})
+	var routed []int
+	for v := range PollSeq(others, 8) {
+		routed = append(routed, v)
+	}
+	c.Assert(routed, DeepEquals, []int{1, 3, 5})
+}
+
+func (s *MySuite) TestPollWhereScanBound(c *C) {
+	// given
+	buffer := New[int](NodeBased, 8)
+	others := New[int](NodeBased, 8)
+	for _, v := range []int{1, 3, 4} {
+		buffer.Offer(v)
+	}
+
+	// when
+	_, ok := PollWhere(buffer, func(v int) bool { return v == 4 }, others, WithBatchSize(2))
+
+	// then
+	c.Assert(ok, Equals, false)
+	c.Assert(others.Len(), Equals, uint64(2))
+	c.Assert(buffer.Len(), Equals, uint64(1))
+}
@@ -0,0 +1,13 @@
+//go:build amd64 || arm64
+
+package lfring
+
+// cpuRelax hints the CPU that the caller is in a spin loop: PAUSE on amd64, YIELD on arm64.
+// It lets the sibling hyperthread run, and on amd64 avoids the memory-order machine clear
+// when the loop exits on the store of another core. Every spin loop of this package calls it
+// on every iteration, before it yields if it does. The subpackages can't reach it: the waits
+// of shm sleep on a futex (or poll by sleeps) rather than spin, and the retries of ringlog
+// end as soon as the offer wins or the ring is full.
+//
+//go:noescape
+func cpuRelax()
@@ -0,0 +1,6 @@
+//go:build !amd64 && !arm64
+
+package lfring
+
+// cpuRelax does nothing on the architectures without a spin hint supported.
+func cpuRelax() {}
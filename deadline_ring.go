@@ -0,0 +1,94 @@
+package lfring
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DeadlineRing is a ring buffer of items carrying a presentation deadline, for soft real-time
+// pipelines (e.g. video processing): consumers automatically discard the items whose
+// deadline passed, since a late frame is worse than a dropped one, and the drops are
+// reported by Stats.
+type DeadlineRing[T any] struct {
+	buffer      RingBuffer[deadlineItem[T]]
+	delivered   uint64
+	dropped     uint64
+	maxLateness int64
+}
+
+type deadlineItem[T any] struct {
+	value    T
+	deadline time.Time
+}
+
+// DeadlineStats is a snapshot of the counters of DeadlineRing.
+type DeadlineStats struct {
+	Delivered uint64 `json:"delivered"`
+	Dropped   uint64 `json:"dropped"`
+	// MaxLateness is the maximum of how late the dropped items were when discarded
+	MaxLateness time.Duration `json:"max_lateness"`
+}
+
+// NewDeadlineRing build a DeadlineRing over a NodeBased buffer with capacity and options.
+func NewDeadlineRing[T any](capacity uint64, opts ...Option) *DeadlineRing[T] {
+	return &DeadlineRing[T]{buffer: New[deadlineItem[T]](NodeBased, capacity, opts...)}
+}
+
+// Offer a value with its deadline, return false if buffer is full or the claim lost in
+// contention.
+func (r *DeadlineRing[T]) Offer(value T, deadline time.Time) (success bool) {
+	return r.buffer.Offer(deadlineItem[T]{value: value, deadline: deadline})
+}
+
+// Poll the first value whose deadline not passed yet, the passed ones before it are
+// discarded. It returns false if no such value.
+func (r *DeadlineRing[T]) Poll() (value T, deadline time.Time, success bool) {
+	return r.PollAt(time.Now())
+}
+
+// PollAt is Poll at the time now, e.g. the presentation clock of the pipeline.
+func (r *DeadlineRing[T]) PollAt(now time.Time) (value T, deadline time.Time, success bool) {
+	for {
+		item, ok := r.buffer.Poll()
+		if !ok {
+			return
+		}
+
+		if lateness := now.Sub(item.deadline); lateness > 0 {
+			r.drop(lateness)
+			continue
+		}
+		atomic.AddUint64(&r.delivered, 1)
+		return item.value, item.deadline, true
+	}
+}
+
+func (r *DeadlineRing[T]) drop(lateness time.Duration) {
+	atomic.AddUint64(&r.dropped, 1)
+	for {
+		old := atomic.LoadInt64(&r.maxLateness)
+		if int64(lateness) <= old || atomic.CompareAndSwapInt64(&r.maxLateness, old, int64(lateness)) {
+			return
+		}
+	}
+}
+
+// Stats returns the counters of delivered and dropped items.
+func (r *DeadlineRing[T]) Stats() DeadlineStats {
+	return DeadlineStats{
+		Delivered:   atomic.LoadUint64(&r.delivered),
+		Dropped:     atomic.LoadUint64(&r.dropped),
+		MaxLateness: time.Duration(atomic.LoadInt64(&r.maxLateness)),
+	}
+}
+
+// Len returns the approximate number of items in buffer, including the expired ones not
+// discarded yet.
+func (r *DeadlineRing[T]) Len() uint64 {
+	return r.buffer.Len()
+}
+
+// Cap returns the real (power-of-two) capacity.
+func (r *DeadlineRing[T]) Cap() uint64 {
+	return r.buffer.Cap()
+}
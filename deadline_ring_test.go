@@ -0,0 +1,44 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestDeadlineRingDropsLateItems(c *C) {
+	// given
+	ring := NewDeadlineRing[string](8)
+	start := time.Now()
+	ring.Offer("late", start.Add(10*time.Millisecond))
+	ring.Offer("later", start.Add(20*time.Millisecond))
+	ring.Offer("on time", start.Add(40*time.Millisecond))
+
+	// when
+	value, deadline, ok := ring.PollAt(start.Add(30 * time.Millisecond))
+
+	// then
+	c.Assert(ok, Equals, true)
+	c.Assert(value, Equals, "on time")
+	c.Assert(deadline.Equal(start.Add(40*time.Millisecond)), Equals, true)
+	c.Assert(ring.Stats(), Equals, DeadlineStats{Delivered: 1, Dropped: 2, MaxLateness: 20 * time.Millisecond})
+	_, _, ok = ring.PollAt(start)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestDeadlineRingPoll(c *C) {
+	// given
+	ring := NewDeadlineRing[int](2)
+	ring.Offer(1, time.Now().Add(time.Hour))
+	ring.Offer(2, time.Now().Add(time.Hour))
+
+	// when
+	full := ring.Offer(3, time.Now().Add(time.Hour))
+	value, _, ok := ring.Poll()
+
+	// then
+	c.Assert(full, Equals, false)
+	c.Assert(ok, Equals, true)
+	c.Assert(value, Equals, 1)
+	c.Assert(ring.Len(), Equals, uint64(1))
+	c.Assert(ring.Cap(), Equals, uint64(2))
+}
@@ -0,0 +1,8 @@
+//go:build lfring_debug
+
+package lfring
+
+// debugAssertions enables the runtime checks of API contracts that are too costly for the
+// hot path, e.g. the single producer of SingleProducerOffer. Build with -tags lfring_debug
+// to turn it on.
+const debugAssertions = true
@@ -0,0 +1,5 @@
+//go:build !lfring_debug
+
+package lfring
+
+const debugAssertions = false
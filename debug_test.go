@@ -0,0 +1,43 @@
+//go:build lfring_debug
+
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestSingleProducerOfferDetectOtherProducer(c *C) {
+	// given
+	buffer := New[int](NodeBased, 8)
+	i := 0
+
+	// when
+	offer := func() {
+		buffer.SingleProducerOffer(func() (v int, finish bool) {
+			i++
+			buffer.Offer(100)
+			return i, i > 2
+		})
+	}
+
+	// then
+	c.Assert(offer, PanicMatches, ".*tail moved by other producer.*")
+}
+
+func (s *MySuite) TestSingleConsumerPollDetectOtherConsumer(c *C) {
+	// given
+	buffer := New[int](NodeBased, 8)
+	for i := 0; i < 4; i++ {
+		buffer.Offer(i)
+	}
+
+	// when
+	poll := func() {
+		buffer.SingleConsumerPoll(func(int) {
+			buffer.SingleConsumerPollVec(make([]int, 1))
+		})
+	}
+
+	// then
+	c.Assert(poll, PanicMatches, ".*concurrent SingleConsumerPollVec detected.*")
+}
@@ -0,0 +1,74 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// Dedup wraps a RingBuffer and drops an Offer that equals the most recently enqueued value,
+// for the event streams where a repeated identical state update is noise. The last value is
+// kept in a Latest, whether polled since or not.
+//
+// With concurrent producers "most recently" is by the order the offers completed, two equal
+// values offered at the same time may both get in. The other methods of the wrapped buffer
+// are available as is.
+type Dedup[T any] struct {
+	RingBuffer[T]
+	equal   func(a, b T) bool
+	last    Latest[T]
+	dropped uint64
+}
+
+// NewDedup wraps buffer, comparing values by ==.
+func NewDedup[T comparable](buffer RingBuffer[T]) *Dedup[T] {
+	return NewDedupFunc(buffer, func(a, b T) bool { return a == b })
+}
+
+// NewDedupFunc wraps buffer, comparing values by equal.
+func NewDedupFunc[T any](buffer RingBuffer[T], equal func(a, b T) bool) *Dedup[T] {
+	return &Dedup[T]{RingBuffer: buffer, equal: equal}
+}
+
+// Offer a value unless it equals the last one, a dropped duplicate counts as success, see
+// Deduplicated. Return false if buffer is full or the claim lost in contention.
+func (d *Dedup[T]) Offer(value T) (success bool) {
+	if d.duplicated(value) {
+		atomic.AddUint64(&d.dropped, 1)
+		return true
+	}
+	if !d.RingBuffer.Offer(value) {
+		return false
+	}
+	d.last.Store(value)
+	return true
+}
+
+// SingleProducerOffer offers values from valueSupplier skipping the duplicated ones, until
+// finish or buffer full. The caller must be the only producer.
+func (d *Dedup[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	last, version := d.last.Load()
+	d.RingBuffer.SingleProducerOffer(func() (v T, finish bool) {
+		for {
+			v, finish = valueSupplier()
+			if finish || version == 0 || !d.equal(last, v) {
+				break
+			}
+			atomic.AddUint64(&d.dropped, 1)
+		}
+		if !finish {
+			// the ring takes every value supplied unless finish
+			last, version = v, version+1
+			d.last.Store(v)
+		}
+		return
+	})
+}
+
+func (d *Dedup[T]) duplicated(value T) bool {
+	last, version := d.last.Load()
+	return version > 0 && d.equal(last, value)
+}
+
+// Deduplicated returns how many offers are dropped as duplicates.
+func (d *Dedup[T]) Deduplicated() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
@@ -0,0 +1,54 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"strings"
+)
+
+func (s *MySuite) TestDedupDropsRepeated(c *C) {
+	for _, t := range bufferSet {
+		// given
+		d := NewDedup(New[string](t, 8))
+
+		// when
+		for _, v := range []string{"on", "on", "off", "off", "on"} {
+			c.Assert(d.Offer(v), Equals, true)
+		}
+		first, _ := d.Poll()
+		d.Offer("on")
+
+		// then
+		c.Assert(first, Equals, "on")
+		var rest []string
+		for v, ok := d.Poll(); ok; v, ok = d.Poll() {
+			rest = append(rest, v)
+		}
+		c.Assert(rest, DeepEquals, []string{"off", "on"}, Commentf("%s", t))
+		c.Assert(d.Deduplicated(), Equals, uint64(3))
+	}
+}
+
+func (s *MySuite) TestDedupFuncSingleProducer(c *C) {
+	// given case-insensitive duplicates
+	d := NewDedupFunc(New[string](NodeBased, 8), strings.EqualFold)
+	d.Offer("a")
+	values := []string{"A", "b", "B", "c"}
+
+	// when
+	d.SingleProducerOffer(func() (string, bool) {
+		if len(values) == 0 {
+			return "", true
+		}
+		v := values[0]
+		values = values[1:]
+		return v, false
+	})
+
+	// then
+	var got []string
+	for v, ok := d.Poll(); ok; v, ok = d.Poll() {
+		got = append(got, v)
+	}
+	c.Assert(got, DeepEquals, []string{"a", "b", "c"})
+	c.Assert(d.Deduplicated(), Equals, uint64(2))
+}
@@ -0,0 +1,168 @@
+package lfring
+
+import (
+	"container/heap"
+	"sync/atomic"
+	"time"
+)
+
+// DelayRing is a queue whose values become pollable only after their ready time, e.g. the
+// retries with backoff and the scheduled tasks.
+//
+// Producers offer into an intake ring, so Offer stays lock-free. The timing structure is a
+// min-heap of ready time: a consumer that finds nothing ready moves the intake into the heap,
+// and the values whose time has come from the heap into the main ring, then polls the main
+// ring. The move is done by one consumer at a time, the others don't wait but poll the main
+// ring. Values of the same ready time are polled in the order offered.
+type DelayRing[T any] struct {
+	intake    RingBuffer[delayed[T]]
+	ready     RingBuffer[T]
+	pending   atomic.Uint64
+	capacity  uint64
+	moving    atomic.Int32
+	nextReady atomic.Int64
+	delayed   delayHeap[T]
+	seq       uint64
+}
+
+type delayed[T any] struct {
+	readyAt int64
+	seq     uint64
+	value   T
+}
+
+// noneReady is nextReady when the heap is empty.
+const noneReady = int64(^uint64(0) >> 1)
+
+// NewDelayRing build a DelayRing holds at most capacity (expands to power-of-two) values,
+// the intake and main rings are NodeBased built with opts.
+func NewDelayRing[T any](capacity uint64, opts ...Option) *DelayRing[T] {
+	intake := New[delayed[T]](NodeBased, capacity, opts...)
+	r := &DelayRing[T]{
+		intake:   intake,
+		ready:    New[T](NodeBased, capacity, opts...),
+		capacity: intake.Cap(),
+	}
+	r.nextReady.Store(noneReady)
+	return r
+}
+
+// Offer value that becomes pollable at readyAt, return false if DelayRing is full or the
+// claim lost in contention.
+func (r *DelayRing[T]) Offer(value T, readyAt time.Time) (success bool) {
+	if r.pending.Add(1) > r.capacity {
+		r.pending.Add(^uint64(0))
+		return false
+	}
+	if !r.intake.Offer(delayed[T]{readyAt: int64(readyAt.Sub(monotonicBase)), value: value}) {
+		r.pending.Add(^uint64(0))
+		return false
+	}
+	return true
+}
+
+// OfferAfter offers value that becomes pollable after d.
+func (r *DelayRing[T]) OfferAfter(value T, d time.Duration) (success bool) {
+	return r.Offer(value, time.Now().Add(d))
+}
+
+// Poll a value whose ready time has come, return false if there's none.
+func (r *DelayRing[T]) Poll() (value T, success bool) {
+	return r.PollAt(time.Now())
+}
+
+// PollAt is Poll at the time now.
+func (r *DelayRing[T]) PollAt(now time.Time) (value T, success bool) {
+	if value, success = r.ready.Poll(); !success {
+		r.move(int64(now.Sub(monotonicBase)))
+		value, success = r.ready.Poll()
+	}
+	if success {
+		r.pending.Add(^uint64(0))
+	}
+	return
+}
+
+// move moves the intake into the heap and the values ready at now into the main ring, it
+// returns at once if another consumer is moving.
+func (r *DelayRing[T]) move(now int64) {
+	if !r.moving.CompareAndSwap(0, 1) {
+		return
+	}
+	defer r.moving.Store(0)
+
+	for {
+		item, ok := r.intake.Poll()
+		if !ok {
+			break
+		}
+		// the seq breaks the tie of ready time by the order moved, which is the order offered
+		item.seq = r.seq
+		r.seq++
+		heap.Push(&r.delayed, item)
+	}
+
+	for len(r.delayed) > 0 && r.delayed[0].readyAt <= now {
+		if !r.ready.Offer(r.delayed[0].value) {
+			break
+		}
+		heap.Pop(&r.delayed)
+	}
+
+	next := noneReady
+	if len(r.delayed) > 0 {
+		next = r.delayed[0].readyAt
+	}
+	r.nextReady.Store(next)
+}
+
+// NextReady returns the earliest ready time of the values not pollable yet as of the last
+// Poll failed, so a consumer can sleep until then. It returns false if there's none, which
+// doesn't count the values offered since.
+func (r *DelayRing[T]) NextReady() (readyAt time.Time, ok bool) {
+	next := r.nextReady.Load()
+	if next == noneReady {
+		return readyAt, false
+	}
+	return monotonicTime(next), true
+}
+
+// Len returns the approximate number of values, ready or not.
+func (r *DelayRing[T]) Len() uint64 {
+	return min(r.pending.Load(), r.capacity)
+}
+
+// Cap returns the real (power-of-two) capacity.
+func (r *DelayRing[T]) Cap() uint64 {
+	return r.capacity
+}
+
+// delayHeap is the min-heap of ready time, see container/heap.
+type delayHeap[T any] []delayed[T]
+
+func (h delayHeap[T]) Len() int {
+	return len(h)
+}
+
+func (h delayHeap[T]) Less(i, j int) bool {
+	if h[i].readyAt != h[j].readyAt {
+		return h[i].readyAt < h[j].readyAt
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h delayHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *delayHeap[T]) Push(x any) {
+	*h = append(*h, x.(delayed[T]))
+}
+
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = delayed[T]{}
+	*h = old[:len(old)-1]
+	return item
+}
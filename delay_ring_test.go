@@ -0,0 +1,53 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestDelayRingReadyTime(c *C) {
+	// given
+	r := NewDelayRing[string](8)
+	now := time.Now()
+	r.Offer("later", now.Add(2*time.Second))
+	r.Offer("soon", now.Add(time.Second))
+	r.Offer("soon too", now.Add(time.Second))
+
+	// when
+	_, early := r.PollAt(now)
+	next, hasNext := r.NextReady()
+	first, _ := r.PollAt(now.Add(time.Second))
+	second, _ := r.PollAt(now.Add(time.Second))
+	_, notYet := r.PollAt(now.Add(time.Second))
+	third, _ := r.PollAt(now.Add(3 * time.Second))
+
+	// then
+	c.Assert(early, Equals, false)
+	c.Assert(hasNext, Equals, true)
+	c.Assert(next.Sub(now), Equals, time.Second)
+	c.Assert([]string{first, second, third}, DeepEquals, []string{"soon", "soon too", "later"})
+	c.Assert(notYet, Equals, false)
+	c.Assert(r.Len(), Equals, uint64(0))
+	_, hasNext = r.NextReady()
+	c.Assert(hasNext, Equals, false)
+}
+
+func (s *MySuite) TestDelayRingFull(c *C) {
+	// given
+	r := NewDelayRing[int](2)
+
+	// when
+	r.OfferAfter(1, 0)
+	r.OfferAfter(2, time.Hour)
+	full := r.OfferAfter(3, 0)
+	v, ok := r.Poll()
+	again := r.OfferAfter(3, 0)
+
+	// then
+	c.Assert(full, Equals, false)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 1)
+	c.Assert(again, Equals, true)
+	c.Assert(r.Len(), Equals, uint64(2))
+	c.Assert(r.Cap(), Equals, uint64(2))
+}
@@ -0,0 +1,105 @@
+package lfring
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// DoubleBuffer is a lock-free double buffer for bulk handoff: writers append to the active
+// half, the consumer swaps the halves at once and drains the one just retired in bulk, e.g.
+// a metrics aggregator flushes the samples of the last period. The order of values is not
+// guaranteed across writers.
+//
+// Append claims a slot by a fetch-and-add and never retries (there's no CAS to lose), it
+// fails only if the active half is full. Swap waits the appends in flight on the retired
+// half to finish before draining it, which is a few instructions.
+type DoubleBuffer[T any] struct {
+	active  atomic.Pointer[bufferHalf[T]]
+	halves  [2]bufferHalf[T]
+	dropped atomic.Uint64
+
+	consumerGuard ownerGuard
+}
+
+type bufferHalf[T any] struct {
+	claimed   atomic.Uint64
+	writers   atomic.Int64
+	_padding0 [48]byte
+	values    []T
+}
+
+// NewDoubleBuffer build a DoubleBuffer of two halves, each holds capacity values.
+func NewDoubleBuffer[T any](capacity uint64) *DoubleBuffer[T] {
+	b := &DoubleBuffer[T]{}
+	for i := range b.halves {
+		b.halves[i].values = make([]T, capacity)
+	}
+	b.active.Store(&b.halves[0])
+	return b
+}
+
+// Append a value to the active half, return false (and counts as Dropped) if it's full.
+func (b *DoubleBuffer[T]) Append(value T) (success bool) {
+	for {
+		half := b.active.Load()
+		half.writers.Add(1)
+		// swapped before announced, the consumer may not wait for us
+		if b.active.Load() != half {
+			half.writers.Add(-1)
+			continue
+		}
+
+		i := half.claimed.Add(1) - 1
+		if i < uint64(len(half.values)) {
+			half.values[i] = value
+			success = true
+		}
+		half.writers.Add(-1)
+		if !success {
+			b.dropped.Add(1)
+		}
+		return success
+	}
+}
+
+// Swap makes the other half active, and passes the values appended to the retired half to
+// drain in one call, the slice is reused after drain returns. The caller must be the only
+// consumer, build with tag lfring_debug to detect the violation.
+func (b *DoubleBuffer[T]) Swap(drain func(values []T)) {
+	if debugAssertions {
+		b.consumerGuard.enter("DoubleBuffer.Swap")
+		defer b.consumerGuard.exit()
+	}
+
+	retired := b.active.Load()
+	next := &b.halves[0]
+	if retired == next {
+		next = &b.halves[1]
+	}
+	b.active.Store(next)
+	for retired.writers.Load() != 0 {
+		cpuRelax()
+		runtime.Gosched()
+	}
+
+	n := min(retired.claimed.Load(), uint64(len(retired.values)))
+	drain(retired.values[:n])
+	clear(retired.values[:n])
+	retired.claimed.Store(0)
+}
+
+// Len returns the approximate number of values in the active half.
+func (b *DoubleBuffer[T]) Len() uint64 {
+	half := b.active.Load()
+	return min(half.claimed.Load(), uint64(len(half.values)))
+}
+
+// Cap returns how many values a half holds.
+func (b *DoubleBuffer[T]) Cap() uint64 {
+	return uint64(len(b.halves[0].values))
+}
+
+// Dropped returns how many values are dropped since the active half was full.
+func (b *DoubleBuffer[T]) Dropped() uint64 {
+	return b.dropped.Load()
+}
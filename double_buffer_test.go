@@ -0,0 +1,63 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"sync"
+)
+
+func (s *MySuite) TestDoubleBufferSwap(c *C) {
+	// given
+	b := NewDoubleBuffer[int](2)
+	var drained [][]int
+	collect := func(values []int) { drained = append(drained, append([]int(nil), values...)) }
+
+	// when
+	b.Append(1)
+	b.Append(2)
+	full := b.Append(3)
+	b.Swap(collect)
+	b.Append(4)
+	b.Swap(collect)
+	b.Swap(collect)
+
+	// then
+	c.Assert(full, Equals, false)
+	c.Assert(drained, DeepEquals, [][]int{{1, 2}, {4}, nil})
+	c.Assert(b.Dropped(), Equals, uint64(1))
+	c.Assert(b.Len(), Equals, uint64(0))
+	c.Assert(b.Cap(), Equals, uint64(2))
+}
+
+func (s *MySuite) TestDoubleBufferConcurrentAppend(c *C) {
+	// given
+	const writers, perWriter = 4, 1000
+	b := NewDoubleBuffer[int](writers * perWriter)
+	var wg sync.WaitGroup
+	total := 0
+	sum := func(values []int) {
+		for _, v := range values {
+			total += v
+		}
+	}
+
+	// when
+	wg.Add(writers)
+	for w := 0; w < writers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				b.Append(1)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		b.Swap(sum)
+	}
+	wg.Wait()
+	b.Swap(sum)
+	b.Swap(sum)
+
+	// then
+	c.Assert(total, Equals, writers*perWriter)
+	c.Assert(b.Dropped(), Equals, uint64(0))
+}
@@ -0,0 +1,161 @@
+// Package ebr is an epoch-based memory reclamation utility for lock-free structures: a
+// node unlinked by one goroutine may still be read by the others, so it can't be reused (or
+// freed, e.g. back to a pool or an off-heap arena) until every goroutine has moved past it.
+//
+// The goroutines that touch shared nodes register a Participant of the same Domain, and wrap
+// every access into Enter / Exit. A node unlinked is handed to Retire with the function that
+// frees it, and the function is called once the global epoch advanced twice since, which
+// means every participant has left the critical sections that may have seen the node:
+//
+//	p := domain.Register()
+//	defer p.Unregister()
+//
+//	p.Enter()
+//	n := stack.pop()
+//	p.Exit()
+//	p.Retire(func() { pool.Put(n) })
+//
+// The garbage collector already makes the memory safe in Go, reclamation is about reuse:
+// without it a recycled node may be observed through a stale pointer (ABA).
+package ebr
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// collectEvery is how many Retire calls between two attempts to advance and collect.
+const collectEvery = 64
+
+// Domain is the shared epoch of a group of participants.
+type Domain struct {
+	epoch uint64
+
+	mu           sync.Mutex
+	participants atomic.Pointer[[]*Participant]
+}
+
+// NewDomain build a Domain at epoch 0.
+func NewDomain() *Domain {
+	return &Domain{}
+}
+
+// Epoch returns the global epoch.
+func (d *Domain) Epoch() uint64 {
+	return atomic.LoadUint64(&d.epoch)
+}
+
+// Register adds a participant, a participant isn't safe for concurrent use, register one
+// per goroutine.
+func (d *Domain) Register() *Participant {
+	p := &Participant{domain: d}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var participants []*Participant
+	if old := d.participants.Load(); old != nil {
+		participants = append(participants, *old...)
+	}
+	participants = append(participants, p)
+	d.participants.Store(&participants)
+	return p
+}
+
+// TryAdvance advances the global epoch if every active participant has observed the current
+// one, returns the global epoch.
+func (d *Domain) TryAdvance() uint64 {
+	epoch := atomic.LoadUint64(&d.epoch)
+	if participants := d.participants.Load(); participants != nil {
+		for _, p := range *participants {
+			state := p.state.Load()
+			if state&active != 0 && state>>1 != epoch {
+				return epoch
+			}
+		}
+	}
+
+	if atomic.CompareAndSwapUint64(&d.epoch, epoch, epoch+1) {
+		return epoch + 1
+	}
+	return atomic.LoadUint64(&d.epoch)
+}
+
+func (d *Domain) unregister(p *Participant) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	old := d.participants.Load()
+	if old == nil {
+		return
+	}
+
+	var participants []*Participant
+	for _, other := range *old {
+		if other != p {
+			participants = append(participants, other)
+		}
+	}
+	d.participants.Store(&participants)
+}
+
+// active is the flag bit of Participant.state, the rest bits are the observed epoch.
+const active = 1
+
+// Participant is a goroutine that accesses the shared nodes of a Domain.
+type Participant struct {
+	domain  *Domain
+	state   atomic.Uint64
+	retired []retired
+	calls   int
+}
+
+type retired struct {
+	epoch uint64
+	free  func()
+}
+
+// Enter starts a critical section, the nodes reachable from the shared structure in it
+// stay valid until Exit.
+func (p *Participant) Enter() {
+	epoch := atomic.LoadUint64(&p.domain.epoch)
+	p.state.Store(epoch<<1 | active)
+}
+
+// Exit ends the critical section.
+func (p *Participant) Exit() {
+	p.state.Store(p.state.Load() &^ active)
+}
+
+// Retire defers free until no participant can observe the retired node, the node must be
+// unlinked from the shared structure already.
+func (p *Participant) Retire(free func()) {
+	p.retired = append(p.retired, retired{epoch: p.domain.Epoch(), free: free})
+	if p.calls++; p.calls%collectEvery == 0 {
+		p.Collect()
+	}
+}
+
+// Collect tries to advance the global epoch, then frees the retired nodes that are safe,
+// returns how many nodes still pending.
+func (p *Participant) Collect() (pending int) {
+	epoch := p.domain.TryAdvance()
+
+	kept := p.retired[:0]
+	for _, r := range p.retired {
+		if r.epoch+2 <= epoch {
+			r.free()
+			continue
+		}
+		kept = append(kept, r)
+	}
+	clear(p.retired[len(kept):])
+	p.retired = kept
+	return len(kept)
+}
+
+// Unregister removes the participant from Domain, it must be outside the critical section.
+// The pending retired nodes are freed once safe by a final Collect, the rest are dropped
+// to the garbage collector.
+func (p *Participant) Unregister() {
+	p.Collect()
+	p.domain.unregister(p)
+}
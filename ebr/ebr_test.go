@@ -0,0 +1,89 @@
+package ebr
+
+import (
+	. "gopkg.in/check.v1"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// hook up go-check to go testing
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+func (s *MySuite) TestRetiredFreedAfterTwoEpochs(c *C) {
+	// given
+	domain := NewDomain()
+	reader := domain.Register()
+	writer := domain.Register()
+	freed := false
+
+	// when
+	reader.Enter()
+	writer.Retire(func() { freed = true })
+	writer.Collect()
+	writer.Collect()
+
+	// then the reader still in the critical section pins the epoch
+	c.Assert(freed, Equals, false)
+	c.Assert(domain.Epoch(), Equals, uint64(1))
+
+	// when
+	reader.Exit()
+	pending := writer.Collect()
+
+	// then
+	c.Assert(pending, Equals, 0)
+	c.Assert(freed, Equals, true)
+}
+
+func (s *MySuite) TestUnregisterStopsPinning(c *C) {
+	// given
+	domain := NewDomain()
+	stale := domain.Register()
+	stale.Enter()
+	p := domain.Register()
+
+	// when
+	stale.Exit()
+	stale.Unregister()
+	freed := false
+	p.Retire(func() { freed = true })
+	p.Collect()
+	p.Collect()
+
+	// then
+	c.Assert(freed, Equals, true)
+}
+
+func (s *MySuite) TestConcurrentRetire(c *C) {
+	// given
+	domain := NewDomain()
+	const goroutines, perGoroutine = 4, 1000
+	var freed int64
+	var wg sync.WaitGroup
+
+	// when
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			p := domain.Register()
+			for j := 0; j < perGoroutine; j++ {
+				p.Enter()
+				p.Exit()
+				p.Retire(func() { atomic.AddInt64(&freed, 1) })
+			}
+			for p.Collect() > 0 {
+			}
+			p.Unregister()
+		}()
+	}
+	wg.Wait()
+
+	// then
+	c.Assert(freed, Equals, int64(goroutines*perGoroutine))
+}
@@ -0,0 +1,31 @@
+package lfring
+
+import (
+	"time"
+)
+
+// TimedConsumer is implemented by the buffers that record the enqueue time of every slot,
+// namely NodeBased, FetchAdd and Relaxed built WithEnqueueTime. The time is kept in the slot
+// rather than in T, so queue delay can be measured without changing the payload:
+//
+//	if timed, ok := buffer.(lfring.TimedConsumer[Order]); ok {
+//		order, enqueued, ok := timed.PollTimed()
+//		delay.Observe(time.Since(enqueued))
+//	}
+//
+// The enqueue time is zero if the buffer doesn't record it.
+type TimedConsumer[T any] interface {
+	PollTimed() (value T, enqueued time.Time, success bool)
+}
+
+// monotonicBase is the origin of monotonicNow, time.Since reads the monotonic clock, so the
+// enqueue times are immune to wall clock changes.
+var monotonicBase = time.Now()
+
+func monotonicNow() int64 {
+	return int64(time.Since(monotonicBase))
+}
+
+func monotonicTime(t int64) time.Time {
+	return monotonicBase.Add(time.Duration(t))
+}
@@ -0,0 +1,61 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestPollTimed(c *C) {
+	for _, t := range []BufferType{NodeBased, FetchAdd, Relaxed} {
+		// given
+		buffer := New[int](t, 8, WithEnqueueTime(), WithShards(2))
+		before := time.Now()
+		MustOffer[int](buffer, 1)
+		after := time.Now()
+
+		// when
+		value, enqueued, ok := buffer.(TimedConsumer[int]).PollTimed()
+
+		// then
+		c.Assert(ok, Equals, true)
+		c.Assert(value, Equals, 1)
+		c.Assert(enqueued.Before(before), Equals, false)
+		c.Assert(enqueued.After(after), Equals, false)
+		_, _, ok = buffer.(TimedConsumer[int]).PollTimed()
+		c.Assert(ok, Equals, false)
+	}
+}
+
+func (s *MySuite) TestPollTimedSingleProducerOffer(c *C) {
+	// given
+	buffer := New[int](NodeBased, 4, WithEnqueueTime())
+	done := false
+	buffer.SingleProducerOffer(func() (v int, finish bool) {
+		if done {
+			return 0, true
+		}
+		done = true
+		return 1, false
+	})
+
+	// when
+	_, enqueued, ok := buffer.(TimedConsumer[int]).PollTimed()
+
+	// then
+	c.Assert(ok, Equals, true)
+	c.Assert(time.Since(enqueued) >= 0, Equals, true)
+	c.Assert(enqueued.IsZero(), Equals, false)
+}
+
+func (s *MySuite) TestPollTimedWithoutOption(c *C) {
+	// given
+	buffer := New[int](NodeBased, 4)
+	MustOffer[int](buffer, 1)
+
+	// when
+	_, enqueued, ok := buffer.(TimedConsumer[int]).PollTimed()
+
+	// then
+	c.Assert(ok, Equals, true)
+	c.Assert(enqueued.IsZero(), Equals, true)
+}
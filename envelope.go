@@ -0,0 +1,63 @@
+package lfring
+
+// Envelope stamps a value with its origin: the id of the producer and the sequence of the
+// value among the ones that producer offered (starts from 0, no gap). Consumers of a shared
+// ring can attribute and order values per source by them, without changing the type of
+// value.
+type Envelope[T any] struct {
+	Producer uint64
+	Seq      uint64
+	Value    T
+}
+
+// TaggedProducer offers values in Envelope, stamped with its id and sequence. It implements
+// Producer[T] over a buffer of Envelope[T]:
+//
+//	buffer := lfring.New[lfring.Envelope[Order]](lfring.NodeBased, 1024)
+//	p := lfring.NewTaggedProducer[Order](buffer, 1)
+//	p.Offer(order)
+//
+// The sequence only counts the successful offers, so a TaggedProducer isn't safe for
+// concurrent use, use one per producer goroutine instead.
+type TaggedProducer[T any] struct {
+	dst Producer[Envelope[T]]
+	id  uint64
+	seq uint64
+}
+
+// NewTaggedProducer build a TaggedProducer of id offering to dst.
+func NewTaggedProducer[T any](dst Producer[Envelope[T]], id uint64) *TaggedProducer[T] {
+	return &TaggedProducer[T]{dst: dst, id: id}
+}
+
+// Offer a value in Envelope.
+func (p *TaggedProducer[T]) Offer(value T) (success bool) {
+	if success = p.dst.Offer(Envelope[T]{Producer: p.id, Seq: p.seq, Value: value}); success {
+		p.seq++
+	}
+	return
+}
+
+// SingleProducerOffer offers values from valueSupplier in Envelope, see
+// Producer.SingleProducerOffer.
+func (p *TaggedProducer[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	p.dst.SingleProducerOffer(func() (e Envelope[T], finish bool) {
+		v, finish := valueSupplier()
+		if finish {
+			return e, true
+		}
+		e = Envelope[T]{Producer: p.id, Seq: p.seq, Value: v}
+		p.seq++
+		return e, false
+	})
+}
+
+// ID returns the producer id.
+func (p *TaggedProducer[T]) ID() uint64 {
+	return p.id
+}
+
+// Next returns the sequence the next offered value will be stamped.
+func (p *TaggedProducer[T]) Next() uint64 {
+	return p.seq
+}
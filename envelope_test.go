@@ -0,0 +1,47 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestTaggedProducers(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[Envelope[string]](t, 8)
+		p1 := NewTaggedProducer[string](buffer, 1)
+		p2 := NewTaggedProducer[string](AsProducer(buffer), 2)
+
+		// when
+		p1.Offer("a")
+		p2.Offer("b")
+		i := 0
+		p1.SingleProducerOffer(func() (v string, finish bool) {
+			i++
+			return "c", i > 2
+		})
+
+		// then
+		c.Assert(p1.Next(), Equals, uint64(3))
+		c.Assert(p2.Next(), Equals, uint64(1))
+		c.Assert(MustPoll[Envelope[string]](buffer), Equals, Envelope[string]{Producer: 1, Seq: 0, Value: "a"})
+		c.Assert(MustPoll[Envelope[string]](buffer), Equals, Envelope[string]{Producer: 2, Seq: 0, Value: "b"})
+		c.Assert(MustPoll[Envelope[string]](buffer), Equals, Envelope[string]{Producer: 1, Seq: 1, Value: "c"})
+		c.Assert(MustPoll[Envelope[string]](buffer), Equals, Envelope[string]{Producer: 1, Seq: 2, Value: "c"})
+	}
+}
+
+func (s *MySuite) TestTaggedProducerSeqOnlyCountsSuccess(c *C) {
+	// given
+	buffer := New[Envelope[int]](NodeBased, 2)
+	p := NewTaggedProducer[int](buffer, 7)
+	p.Offer(1)
+	p.Offer(2)
+
+	// when
+	offered := p.Offer(3)
+
+	// then
+	c.Assert(offered, Equals, false)
+	c.Assert(p.Next(), Equals, uint64(2))
+	c.Assert(p.ID(), Equals, uint64(7))
+}
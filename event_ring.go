@@ -0,0 +1,86 @@
+package lfring
+
+import (
+	"context"
+)
+
+// EventRing is a multi-producer multi-consumer ring buffer whose slots hold pre-allocated
+// events rather than values that copied in and out.
+//
+// Producers claim a slot and mutate the event in place by a translator, consumers read the
+// event in place by a handler, hence there's neither allocation nor copy of T on the hot
+// path, which matters when T is large. The sequencing is done by Sequencer, see there for
+// the details.
+//
+// Events are reused across laps of the ring, so a translator should overwrite every field
+// it cares about, and neither side should keep the pointer after the callback returns.
+type EventRing[T any] struct {
+	sequencer Sequencer
+	events    []T
+	trace     bool
+}
+
+// NewEventRing build an EventRing, capacity expands to power-of-two as New does. If factory
+// is not nil, it's called once on every slot to initialize the pre-allocated event (e.g.
+// allocate the inner buffers). WithWaitStrategy decides how WaitFor waits, WithTrace emits
+// the regions of claim and publish.
+func NewEventRing[T any](capacity uint64, factory func(*T), opts ...Option) *EventRing[T] {
+	c := newConfig(opts)
+	r := &EventRing[T]{trace: c.trace}
+	r.sequencer.init(capacity, c)
+	r.events = make([]T, r.sequencer.Capacity())
+	if factory != nil {
+		for i := range r.events {
+			factory(&r.events[i])
+		}
+	}
+
+	return r
+}
+
+// OfferWith claims the tail slot and calls translator to fill the event, return false if
+// buffer is full or the claim lost in contention.
+func (r *EventRing[T]) OfferWith(translator func(*T)) (success bool) {
+	region := startRegion(r.trace, context.Background(), RegionClaim)
+	seq, success := r.sequencer.TryClaim()
+	endRegion(region)
+	if !success {
+		return false
+	}
+
+	region = startRegion(r.trace, context.Background(), RegionPublish)
+	translator(&r.events[r.sequencer.Index(seq)])
+	r.sequencer.Publish(seq)
+	endRegion(region)
+	return true
+}
+
+// PollWith claims the head slot and calls handler to read the event, return false if buffer
+// is empty or the claim lost in contention.
+func (r *EventRing[T]) PollWith(handler func(*T)) (success bool) {
+	seq, success := r.sequencer.TryAcquire()
+	if !success {
+		return false
+	}
+
+	handler(&r.events[r.sequencer.Index(seq)])
+	r.sequencer.Release(seq)
+	return true
+}
+
+// Capacity returns the real (power-of-two) capacity.
+func (r *EventRing[T]) Capacity() uint64 {
+	return r.sequencer.Capacity()
+}
+
+// Published returns the sequence next to the contiguously published ones, see
+// Sequencer.Published.
+func (r *EventRing[T]) Published() uint64 {
+	return r.sequencer.Published()
+}
+
+// WaitFor blocks until seq and all sequences before it are published, see
+// Sequencer.WaitFor.
+func (r *EventRing[T]) WaitFor(seq uint64) (available uint64) {
+	return r.sequencer.WaitFor(seq)
+}
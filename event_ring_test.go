@@ -0,0 +1,110 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"sync"
+)
+
+type fakeEvent struct {
+	id      int
+	payload []byte
+}
+
+func (s *MySuite) TestEventRingInitializeEverySlot(c *C) {
+	// given
+	initialized := 0
+
+	// when
+	ring := NewEventRing[fakeEvent](10, func(e *fakeEvent) {
+		e.payload = make([]byte, 8)
+		initialized++
+	})
+
+	// then
+	c.Assert(ring.Capacity(), Equals, uint64(16))
+	c.Assert(initialized, Equals, 16)
+}
+
+func (s *MySuite) TestEventRingOfferWithAndPollWith(c *C) {
+	// given
+	ring := NewEventRing[fakeEvent](4, func(e *fakeEvent) {
+		e.payload = make([]byte, 1)
+	})
+
+	// when
+	for i := 0; i < 4; i++ {
+		offered := ring.OfferWith(func(e *fakeEvent) {
+			e.id = i
+			e.payload[0] = byte(i)
+		})
+		c.Assert(offered, Equals, true)
+	}
+	full := ring.OfferWith(func(e *fakeEvent) {})
+
+	// then
+	c.Assert(full, Equals, false)
+	for i := 0; i < 4; i++ {
+		polled := ring.PollWith(func(e *fakeEvent) {
+			c.Assert(e.id, Equals, i)
+			c.Assert(e.payload[0], Equals, byte(i))
+		})
+		c.Assert(polled, Equals, true)
+	}
+	c.Assert(ring.PollWith(func(e *fakeEvent) {}), Equals, false)
+}
+
+func (s *MySuite) TestEventRingConcurrencyRW(c *C) {
+	// given
+	ring := NewEventRing[fakeEvent](8, nil)
+	counts := make([]int, 300)
+
+	// when
+	var wg sync.WaitGroup
+	wg.Add(3)
+	for p := 0; p < 3; p++ {
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				for !ring.OfferWith(func(e *fakeEvent) { e.id = p*100 + i }) {
+				}
+			}
+		}(p)
+	}
+	for polled := 0; polled < 300; {
+		if ring.PollWith(func(e *fakeEvent) { counts[e.id]++ }) {
+			polled++
+		}
+	}
+	wg.Wait()
+
+	// then
+	for _, cnt := range counts {
+		c.Assert(cnt, Equals, 1)
+	}
+}
+
+func (s *MySuite) TestEventRingPublishedAndWaitFor(c *C) {
+	// given
+	ring := NewEventRing[fakeEvent](8, nil, WithWaitStrategy(BusySpinWait()))
+	c.Assert(ring.Published(), Equals, uint64(0))
+
+	// when
+	for i := 0; i < 3; i++ {
+		ring.OfferWith(func(e *fakeEvent) { e.id = i })
+	}
+	ring.PollWith(func(e *fakeEvent) {})
+
+	// then
+	c.Assert(ring.Published(), Equals, uint64(3))
+	c.Assert(ring.WaitFor(1), Equals, uint64(2))
+
+	// when
+	done := make(chan uint64)
+	go func() { done <- ring.WaitFor(4) }()
+	for i := 0; i < 2; i++ {
+		ring.OfferWith(func(e *fakeEvent) { e.id = i })
+	}
+
+	// then
+	c.Assert(<-done, Equals, uint64(4))
+}
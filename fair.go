@@ -0,0 +1,157 @@
+package lfring
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Fair wraps a RingBuffer with the ticket fairness: the producers (and the consumers, by a
+// line of their own) take tickets and are served in the ticket order, only the one being
+// served tries the buffer. It prevents the pathological case of the raw CAS race, where a hot
+// goroutine keeps winning the claim and the others starve for seconds, at the cost of
+// serializing the producers (and the consumers) through their line.
+//
+// Send and Recv wait in the line, and for the buffer when served, by WithWaitStrategy. Offer
+// and Poll never wait: they only try when nobody waits in the line, so they don't barge in
+// ahead of the waiters either. The other methods of the wrapped buffer are available as is,
+// and bypass the lines.
+type Fair[T any] struct {
+	RingBuffer[T]
+	wait      WaitStrategy
+	producers ticketLine
+	consumers ticketLine
+}
+
+// NewFair wraps buffer, see WithWaitStrategy for how Send and Recv wait.
+func NewFair[T any](buffer RingBuffer[T], opts ...Option) *Fair[T] {
+	return &Fair[T]{RingBuffer: buffer, wait: newConfig(opts).wait}
+}
+
+// Offer a value if no producer waits in the line, return false if one does, buffer is full
+// or the claim lost in contention.
+func (f *Fair[T]) Offer(value T) (success bool) {
+	ticket, ok := f.producers.tryTake()
+	if !ok {
+		return false
+	}
+	defer f.producers.done(ticket)
+	return f.RingBuffer.Offer(value)
+}
+
+// Poll a value if no consumer waits in the line, return false if one does, buffer is empty
+// or the claim lost in contention.
+func (f *Fair[T]) Poll() (value T, success bool) {
+	ticket, ok := f.consumers.tryTake()
+	if !ok {
+		return
+	}
+	defer f.consumers.done(ticket)
+	return f.RingBuffer.Poll()
+}
+
+// Send waits for the turn of the caller among the producers, then for the room of value, it
+// returns ctx.Err() if ctx done before sent. A producer gives up its turn when ctx done, the
+// line goes on.
+func (f *Fair[T]) Send(ctx context.Context, value T) error {
+	return f.serve(ctx, &f.producers, func() bool { return f.RingBuffer.Offer(value) })
+}
+
+// Recv waits for the turn of the caller among the consumers, then for a value, it returns
+// ctx.Err() if ctx done before received.
+func (f *Fair[T]) Recv(ctx context.Context) (value T, err error) {
+	err = f.serve(ctx, &f.consumers, func() (success bool) {
+		value, success = f.RingBuffer.Poll()
+		return
+	})
+	return
+}
+
+func (f *Fair[T]) serve(ctx context.Context, line *ticketLine, try func() bool) error {
+	ticket := line.take()
+	if err := line.await(ctx, ticket, f.wait); err != nil {
+		return err
+	}
+	defer line.done(ticket)
+
+	done := ctx.Done()
+	for attempt := 1; !try(); attempt++ {
+		select {
+		case <-done:
+			return ctx.Err()
+		default:
+		}
+		f.wait.Wait(attempt)
+	}
+	return nil
+}
+
+// ticketLine serves the tickets in order, the holder of ticket serving is the one served. The
+// tickets given up before served are skipped by done.
+type ticketLine struct {
+	next    atomic.Uint64
+	serving atomic.Uint64
+
+	// guards the moves of serving and abandoned
+	mu        sync.Mutex
+	abandoned map[uint64]struct{}
+}
+
+func (l *ticketLine) take() uint64 {
+	return l.next.Add(1) - 1
+}
+
+// tryTake takes a ticket only when it's served right away.
+func (l *ticketLine) tryTake() (ticket uint64, ok bool) {
+	// serving never passes next, and moves by the holder of serving only, nobody holds it if
+	// they are equal
+	serving := l.serving.Load()
+	return serving, l.next.CompareAndSwap(serving, serving+1)
+}
+
+func (l *ticketLine) await(ctx context.Context, ticket uint64, wait WaitStrategy) error {
+	done := ctx.Done()
+	for attempt := 1; l.serving.Load() != ticket; attempt++ {
+		select {
+		case <-done:
+			l.abandon(ticket)
+			return ctx.Err()
+		default:
+		}
+		wait.Wait(attempt)
+	}
+	return nil
+}
+
+func (l *ticketLine) done(ticket uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(ticket)
+}
+
+// abandon gives up ticket, it's skipped when its turn comes, or right away if it's served
+// already.
+func (l *ticketLine) abandon(ticket uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.serving.Load() == ticket {
+		l.advance(ticket)
+		return
+	}
+	if l.abandoned == nil {
+		l.abandoned = make(map[uint64]struct{})
+	}
+	l.abandoned[ticket] = struct{}{}
+}
+
+func (l *ticketLine) advance(ticket uint64) {
+	next := ticket + 1
+	for {
+		if _, ok := l.abandoned[next]; !ok {
+			break
+		}
+		delete(l.abandoned, next)
+		next++
+	}
+	l.serving.Store(next)
+}
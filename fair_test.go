@@ -0,0 +1,94 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"runtime"
+	"time"
+)
+
+func (s *MySuite) TestFairServeInTicketOrder(c *C) {
+	// given a full buffer
+	fair := NewFair(New[int](NodeBased, 2), WithWaitStrategy(YieldingWait()))
+	fair.Offer(0)
+	fair.Offer(0)
+
+	// when producers line up one by one
+	errs := make(chan error, 3)
+	lined := fair.producers.next.Load()
+	for i := 1; i <= 3; i++ {
+		go func(v int) { errs <- fair.Send(context.Background(), v) }(i)
+		for fair.producers.next.Load() != lined+uint64(i) {
+			runtime.Gosched()
+		}
+	}
+	barged := fair.Offer(9)
+	var got []int
+	for len(got) < 3 {
+		if v, ok := fair.Poll(); ok && v != 0 {
+			got = append(got, v)
+		} else {
+			runtime.Gosched()
+		}
+	}
+
+	// then
+	c.Assert(barged, Equals, false)
+	c.Assert(got, DeepEquals, []int{1, 2, 3})
+	for i := 0; i < 3; i++ {
+		c.Assert(<-errs, IsNil)
+	}
+}
+
+func (s *MySuite) TestFairSkipGivenUpTicket(c *C) {
+	// given a full buffer and a producer waiting
+	fair := NewFair(New[int](NodeBased, 2), WithWaitStrategy(YieldingWait()))
+	fair.Offer(0)
+	fair.Offer(0)
+	lined := fair.producers.next.Load()
+	first := make(chan error, 1)
+	go func() { first <- fair.Send(context.Background(), 1) }()
+	for fair.producers.next.Load() != lined+1 {
+		runtime.Gosched()
+	}
+
+	// when the next one gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	givenUp := fair.Send(ctx, 2)
+	third := make(chan error, 1)
+	go func() { third <- fair.Send(context.Background(), 3) }()
+	for fair.producers.next.Load() != lined+3 {
+		runtime.Gosched()
+	}
+	var got []int
+	for len(got) < 2 {
+		if v, err := fair.Recv(context.Background()); err == nil && v != 0 {
+			got = append(got, v)
+		}
+	}
+
+	// then
+	c.Assert(givenUp, Equals, context.DeadlineExceeded)
+	c.Assert(got, DeepEquals, []int{1, 3})
+	c.Assert(<-first, IsNil)
+	c.Assert(<-third, IsNil)
+	c.Assert(fair.producers.serving.Load(), Equals, lined+3)
+}
+
+func (s *MySuite) TestFairRecvContext(c *C) {
+	// given
+	fair := NewFair(New[int](NodeBased, 2))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	_, err := fair.Recv(ctx)
+	v, ok := fair.Poll()
+
+	// then the line goes on
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(ok, Equals, false)
+	c.Assert(v, Equals, 0)
+	c.Assert(fair.consumers.serving.Load(), Equals, fair.consumers.next.Load())
+}
@@ -0,0 +1,158 @@
+package lfring
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// fetchAdd is a NodeBased ring buffer whose producers claim tail by fetch-and-add rather than
+// CAS. A CAS fails whenever another producer moved tail in between, so under many producers
+// most of the attempts are wasted, and an unlucky producer may fail again and again. A
+// fetch-and-add never fails: every producer gets a distinct sequence at the first attempt,
+// so the producers are served in the order they arrive.
+//
+// As the claim can't be undone, Offer checks the room by head and tail beforehand. Producers
+// that passed the check at the same time may claim more sequences than the free slots, the
+// overshooting ones have to fill the slot they claimed, so they block until it's polled, by
+// the per-slot sequence (node.step). It only happens when buffer is nearly full, but if the
+// consumers stop then, an overshooting Offer never returns. So the producers are not
+// wait-free, only free of the retries and failures for contention. The consumer side is the
+// same as NodeBased.
+//
+// The other offers (OfferIf, OfferAllOrNothing and so on) never fail for contention either:
+// they claim by fetch-and-add the same way, or retry the CAS where the claim must be coupled
+// with a check.
+type fetchAdd[T any] struct {
+	*nodeBased[T]
+}
+
+func newFetchAdd[T any](capacity uint64, c *config) RingBuffer[T] {
+	return &fetchAdd[T]{nodeBased: newNodeBased[T](capacity, c).(*nodeBased[T])}
+}
+
+// Offer a value, return false if buffer is full. It never fails for contention, but blocks
+// if overshot until the slot polled.
+func (r *fetchAdd[T]) Offer(value T) (success bool) {
+	seq, ok := r.claim(1)
+	if !ok {
+		return false
+	}
+	r.publish(seq, value, r.enqueueTime())
+	return true
+}
+
+// claim takes n sequences by fetch-and-add if buffer has room for them by head and tail.
+func (r *fetchAdd[T]) claim(n uint64) (seq uint64, ok bool) {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	if occupancy(r.mask+1, head, tail)+n > r.mask+1 {
+		return 0, false
+	}
+	return atomic.AddUint64(&r.tail, n) - n, true
+}
+
+// publish waits the slot of seq polled if overshot, then publishes value to it.
+func (r *fetchAdd[T]) publish(seq uint64, value T, enqueued int64) {
+	tailNode := r.element[seq&r.mask]
+	// overshot, wait the value of previous lap polled
+	for tailNode.step.Load() != seq {
+		cpuRelax()
+		runtime.Gosched()
+	}
+
+	tailNode.value = value
+	tailNode.enqueued = enqueued
+	tailNode.step.Store(seq + 1)
+}
+
+func (r *fetchAdd[T]) enqueueTime() int64 {
+	if r.timed {
+		return monotonicNow()
+	}
+	return 0
+}
+
+// OfferIf offers value if cond is true, see ConditionalProducer. The check must be coupled
+// with the claim, so it claims by CAS, but retries rather than fails when lost in contention.
+func (r *fetchAdd[T]) OfferIf(value T, cond func(len, cap uint64) bool) (success bool) {
+	for {
+		oldTail := atomic.LoadUint64(&r.tail)
+		tailNode := r.element[oldTail&r.mask]
+		// not polled yet, buffer is full
+		if tailNode.step.Load() != oldTail {
+			return false
+		}
+		if !cond(occupancy(r.mask+1, atomic.LoadUint64(&r.head), oldTail), r.mask+1) {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&r.tail, oldTail, oldTail+1) {
+			r.publish(oldTail, value, r.enqueueTime())
+			return true
+		}
+	}
+}
+
+// OfferAllOrNothing offers all values or none of them, see BatchProducer. It claims the
+// contiguous sequences by one fetch-and-add, and blocks as Offer if overshot.
+func (r *fetchAdd[T]) OfferAllOrNothing(values []T) (success bool) {
+	n := uint64(len(values))
+	if n == 0 {
+		return true
+	}
+	seq, ok := r.claim(n)
+	if !ok {
+		return false
+	}
+
+	enqueued := r.enqueueTime()
+	for i, value := range values {
+		r.publish(seq+uint64(i), value, enqueued)
+	}
+	return true
+}
+
+// OfferReject offers value, see OverwritingProducer.
+func (r *fetchAdd[T]) OfferReject(value T) (success bool) {
+	return r.Offer(value)
+}
+
+// OfferOverwrite offers value, drops the oldest if full, see OverwritingProducer.
+func (r *fetchAdd[T]) OfferOverwrite(value T) (dropped T, overwritten bool) {
+	return offerOverwrite[T](r, value, func() bool {
+		return r.Len() == r.mask+1
+	})
+}
+
+// Requeue puts value back to tail, see Requeuer. Offer never fails for contention, so it's
+// the same as Offer.
+func (r *fetchAdd[T]) Requeue(value T) (success bool) {
+	return r.Offer(value)
+}
+
+// SingleProducerOffer offers values from valueSupplier until finish or buffer full. The room
+// is checked before every value supplied, so no value is lost when full.
+func (r *fetchAdd[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	for {
+		head := atomic.LoadUint64(&r.head)
+		tail := atomic.LoadUint64(&r.tail)
+		if occupancy(r.mask+1, head, tail) > r.mask {
+			return
+		}
+
+		v, finish := valueSupplier()
+		if finish {
+			return
+		}
+		r.publish(atomic.AddUint64(&r.tail, 1)-1, v, r.enqueueTime())
+	}
+}
+
+func (r *fetchAdd[T]) State() State {
+	state := r.nodeBased.State()
+	state.Type = FetchAdd
+	return state
+}
+
+func (r *fetchAdd[T]) String() string {
+	return r.State().String()
+}
@@ -0,0 +1,49 @@
+package lfring
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// FlushEvery drains consumer into flush every interval, for the metrics and batching that
+// only need "flush every ~100ms": values accumulate in the ring between flushes, and flush
+// gets whatever drained, in batches of at most WithBatchSize, skipped if nothing. The batch
+// is reused between calls, flush must not retain it.
+//
+// Every interval is shifted by a random duration in [-jitter, jitter], so the flushers
+// started together don't hit the sink at the same moment. It returns when ctx done
+// (ctx.Err()), or consumer closed (nil), after a last drain either way. The caller must be
+// the only consumer.
+func FlushEvery[T any](ctx context.Context, consumer Consumer[T], interval time.Duration, jitter time.Duration, flush func(batch []T), opts ...Option) error {
+	batch := make([]T, newConfig(opts).batchSize)
+	closer, _ := consumer.(closable)
+	timer := time.NewTimer(jittered(interval, jitter))
+	defer timer.Stop()
+	done := ctx.Done()
+	for {
+		select {
+		case <-done:
+			drainBatches(context.Background(), consumer, batch, flush)
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		drainBatches(ctx, consumer, batch, flush)
+		if closer != nil && closer.Closed() {
+			// values may be published right before closed
+			return drainBatches(ctx, consumer, batch, flush)
+		}
+		timer.Reset(jittered(interval, jitter))
+	}
+}
+
+// jittered returns interval shifted by a random duration in [-jitter, jitter], never
+// negative.
+func jittered(interval time.Duration, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	d := interval - jitter + rand.N(2*jitter+1)
+	return max(d, 0)
+}
@@ -0,0 +1,59 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestFlushEveryDrainsPeriodically(c *C) {
+	// given
+	buffer := NewBlocking(New[int](NodeBased, 16))
+	flushed := make(chan []int, 16)
+	for i := 0; i < 3; i++ {
+		buffer.Offer(i)
+	}
+
+	// when
+	done := make(chan error)
+	go func() {
+		done <- FlushEvery(context.Background(), buffer, time.Millisecond, time.Millisecond/2, func(batch []int) {
+			flushed <- append([]int(nil), batch...)
+		}, WithBatchSize(2))
+	}()
+	first, second := <-flushed, <-flushed
+	buffer.Offer(3)
+	third := <-flushed
+	buffer.Close()
+
+	// then
+	c.Assert(<-done, IsNil)
+	c.Assert([][]int{first, second, third}, DeepEquals, [][]int{{0, 1}, {2}, {3}})
+}
+
+func (s *MySuite) TestFlushEveryLastDrainOnCancel(c *C) {
+	// given
+	buffer := New[int](NodeBased, 16)
+	buffer.Offer(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var flushed []int
+
+	// when
+	err := FlushEvery(ctx, buffer, time.Hour, 0, func(batch []int) {
+		flushed = append(flushed, batch...)
+	})
+
+	// then
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(flushed, DeepEquals, []int{1})
+}
+
+func (s *MySuite) TestJittered(c *C) {
+	for i := 0; i < 100; i++ {
+		d := jittered(10*time.Millisecond, 3*time.Millisecond)
+		c.Assert(d >= 7*time.Millisecond && d <= 13*time.Millisecond, Equals, true)
+	}
+	c.Assert(jittered(time.Millisecond, 5*time.Millisecond) >= 0, Equals, true)
+	c.Assert(jittered(time.Second, 0), Equals, time.Second)
+}
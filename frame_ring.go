@@ -0,0 +1,153 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// FramePolicy decides what FrameRing drops when the producer outruns the consumer.
+type FramePolicy int
+
+const (
+	// FrameDropNewest rejects the pushed frame, the frames in ring are kept
+	FrameDropNewest FramePolicy = iota
+	// FrameDropOldest overwrites the oldest frame, so the consumer always gets the latest
+	// frames, which suits live media
+	FrameDropOldest
+)
+
+// FrameRing is a single-producer single-consumer ring of fixed-size frames (e.g. audio
+// blocks of frameSize samples) for real-time media: Push and Pull move whole frames, never
+// block, and count the overruns (frames dropped as the producer outruns the consumer) and
+// underruns (pulls find no frame) explicitly. A failed Pull fills silence (zeros) into dst,
+// so the callback of an audio device can always hand dst over.
+//
+// Frames are guarded by stamps and published by pointer like Multicast, so with
+// FrameDropOldest the producer never waits, a consumer that falls behind skips to the oldest
+// retained frame. The producer may overwrite a frame being pulled then, so every Push copies
+// into a new frame, which costs an allocation. With FrameDropNewest a frame is only pushed
+// again once pulled, so Push reuses it and doesn't allocate after the first lap.
+type FrameRing[T any] struct {
+	head      uint64
+	_padding0 [56]byte
+	tail      uint64
+	_padding1 [56]byte
+	overruns  uint64
+	underruns uint64
+	mask      uint64
+	frameSize int
+	policy    FramePolicy
+	slots     []frameSlot[T]
+}
+
+type frameSlot[T any] struct {
+	stamp atomic.Uint64
+	frame atomic.Pointer[[]T]
+}
+
+// NewFrameRing build a FrameRing of frames (expands to power-of-two as New does) frames of
+// frameSize samples.
+func NewFrameRing[T any](frames uint64, frameSize int, policy FramePolicy) *FrameRing[T] {
+	realFrames := findPowerOfTwo(frames)
+	return &FrameRing[T]{
+		mask:      realFrames - 1,
+		frameSize: frameSize,
+		policy:    policy,
+		slots:     make([]frameSlot[T], realFrames),
+	}
+}
+
+// Push copies frame into ring, returns false if the frame is dropped by FrameDropNewest. It
+// panics if the length of frame isn't frameSize.
+func (r *FrameRing[T]) Push(frame []T) bool {
+	if len(frame) != r.frameSize {
+		panic("lfring: frame size mismatch")
+	}
+
+	tail := r.tail
+	if r.policy == FrameDropNewest && tail-atomic.LoadUint64(&r.head) > r.mask {
+		atomic.AddUint64(&r.overruns, 1)
+		return false
+	}
+
+	slot := &r.slots[tail&r.mask]
+	var buf []T
+	if old := slot.frame.Load(); old != nil && r.policy == FrameDropNewest {
+		// pulled already, nobody reads it
+		buf = *old
+	} else {
+		buf = make([]T, r.frameSize)
+	}
+	copy(buf, frame)
+	slot.stamp.Store(publishedStamp(tail) - 1)
+	slot.frame.Store(&buf)
+	slot.stamp.Store(publishedStamp(tail))
+	atomic.StoreUint64(&r.tail, tail+1)
+	return true
+}
+
+// Pull copies the oldest frame to dst, returns false (and fills dst zeros) if ring is empty.
+// It panics if the length of dst isn't frameSize.
+func (r *FrameRing[T]) Pull(dst []T) bool {
+	if len(dst) != r.frameSize {
+		panic("lfring: frame size mismatch")
+	}
+
+	for {
+		head := r.head
+		tail := atomic.LoadUint64(&r.tail)
+		if head >= tail {
+			atomic.AddUint64(&r.underruns, 1)
+			clear(dst)
+			return false
+		}
+
+		// fall a lap behind, skip to the oldest retained one
+		if tail-head > r.mask+1 {
+			atomic.AddUint64(&r.overruns, tail-head-r.mask-1)
+			atomic.StoreUint64(&r.head, tail-r.mask-1)
+			continue
+		}
+
+		slot := &r.slots[head&r.mask]
+		expected := publishedStamp(head)
+		if slot.stamp.Load() != expected {
+			// being overwritten by a later lap
+			atomic.StoreUint64(&r.head, head+1)
+			atomic.AddUint64(&r.overruns, 1)
+			continue
+		}
+		frame := slot.frame.Load()
+		if slot.stamp.Load() != expected {
+			continue
+		}
+		copy(dst, *frame)
+
+		atomic.StoreUint64(&r.head, head+1)
+		return true
+	}
+}
+
+// Len returns the number of frames in ring.
+func (r *FrameRing[T]) Len() uint64 {
+	return occupancy(r.mask+1, atomic.LoadUint64(&r.head), atomic.LoadUint64(&r.tail))
+}
+
+// Cap returns the real (power-of-two) number of frames.
+func (r *FrameRing[T]) Cap() uint64 {
+	return r.mask + 1
+}
+
+// FrameSize returns the number of samples in a frame.
+func (r *FrameRing[T]) FrameSize() int {
+	return r.frameSize
+}
+
+// Overruns returns how many frames have been dropped by the policy.
+func (r *FrameRing[T]) Overruns() uint64 {
+	return atomic.LoadUint64(&r.overruns)
+}
+
+// Underruns returns how many pulls found no frame.
+func (r *FrameRing[T]) Underruns() uint64 {
+	return atomic.LoadUint64(&r.underruns)
+}
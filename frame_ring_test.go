@@ -0,0 +1,52 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestFrameRingDropNewest(c *C) {
+	// given
+	ring := NewFrameRing[int16](2, 3, FrameDropNewest)
+	dst := make([]int16, 3)
+
+	// when
+	pushed := []bool{ring.Push([]int16{1, 1, 1}), ring.Push([]int16{2, 2, 2}), ring.Push([]int16{3, 3, 3})}
+
+	// then
+	c.Assert(pushed, DeepEquals, []bool{true, true, false})
+	c.Assert(ring.Overruns(), Equals, uint64(1))
+	c.Assert(ring.Pull(dst), Equals, true)
+	c.Assert(dst, DeepEquals, []int16{1, 1, 1})
+	c.Assert(ring.Pull(dst), Equals, true)
+	c.Assert(dst, DeepEquals, []int16{2, 2, 2})
+	c.Assert(ring.Pull(dst), Equals, false)
+	c.Assert(dst, DeepEquals, []int16{0, 0, 0})
+	c.Assert(ring.Underruns(), Equals, uint64(1))
+}
+
+func (s *MySuite) TestFrameRingDropOldest(c *C) {
+	// given
+	ring := NewFrameRing[float32](2, 2, FrameDropOldest)
+	dst := make([]float32, 2)
+
+	// when
+	for i := 1; i <= 5; i++ {
+		c.Assert(ring.Push([]float32{float32(i), float32(i)}), Equals, true)
+	}
+
+	// then
+	c.Assert(ring.Pull(dst), Equals, true)
+	c.Assert(dst, DeepEquals, []float32{4, 4})
+	c.Assert(ring.Overruns(), Equals, uint64(3))
+	c.Assert(ring.Len(), Equals, uint64(1))
+	c.Assert(ring.Pull(dst), Equals, true)
+	c.Assert(dst, DeepEquals, []float32{5, 5})
+}
+
+func (s *MySuite) TestFrameRingSizeMismatch(c *C) {
+	ring := NewFrameRing[int](2, 4, FrameDropNewest)
+	c.Assert(func() { ring.Push([]int{1}) }, PanicMatches, "lfring: frame size mismatch")
+	c.Assert(func() { ring.Pull(make([]int, 5)) }, PanicMatches, "lfring: frame size mismatch")
+	c.Assert(ring.FrameSize(), Equals, 4)
+	c.Assert(ring.Cap(), Equals, uint64(2))
+}
@@ -1,7 +1,9 @@
 package lfring
 
 import (
+	"encoding/json"
 	. "gopkg.in/check.v1"
+	"sync/atomic"
 	"testing"
 )
 
@@ -35,7 +37,7 @@ func (s *MySuite) TestFindPowerOfTwo(c *C) {
 	c.Assert(res5, Equals, uint64(0))
 }
 
-var bufferSet = []BufferType{NodeBased, Classical}
+var bufferSet = []BufferType{NodeBased, Classical, FetchAdd}
 
 func (s *MySuite) TestOfferAndPollSuccess(c *C) {
 	for _, t := range bufferSet {
@@ -127,3 +129,302 @@ func (s *MySuite) TestRingBufferShift(c *C) {
 		c.Assert(polled2, Equals, 16)
 	}
 }
+
+func (s *MySuite) TestProducerAndConsumerView(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 4)
+		producer := AsProducer(buffer)
+		consumer := AsConsumer(buffer)
+
+		// when
+		offered := producer.Offer(1)
+		polled, success := consumer.Poll()
+
+		// then
+		c.Assert(offered, Equals, true)
+		c.Assert(success, Equals, true)
+		c.Assert(polled, Equals, 1)
+		_, isConsumer := producer.(Consumer[int])
+		_, isProducer := consumer.(Producer[int])
+		c.Assert(isConsumer, Equals, false)
+		c.Assert(isProducer, Equals, false)
+	}
+}
+
+func (s *MySuite) TestMustOfferAndMustPoll(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 2)
+
+		// when
+		MustOffer[int](buffer, 1)
+
+		// then
+		c.Assert(MustPoll[int](buffer), Equals, 1)
+		c.Assert(func() { MustPoll[int](buffer) }, PanicMatches, ".*buffer is empty")
+	}
+}
+
+func (s *MySuite) TestStateAndString(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 8)
+		buffer.Offer(1)
+		buffer.Offer(2)
+		buffer.Offer(3)
+		buffer.Poll()
+
+		// when
+		state := buffer.State()
+		marshaled, err := json.Marshal(state)
+
+		// then
+		c.Assert(err, IsNil)
+		c.Assert(state.Type, Equals, t)
+		c.Assert(state.Capacity, Equals, uint64(8))
+		c.Assert(state.Occupancy, Equals, uint64(2))
+		c.Assert(buffer.Len(), Equals, uint64(2))
+		c.Assert(buffer.Cap(), Equals, uint64(8))
+		c.Assert(string(marshaled), Matches, `\{"type":"`+t.String()+`","capacity":8,.*"occupancy":2,"policy":"reject"\}`)
+		c.Assert(buffer.String(), Matches, t.String()+`\(capacity=8, .*occupancy=2\)`)
+	}
+}
+
+func (s *MySuite) TestPollNBatchedWithMaxBatchScan(c *C) {
+	// given
+	buffer := New[int](NodeBased, 256, WithMaxBatchScan(128))
+	for i := 0; i < 200; i++ {
+		buffer.Offer(i)
+	}
+
+	// when
+	values, count := buffer.PollNBatched(150)
+
+	// then
+	c.Assert(buffer.(*nodeBased[int]).maxBatch, Equals, uint64(128))
+	c.Assert(count, Equals, uint64(150))
+	for i := 0; i < 150; i++ {
+		c.Assert(values[i], Equals, i)
+	}
+}
+
+func (s *MySuite) TestPollBatchInto(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 16)
+		for i := 0; i < 10; i++ {
+			buffer.Offer(i)
+		}
+		dst := make([]int, 4)
+
+		// when
+		first := buffer.PollBatchInto(dst)
+		firstValues := append([]int(nil), dst[:first]...)
+		rest, restCount := buffer.PollNBatched(16)
+
+		// then
+		c.Assert(first, Equals, uint64(4))
+		c.Assert(firstValues, DeepEquals, []int{0, 1, 2, 3})
+		c.Assert(restCount, Equals, uint64(6))
+		c.Assert(rest, DeepEquals, []int{4, 5, 6, 7, 8, 9})
+		c.Assert(buffer.PollBatchInto(dst), Equals, uint64(0))
+	}
+}
+
+func (s *MySuite) TestSingleProducerOfferUntilFull(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 4)
+		i := 0
+
+		// when
+		buffer.SingleProducerOffer(func() (v int, finish bool) {
+			i++
+			return i, false
+		})
+
+		// then
+		offered := buffer.Len()
+		c.Assert(offered > 0, Equals, true)
+		for j := 1; uint64(j) <= offered; j++ {
+			c.Assert(MustPoll[int](buffer), Equals, j)
+		}
+	}
+}
+
+func (s *MySuite) TestSizeConsistent(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 8)
+		MustOffer[int](buffer, 1)
+		MustOffer[int](buffer, 2)
+		MustOffer[int](buffer, 3)
+		MustPoll[int](buffer)
+
+		// when
+		size, ok := buffer.SizeConsistent()
+
+		// then
+		c.Assert(ok, Equals, true)
+		c.Assert(size, Equals, uint64(2))
+	}
+}
+
+func (s *MySuite) TestClassicalCachedIndexRefresh(c *C) {
+	// given
+	buffer := New[int](Classical, 4).(*classical[int])
+	for i := 0; buffer.Offer(i); i++ {
+	}
+
+	// when the cached head shows full, the real head is loaded once polled
+	v, polled := buffer.Poll()
+	offered := buffer.Offer(100)
+
+	// then
+	c.Assert(polled, Equals, true)
+	c.Assert(v, Equals, 0)
+	c.Assert(offered, Equals, true)
+	c.Assert(atomic.LoadUint64(&buffer.cachedHead), Equals, uint64(1))
+	var values []int
+	for v, ok := buffer.Poll(); ok; v, ok = buffer.Poll() {
+		values = append(values, v)
+	}
+	c.Assert(values, DeepEquals, []int{1, 2, 100})
+	c.Assert(atomic.LoadUint64(&buffer.cachedTail), Equals, buffer.tail)
+}
+
+func (s *MySuite) TestOfferIf(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 8)
+		producer := buffer.(ConditionalProducer[int])
+		underHalf := func(len, cap uint64) bool { return len*2 < cap }
+
+		// when
+		var admitted []bool
+		for i := 0; i < 5; i++ {
+			admitted = append(admitted, producer.OfferIf(i, underHalf))
+		}
+
+		// then
+		c.Assert(admitted, DeepEquals, []bool{true, true, true, true, false}, Commentf("%s", t))
+		c.Assert(buffer.Len(), Equals, uint64(4))
+		buffer.Poll()
+		c.Assert(producer.OfferIf(4, underHalf), Equals, true)
+	}
+}
+
+func (s *MySuite) TestOfferRejectAndOverwrite(c *C) {
+	for _, t := range bufferSet {
+		// given a full buffer
+		buffer := New[int](t, 4)
+		producer := buffer.(OverwritingProducer[int])
+		for i := 0; producer.OfferReject(i); i++ {
+		}
+		size := buffer.Len()
+
+		// when
+		rejected := producer.OfferReject(100)
+		dropped, overwritten := producer.OfferOverwrite(200)
+		_, notFull := New[int](t, 4).(OverwritingProducer[int]).OfferOverwrite(1)
+
+		// then
+		c.Assert(rejected, Equals, false)
+		c.Assert(overwritten, Equals, true)
+		c.Assert(dropped, Equals, 0, Commentf("%s", t))
+		c.Assert(notFull, Equals, false)
+		c.Assert(buffer.Len(), Equals, size)
+		var values []int
+		for v, ok := buffer.Poll(); ok; v, ok = buffer.Poll() {
+			values = append(values, v)
+		}
+		c.Assert(values[len(values)-1], Equals, 200)
+		c.Assert(values[0], Equals, 1)
+	}
+}
+
+func (s *MySuite) TestRequeue(c *C) {
+	for _, t := range []BufferType{NodeBased, FetchAdd} {
+		// given
+		buffer := New[int](t, 4)
+		requeuer := buffer.(Requeuer[int])
+		for i := 0; i < 3; i++ {
+			buffer.Offer(i)
+		}
+
+		// when
+		first, _ := buffer.Poll()
+		back := requeuer.Requeue(first)
+		buffer.Offer(3)
+		full := requeuer.Requeue(5)
+
+		// then
+		c.Assert(back, Equals, true)
+		c.Assert(full, Equals, false)
+		var values []int
+		for v := range PollSeq(buffer, 8) {
+			values = append(values, v)
+		}
+		c.Assert(values, DeepEquals, []int{1, 2, 0, 3}, Commentf("%s", t))
+	}
+}
+
+func (s *MySuite) TestOfferAllOrNothing(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 8)
+		producer := buffer.(BatchProducer[int])
+		room := buffer.Cap()
+		if t == Classical {
+			// one slot is always left empty
+			room--
+		}
+
+		// when
+		empty := producer.OfferAllOrNothing(nil)
+		first := producer.OfferAllOrNothing([]int{0, 1, 2})
+		tooMany := producer.OfferAllOrNothing(make([]int, room-2))
+		rest := make([]int, room-3)
+		for i := range rest {
+			rest[i] = i + 3
+		}
+		fit := producer.OfferAllOrNothing(rest)
+
+		// then
+		c.Assert(empty, Equals, true)
+		c.Assert(first, Equals, true)
+		c.Assert(tooMany, Equals, false, Commentf("%s", t))
+		c.Assert(fit, Equals, true, Commentf("%s", t))
+		values, count := buffer.PollNBatched(room)
+		c.Assert(count, Equals, room)
+		for i, v := range values {
+			c.Assert(v, Equals, i)
+		}
+	}
+}
+
+func (s *MySuite) TestFetchAddSingleProducerOfferUntilFull(c *C) {
+	// given
+	buffer := New[int](FetchAdd, 4)
+
+	// when
+	supplied := 0
+	buffer.SingleProducerOffer(func() (int, bool) {
+		supplied++
+		return supplied, false
+	})
+	buffer.Poll()
+	buffer.SingleProducerOffer(func() (int, bool) {
+		supplied++
+		return supplied, supplied > 6
+	})
+
+	// then no value supplied is lost
+	c.Assert(supplied, Equals, 5)
+	var values []int
+	for v := range PollSeq(buffer, 8) {
+		values = append(values, v)
+	}
+	c.Assert(values, DeepEquals, []int{2, 3, 4, 5})
+}
@@ -0,0 +1,99 @@
+package lfring
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+)
+
+const (
+	futurePending int32 = iota
+	futureCompleting
+	futureDone
+)
+
+// futureSpins is how many times Wait checks (and yields) before it parks.
+const futureSpins = 16
+
+// Future is the result of an asynchronous submission, completed once by the consumer that
+// handled it, e.g. Submit and Caller. Unlike a channel per call, a Future is a single small
+// allocation, and Wait spins a little before it parks, so a fast consumer completes it
+// without any channel involved.
+type Future[T any] struct {
+	state int32
+	value T
+	err   error
+	done  notifier
+}
+
+// NewFuture build a pending Future.
+func NewFuture[T any]() *Future[T] {
+	return &Future[T]{}
+}
+
+// Complete sets the result and wakes the waiters, return false if completed already.
+func (f *Future[T]) Complete(value T, err error) bool {
+	if !atomic.CompareAndSwapInt32(&f.state, futurePending, futureCompleting) {
+		return false
+	}
+
+	f.value, f.err = value, err
+	atomic.StoreInt32(&f.state, futureDone)
+	f.done.broadcast()
+	return true
+}
+
+// Done reports whether the Future is completed.
+func (f *Future[T]) Done() bool {
+	return atomic.LoadInt32(&f.state) == futureDone
+}
+
+// Result returns the result if completed, without waiting.
+func (f *Future[T]) Result() (value T, err error, done bool) {
+	if !f.Done() {
+		return
+	}
+	return f.value, f.err, true
+}
+
+// Wait waits until completed and returns the result, or ctx.Err() if ctx done before.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	for i := 0; i < futureSpins; i++ {
+		if f.Done() {
+			return f.value, f.err
+		}
+		cpuRelax()
+		runtime.Gosched()
+	}
+
+	for {
+		ready := f.done.wait()
+		if f.Done() {
+			return f.value, f.err
+		}
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Job is a value submitted along with the Future of its result, the consumer handles Value
+// and completes Future.
+type Job[T, R any] struct {
+	Value  T
+	Future *Future[R]
+}
+
+// Submit offers value to producer as a Job, blocks while producer is full, and returns the
+// Future the consumer completes. It returns ErrClosed if producer is closed, or ctx.Err() if
+// ctx done before sent.
+func Submit[T, R any](ctx context.Context, producer *Blocking[Job[T, R]], value T) (*Future[R], error) {
+	future := NewFuture[R]()
+	if err := producer.Send(ctx, Job[T, R]{Value: value, Future: future}); err != nil {
+		return nil, err
+	}
+	return future, nil
+}
@@ -0,0 +1,70 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestFutureComplete(c *C) {
+	// given
+	f := NewFuture[int]()
+	_, _, doneBefore := f.Result()
+	errFailed := errors.New("failed")
+
+	// when
+	go func() {
+		time.Sleep(time.Millisecond)
+		f.Complete(1, errFailed)
+	}()
+	value, err := f.Wait(context.Background())
+
+	// then
+	c.Assert(doneBefore, Equals, false)
+	c.Assert(value, Equals, 1)
+	c.Assert(err, Equals, errFailed)
+	c.Assert(f.Complete(2, nil), Equals, false)
+	value, err, done := f.Result()
+	c.Assert([]any{value, err, done}, DeepEquals, []any{1, errFailed, true})
+}
+
+func (s *MySuite) TestFutureWaitCanceled(c *C) {
+	// given
+	f := NewFuture[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := f.Wait(ctx)
+
+	// then
+	c.Assert(err, Equals, context.DeadlineExceeded)
+	c.Assert(f.Done(), Equals, false)
+}
+
+func (s *MySuite) TestSubmit(c *C) {
+	// given
+	jobs := NewBlocking(New[Job[int, int]](NodeBased, 4))
+	go func() {
+		for {
+			job, err := jobs.PollWait()
+			if err != nil {
+				return
+			}
+			job.Future.Complete(job.Value*2, nil)
+		}
+	}()
+	defer jobs.Close()
+
+	// when
+	f1, err1 := Submit(context.Background(), jobs, 1)
+	f2, err2 := Submit(context.Background(), jobs, 2)
+	v2, _ := f2.Wait(context.Background())
+	v1, _ := f1.Wait(context.Background())
+
+	// then
+	c.Assert(err1, IsNil)
+	c.Assert(err2, IsNil)
+	c.Assert([]int{v1, v2}, DeepEquals, []int{2, 4})
+}
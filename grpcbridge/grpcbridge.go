@@ -0,0 +1,132 @@
+// Package grpcbridge exposes a lfring.Blocking over the streams of gRPC, so a ring becomes a
+// network boundary: remote producers offer into the ring by a client stream, and remote
+// consumers take the values polled from the ring by a server stream, or both over one
+// bidirectional stream.
+//
+// The package doesn't depend on grpc, the stream types generated by protoc-gen-go-grpc satisfy
+// Sender and Receiver already, e.g. for
+//
+//	service Ring {
+//		rpc Exchange(stream Offer) returns (stream Item);
+//	}
+//
+// the server handler is one line:
+//
+//	func (s *server) Exchange(stream pb.Ring_ExchangeServer) error {
+//		return grpcbridge.Serve(stream, s.ring, decodeOffer, encodeItem)
+//	}
+//
+// The backpressure is mapped to the flow control of gRPC: a message is only received when the
+// ring has room for it, otherwise the flow control window fills up and the Send of the remote
+// producer blocks; and a value is only polled when the previous one sent, so a slow remote
+// consumer leaves the values in the ring for the local consumers, or backpressures the
+// producers once the ring is full.
+package grpcbridge
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	lfring "github.com/gsingh-ds/go-lock-free-ring-buffer"
+)
+
+// Sender is the sending half of a stream, e.g. a generated server stream, or a client stream.
+type Sender[M any] interface {
+	Send(M) error
+	Context() context.Context
+}
+
+// Receiver is the receiving half of a stream, e.g. a generated client stream on the server
+// side, or a server stream on the client side.
+type Receiver[M any] interface {
+	Recv() (M, error)
+	Context() context.Context
+}
+
+// SendFrom polls values from ring and sends them to stream encoded by encode, until the
+// stream context done (returns its error), ring closed and drained (returns nil), or Send /
+// encode fails (returns the error). The value being sent when Send fails is lost, the
+// delivery over a stream is at-most-once.
+func SendFrom[T, M any](stream Sender[M], ring *lfring.Blocking[T], encode func(T) (M, error)) error {
+	return sendFrom(stream.Context(), stream, ring, encode)
+}
+
+// sendFrom is SendFrom polling until ctx done, a value polled is still sent after.
+func sendFrom[T, M any](ctx context.Context, stream Sender[M], ring *lfring.Blocking[T], encode func(T) (M, error)) error {
+	for {
+		v, err := ring.Recv(ctx)
+		if errors.Is(err, lfring.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		m, err := encode(v)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(m); err != nil {
+			return err
+		}
+	}
+}
+
+// RecvInto receives messages from stream and offers them into ring decoded by decode, until
+// the remote side closes sending (returns nil), the stream context done (returns its error),
+// ring closed (returns lfring.ErrClosed), or Recv / decode fails (returns the error). The
+// next message is only received after the previous one offered.
+func RecvInto[T, M any](stream Receiver[M], ring *lfring.Blocking[T], decode func(M) (T, error)) error {
+	ctx := stream.Context()
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		v, err := decode(m)
+		if err != nil {
+			return err
+		}
+		if err := ring.Send(ctx, v); err != nil {
+			return err
+		}
+	}
+}
+
+// Stream is a bidirectional stream, e.g. a generated bidirectional server stream.
+type Stream[In, Out any] interface {
+	Send(Out) error
+	Recv() (In, error)
+	Context() context.Context
+}
+
+// Serve runs both RecvInto and SendFrom over a bidirectional stream, for a server handler.
+// It returns once SendFrom returns, or RecvInto fails, the remote side closing its sending
+// doesn't end the stream. SendFrom runs on the caller goroutine, when RecvInto fails it stops
+// polling, but the value polled already is still sent before Serve returns, so no Send
+// happens after the handler returned. RecvInto left running exits once the handler returns,
+// as gRPC cancels the stream context and fails the pending Recv then.
+func Serve[T, In, Out any](stream Stream[In, Out], ring *lfring.Blocking[T], decode func(In) (T, error), encode func(T) (Out, error)) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	recvErr := make(chan error, 1)
+	go func() {
+		if err := RecvInto[T, In](stream, ring, decode); err != nil {
+			recvErr <- err
+			cancel()
+		}
+	}()
+
+	err := sendFrom[T, Out](ctx, stream, ring, encode)
+	select {
+	case err = <-recvErr:
+	default:
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+package grpcbridge
+
+import (
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+	"io"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	lfring "github.com/gsingh-ds/go-lock-free-ring-buffer"
+)
+
+// hook up go-check to go testing
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// chanStream is an in-memory stream, the capacity of channels works as the flow control
+// window.
+type chanStream struct {
+	ctx context.Context
+	in  chan string
+	out chan string
+}
+
+func newChanStream(ctx context.Context, window int) *chanStream {
+	return &chanStream{ctx: ctx, in: make(chan string, window), out: make(chan string, window)}
+}
+
+func (s *chanStream) Send(m string) error {
+	select {
+	case s.out <- m:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func (s *chanStream) Recv() (string, error) {
+	select {
+	case m, ok := <-s.in:
+		if !ok {
+			return "", io.EOF
+		}
+		return m, nil
+	case <-s.ctx.Done():
+		return "", s.ctx.Err()
+	}
+}
+
+func (s *chanStream) Context() context.Context {
+	return s.ctx
+}
+
+func decode(m string) (int, error) {
+	return strconv.Atoi(m)
+}
+
+func encode(v int) (string, error) {
+	return strconv.Itoa(v), nil
+}
+
+func (s *MySuite) TestRecvIntoBackpressure(c *C) {
+	// given a ring of 2 and a window of 1
+	ring := lfring.NewBlocking(lfring.New[int](lfring.NodeBased, 2))
+	stream := newChanStream(context.Background(), 1)
+	done := make(chan error)
+	go func() {
+		done <- RecvInto[int, string](stream, ring, decode)
+	}()
+
+	// when the remote producer sends what the ring, the pending offer and the window hold
+	for i := 0; i < 4; i++ {
+		stream.in <- strconv.Itoa(i)
+	}
+	for ring.Len() < 2 || len(stream.in) < 1 {
+		runtime.Gosched()
+	}
+	blocked := false
+	select {
+	case stream.in <- "4":
+	default:
+		blocked = true
+	}
+
+	// then
+	c.Assert(blocked, Equals, true)
+	for i := 0; i < 4; i++ {
+		v, _ := ring.PollWait()
+		c.Assert(v, Equals, i)
+	}
+	close(stream.in)
+	c.Assert(<-done, IsNil)
+}
+
+func (s *MySuite) TestRecvIntoDecodeError(c *C) {
+	// given
+	ring := lfring.NewBlocking(lfring.New[int](lfring.NodeBased, 2))
+	stream := newChanStream(context.Background(), 1)
+	stream.in <- "x"
+
+	// when
+	err := RecvInto[int, string](stream, ring, decode)
+
+	// then
+	var numErr *strconv.NumError
+	c.Assert(errors.As(err, &numErr), Equals, true)
+}
+
+func (s *MySuite) TestSendFrom(c *C) {
+	// given
+	ring := lfring.NewBlocking(lfring.New[int](lfring.NodeBased, 4))
+	stream := newChanStream(context.Background(), 4)
+	for i := 0; i < 3; i++ {
+		ring.Offer(i)
+	}
+	ring.Close()
+
+	// when
+	err := SendFrom[int, string](stream, ring, encode)
+
+	// then
+	c.Assert(err, IsNil)
+	close(stream.out)
+	var sent []string
+	for m := range stream.out {
+		sent = append(sent, m)
+	}
+	c.Assert(sent, DeepEquals, []string{"0", "1", "2"})
+}
+
+func (s *MySuite) TestServe(c *C) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	ring := lfring.NewBlocking(lfring.New[int](lfring.NodeBased, 4))
+	stream := newChanStream(ctx, 4)
+	done := make(chan error)
+	go func() {
+		done <- Serve[int, string, string](stream, ring, decode, encode)
+	}()
+
+	// when the remote side offers and is sent back what polled
+	stream.in <- "7"
+	close(stream.in)
+	echoed := <-stream.out
+	cancel()
+
+	// then
+	c.Assert(echoed, Equals, "7")
+	c.Assert(<-done, Equals, context.Canceled)
+}
+
+func (s *MySuite) TestServeRecvFailureWaitsSend(c *C) {
+	// given the remote side doesn't receive yet
+	ring := lfring.NewBlocking(lfring.New[int](lfring.NodeBased, 4))
+	stream := newChanStream(context.Background(), 0)
+	done := make(chan error)
+	go func() {
+		done <- Serve[int, string, string](stream, ring, decode, encode)
+	}()
+
+	// when a value is being sent back while receiving fails
+	stream.in <- "7"
+	stream.in <- "x"
+
+	// then Serve waits the value sent
+	select {
+	case err := <-done:
+		c.Fatalf("returned before sent: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	c.Assert(<-stream.out, Equals, "7")
+	c.Assert(<-done, ErrorMatches, `.*invalid syntax`)
+}
@@ -0,0 +1,21 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// ownerGuard detects the violation of "single" contracts when debugAssertions is on, e.g.
+// two goroutines call SingleProducerOffer at the same time.
+type ownerGuard struct {
+	busy int32
+}
+
+func (g *ownerGuard) enter(method string) {
+	if !atomic.CompareAndSwapInt32(&g.busy, 0, 1) {
+		panic("lfring: concurrent " + method + " detected, it requires a single caller")
+	}
+}
+
+func (g *ownerGuard) exit() {
+	atomic.StoreInt32(&g.busy, 0)
+}
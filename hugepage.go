@@ -0,0 +1,16 @@
+package lfring
+
+// hugePageSize is the size of the transparent huge pages on amd64 and arm64 (by 4k pages),
+// an array is advised by the huge pages it fully covers.
+const hugePageSize = 2 << 20
+
+// hugePageRange returns the huge page aligned part of [addr, addr+size), zero length if it
+// covers no full huge page.
+func hugePageRange(addr, size uintptr) (start, length uintptr) {
+	start = (addr + hugePageSize - 1) &^ (hugePageSize - 1)
+	end := (addr + size) &^ (hugePageSize - 1)
+	if end <= start {
+		return start, 0
+	}
+	return start, end - start
+}
@@ -0,0 +1,18 @@
+package lfring
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// adviseHugePages advises the huge pages on the array at p of size bytes, before touched so
+// the kernel backs it by the huge pages right away. The error is returned for tests only,
+// the hint failed goes on with the normal pages.
+func adviseHugePages(p unsafe.Pointer, size uintptr) error {
+	start, length := hugePageRange(uintptr(p), size)
+	if length == 0 {
+		return nil
+	}
+	offset := start - uintptr(p)
+	return syscall.Madvise(unsafe.Slice((*byte)(unsafe.Add(p, offset)), length), syscall.MADV_HUGEPAGE)
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package lfring
+
+import (
+	"unsafe"
+)
+
+// adviseHugePages is a no-op but Linux.
+func adviseHugePages(unsafe.Pointer, uintptr) error {
+	return nil
+}
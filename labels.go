@@ -0,0 +1,35 @@
+package lfring
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// The pprof label keys and roles set on the goroutines of a named ring, see WithName.
+const (
+	LabelRing = "lfring"
+	LabelRole = "lfring_role"
+
+	RoleProducer = "producer"
+	RoleConsumer = "consumer"
+)
+
+// Labeled runs f with the pprof labels of ring name and role set on the calling goroutine
+// (and inherited by the goroutines it starts), so CPU profiles of a service with many rings
+// attribute time to the right queue, e.g. `go tool pprof -tagfocus lfring=orders`:
+//
+//	go lfring.Labeled(ctx, "orders", lfring.RoleProducer, func(ctx context.Context) {
+//		_ = lfring.RunProducer(ctx, ch, supplier)
+//	})
+func Labeled(ctx context.Context, name string, role string, f func(ctx context.Context)) {
+	pprof.Do(ctx, pprof.Labels(LabelRing, name, LabelRole, role), f)
+}
+
+// labeled is Labeled if the ring is named by WithName, otherwise runs f as is.
+func labeled(ctx context.Context, name string, role string, f func(ctx context.Context)) {
+	if name == "" {
+		f(ctx)
+		return
+	}
+	Labeled(ctx, name, role, f)
+}
@@ -0,0 +1,35 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"runtime/pprof"
+)
+
+func (s *MySuite) TestLabeled(c *C) {
+	// given
+	var ring, role string
+
+	// when
+	Labeled(context.Background(), "orders", RoleProducer, func(ctx context.Context) {
+		ring, _ = pprof.Label(ctx, LabelRing)
+		role, _ = pprof.Label(ctx, LabelRole)
+	})
+
+	// then
+	c.Assert(ring, Equals, "orders")
+	c.Assert(role, Equals, RoleProducer)
+}
+
+func (s *MySuite) TestUnnamedNotLabeled(c *C) {
+	// given
+	labeledAny := true
+
+	// when
+	labeled(context.Background(), "", RoleConsumer, func(ctx context.Context) {
+		_, labeledAny = pprof.Label(ctx, LabelRing)
+	})
+
+	// then
+	c.Assert(labeledAny, Equals, false)
+}
@@ -0,0 +1,64 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// Latest is a single-slot register for the cases only the newest value matters, e.g. the
+// configuration snapshots and sensor readings: Store overwrites the value rather than queues
+// it, so readers never see a backlog.
+//
+// Every Store publishes a copy of its own of the value, with its version, by an atomic
+// pointer, the copy is never written after, so readers just load the pointer and never block
+// the writer nor race it. It costs an allocation per Store, Load never allocates. Writers
+// are serialized by a CAS of the pointer. The zero value is ready to use, and must not be
+// copied once used.
+type Latest[T any] struct {
+	current atomic.Pointer[latestValue[T]]
+}
+
+type latestValue[T any] struct {
+	value   T
+	version uint64
+}
+
+// Store overwrites the value, it's safe for concurrent use.
+func (l *Latest[T]) Store(value T) {
+	next := &latestValue[T]{value: value}
+	for {
+		old := l.current.Load()
+		next.version = 1
+		if old != nil {
+			next.version = old.version + 1
+		}
+		if l.current.CompareAndSwap(old, next) {
+			return
+		}
+		cpuRelax()
+	}
+}
+
+// Load returns the newest value and its version, which starts from 1 and grows by 1 every
+// Store. The version is 0 (and value is the zero value) if nothing stored yet.
+func (l *Latest[T]) Load() (value T, version uint64) {
+	if p := l.current.Load(); p != nil {
+		return p.value, p.version
+	}
+	return value, 0
+}
+
+// LoadNewer returns the newest value if its version is newer than version, e.g. the one
+// returned by the last Load, so a poller reacts to changes only.
+func (l *Latest[T]) LoadNewer(version uint64) (value T, newVersion uint64, changed bool) {
+	if value, newVersion = l.Load(); newVersion > version {
+		return value, newVersion, true
+	}
+	var zero T
+	return zero, version, false
+}
+
+// Version returns the version of the newest value, see Load.
+func (l *Latest[T]) Version() uint64 {
+	_, version := l.Load()
+	return version
+}
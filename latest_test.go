@@ -0,0 +1,45 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type reading struct {
+	sensor string
+	value  float64
+}
+
+func (s *MySuite) TestLatestOverwrites(c *C) {
+	// given
+	var latest Latest[reading]
+
+	// when
+	empty, emptyVersion := latest.Load()
+	latest.Store(reading{"a", 1})
+	latest.Store(reading{"a", 2})
+	value, version := latest.Load()
+
+	// then
+	c.Assert(empty, Equals, reading{})
+	c.Assert(emptyVersion, Equals, uint64(0))
+	c.Assert(value, Equals, reading{"a", 2})
+	c.Assert(version, Equals, uint64(2))
+}
+
+func (s *MySuite) TestLatestLoadNewer(c *C) {
+	// given
+	var latest Latest[int]
+	latest.Store(1)
+	_, version := latest.Load()
+
+	// when
+	_, _, unchanged := latest.LoadNewer(version)
+	latest.Store(2)
+	value, newVersion, changed := latest.LoadNewer(version)
+
+	// then
+	c.Assert(unchanged, Equals, false)
+	c.Assert(changed, Equals, true)
+	c.Assert(value, Equals, 2)
+	c.Assert(newVersion, Equals, version+1)
+}
@@ -0,0 +1,43 @@
+package lfring
+
+// cacheLineSize is the cache line size the paddings assume, 64 bytes on the most platforms.
+const cacheLineSize = 64
+
+// Layouter is implemented by the buffers that report their memory layout, namely NodeBased,
+// FetchAdd, Classical and Relaxed:
+//
+//	if l, ok := buffer.(lfring.Layouter); ok {
+//		fmt.Printf("%+v, separated: %v\n", l.Layout(), l.Layout().Separated())
+//	}
+type Layouter interface {
+	Layout() Layout
+}
+
+// Layout reports the actual offsets of the hot fields in the buffer struct, and the size of
+// a slot, so the false sharing avoidance can be verified on the target platform.
+type Layout struct {
+	CacheLine uintptr `json:"cache_line"`
+	Head      uintptr `json:"head"`
+	Tail      uintptr `json:"tail"`
+	Mask      uintptr `json:"mask"`
+	Element   uintptr `json:"element"`
+	// SlotSize is the bytes a slot takes, padding included
+	SlotSize uintptr `json:"slot_size"`
+	// Padded tells whether slots are padded, see WithPadding
+	Padded bool `json:"padded"`
+}
+
+// Separated reports whether head, tail and the read-mostly fields (mask, element) are at
+// least a cache line apart from each other, so they never share a line wherever the struct
+// is allocated, while mask and element are close enough to be loaded together mostly.
+func (l Layout) Separated() bool {
+	return distance(l.Head, l.Tail) >= l.CacheLine && distance(l.Head, l.Mask) >= l.CacheLine &&
+		distance(l.Tail, l.Mask) >= l.CacheLine && distance(l.Mask, l.Element) < l.CacheLine
+}
+
+func distance(a, b uintptr) uintptr {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
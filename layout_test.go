@@ -0,0 +1,151 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"unsafe"
+)
+
+func (s *MySuite) TestLayoutSeparated(c *C) {
+	for _, t := range []BufferType{NodeBased, Classical, Relaxed, FetchAdd} {
+		// given
+		buffer := New[int](t, 8)
+
+		// when
+		layout := buffer.(Layouter).Layout()
+
+		// then
+		c.Assert(layout.Separated(), Equals, true, Commentf("%s: %+v", t, layout))
+	}
+}
+
+func (s *MySuite) TestLayoutWithoutPadding(c *C) {
+	// given
+	padded := New[int](NodeBased, 8).(*nodeBased[int])
+	compact := New[int](NodeBased, 8, WithPadding(false)).(*nodeBased[int])
+
+	// when
+	for i := 0; i < 16; i++ {
+		compact.Offer(i)
+	}
+	v, ok := compact.Poll()
+
+	// then
+	c.Assert(padded.Layout().Padded, Equals, true)
+	c.Assert(compact.Layout().Padded, Equals, false)
+	c.Assert(padded.Layout().SlotSize-compact.Layout().SlotSize, Equals, uintptr(40))
+	c.Assert(uintptr(unsafe.Pointer(compact.element[1]))-uintptr(unsafe.Pointer(compact.element[0])), Equals, compact.Layout().SlotSize)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 0)
+	c.Assert(compact.Len(), Equals, uint64(7))
+}
+
+func (s *MySuite) TestLayoutNotSeparated(c *C) {
+	// given
+	layout := Layout{CacheLine: 64, Head: 0, Tail: 8, Mask: 16, Element: 24}
+
+	// then
+	c.Assert(layout.Separated(), Equals, false)
+}
+
+func (s *MySuite) TestMemoryUsage(c *C) {
+	for _, t := range []BufferType{NodeBased, Classical, Relaxed, FetchAdd} {
+		// given
+		buffer := New[int64](t, 64, WithShards(4))
+		buffer.Offer(1)
+
+		// when
+		usage := buffer.(MemoryReporter).MemoryUsage()
+
+		// then
+		comment := Commentf("%s: %+v", t, usage)
+		// Classical keeps a pointer per slot
+		c.Assert(usage.Slots >= 64*uint64(unsafe.Sizeof(uintptr(0))), Equals, true, comment)
+		c.Assert(usage.Header >= 128, Equals, true, comment)
+		c.Assert(usage.Total() > usage.Slots, Equals, true, comment)
+	}
+}
+
+func (s *MySuite) TestMemoryUsageParts(c *C) {
+	// given
+	padded := New[int64](NodeBased, 8).(MemoryReporter)
+	compact := New[int64](NodeBased, 8, WithPadding(false)).(MemoryReporter)
+	relaxed := New[int64](Relaxed, 16, WithShards(4)).(MemoryReporter)
+	classical := New[int64](Classical, 8)
+	classical.Offer(1)
+	classical.Offer(2)
+	budgeted := NewBudgeted(New[[]byte](NodeBased, 8), 1024, nil)
+	budgeted.Offer(make([]byte, 100))
+
+	// then
+	header := uint64(unsafe.Sizeof(nodeBased[int64]{}))
+	index := 8 * uint64(unsafe.Sizeof(uintptr(0)))
+	c.Assert(padded.MemoryUsage(), Equals, MemoryUsage{Header: header, Slots: 8 * 64, Padding: 8 * 40, Index: index, Segments: 1})
+	c.Assert(compact.MemoryUsage(), Equals, MemoryUsage{Header: header, Slots: 8 * 24, Index: index, Segments: 1})
+	c.Assert(relaxed.MemoryUsage().Segments, Equals, 4)
+	c.Assert(relaxed.MemoryUsage().Slots, Equals, uint64(16*64))
+	c.Assert(classical.(MemoryReporter).MemoryUsage().Payload, Equals, uint64(16))
+	c.Assert(budgeted.MemoryUsage().Payload, Equals, uint64(100))
+}
+
+func (s *MySuite) TestWarmup(c *C) {
+	for _, t := range []BufferType{NodeBased, Classical, Relaxed, FetchAdd} {
+		// given
+		buffer := New[[512]byte](t, 16, WithWarmup(2), WithShards(2))
+
+		// when
+		state := buffer.State()
+		ok := buffer.Offer([512]byte{1})
+		v, polled := buffer.Poll()
+
+		// then
+		comment := Commentf("%s: %+v", t, state)
+		c.Assert(state.Head >= 30, Equals, true, comment)
+		c.Assert(state.Tail, Equals, state.Head, comment)
+		c.Assert(ok && polled, Equals, true, comment)
+		c.Assert(v[0], Equals, byte(1), comment)
+	}
+}
+
+func (s *MySuite) TestHugePageRange(c *C) {
+	// when
+	start, length := hugePageRange(hugePageSize+1, 3*hugePageSize)
+	_, short := hugePageRange(hugePageSize+1, hugePageSize)
+
+	// then
+	c.Assert(start, Equals, uintptr(2*hugePageSize))
+	c.Assert(length, Equals, uintptr(2*hugePageSize))
+	c.Assert(short, Equals, uintptr(0))
+}
+
+func (s *MySuite) TestHugePages(c *C) {
+	for _, t := range []BufferType{NodeBased, Classical, Relaxed, FetchAdd} {
+		for _, padding := range []bool{true, false} {
+			// given a ring spans huge pages
+			buffer := New[int](t, 1<<17, WithHugePages(), WithPadding(padding), WithShards(2))
+
+			// when
+			for i := 0; i < 1<<17; i++ {
+				buffer.Offer(i)
+			}
+			v, ok := buffer.Poll()
+
+			// then
+			comment := Commentf("%s, padding: %v", t, padding)
+			c.Assert(ok, Equals, true, comment)
+			c.Assert(v >= 0, Equals, true, comment)
+			c.Assert(buffer.Len() > 1<<16, Equals, true, comment)
+		}
+	}
+}
+
+func (s *MySuite) TestHugePagesAlignSteps(c *C) {
+	for _, padding := range []bool{true, false} {
+		// given the slots of an odd size, packed in one allocation
+		buffer := New[[3]byte](NodeBased, 64, WithHugePages(), WithPadding(padding)).(*nodeBased[[3]byte])
+
+		// then every step is 8-byte aligned for the 64-bit atomics on 32-bit platforms
+		for i, n := range buffer.element {
+			c.Assert(uintptr(unsafe.Pointer(&n.step))%8, Equals, uintptr(0), Commentf("padding: %v, slot %d", padding, i))
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package lfring
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logger logs the rare events (e.g. close, drops, watermarks and stalls) to the slog.Logger
+// set by WithLogger, never the hot path. A nil logger logs nothing.
+type logger struct {
+	*slog.Logger
+}
+
+func newLogger(c *config) logger {
+	if c.logger == nil || c.name == "" {
+		return logger{c.logger}
+	}
+	return logger{c.logger.With(slog.String("ring", c.name))}
+}
+
+func (l logger) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if l.Logger == nil {
+		return
+	}
+	l.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+func stateAttr(state State) slog.Attr {
+	return slog.Group("state",
+		slog.String("type", state.Type.String()),
+		slog.Uint64("capacity", state.Capacity),
+		slog.Uint64("head", state.Head),
+		slog.Uint64("tail", state.Tail),
+		slog.Uint64("occupancy", state.Occupancy),
+	)
+}
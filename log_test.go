@@ -0,0 +1,66 @@
+package lfring
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+func (s *MySuite) TestLoggerLogsRareEvents(c *C) {
+	// given
+	var out bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	buffer := NewBlocking[int](New[int](NodeBased, 2), WithLogger(l), WithName("orders"), WithSpinLimit(0))
+	buffer.Put(1)
+	buffer.Put(2)
+
+	// when
+	offered := buffer.OfferUntil(3, time.Now().Add(time.Millisecond))
+	_ = buffer.Close()
+	err := Consume[int](context.Background(), buffer, func(int) (Decision, error) {
+		return DeadLetter, errors.New("failure")
+	}, WithLogger(l))
+
+	// then
+	c.Assert(offered, Equals, false)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	c.Assert(len(lines), Equals, 4)
+	c.Assert(strings.Contains(lines[0], `msg="lfring: value dropped at deadline" ring=orders`), Equals, true)
+	c.Assert(strings.Contains(lines[0], "state.occupancy=2"), Equals, true)
+	c.Assert(strings.Contains(lines[1], `msg="lfring: closed" ring=orders`), Equals, true)
+	c.Assert(strings.Contains(lines[2], `msg="lfring: value dropped" decision=DeadLetter error=failure`), Equals, true)
+}
+
+func (s *MySuite) TestNilLoggerLogsNothing(c *C) {
+	newLogger(newConfig(nil)).log(slog.LevelError, "nothing")
+}
+
+func (s *MySuite) TestLoggerLogsWatermarks(c *C) {
+	// given
+	var out bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&out, nil))
+	buffer := NewBlocking[int](New[int](NodeBased, 8), WithLogger(l), WithWatermarks(6, 2))
+
+	// when it hovers around high, then drains to low
+	for i := 0; i < 6; i++ {
+		buffer.Offer(i)
+	}
+	buffer.Poll()
+	buffer.Offer(6)
+	buffer.Poll()
+	buffer.Poll()
+	buffer.PollBatchInto(make([]int, 2))
+
+	// then every crossing is logged once
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	c.Assert(len(lines), Equals, 2)
+	c.Assert(strings.Contains(lines[0], `level=WARN msg="lfring: high watermark reached" high=6`), Equals, true)
+	c.Assert(strings.Contains(lines[0], "state.occupancy=6"), Equals, true)
+	c.Assert(strings.Contains(lines[1], `level=INFO msg="lfring: back to low watermark" low=2`), Equals, true)
+	c.Assert(strings.Contains(lines[1], "state.occupancy=2"), Equals, true)
+}
@@ -0,0 +1,167 @@
+package lfring
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what Mailbox.Send does when the mailbox is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until there's room, or ctx done
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the value being sent, counts it as Dropped
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest value not processed yet to make room, counts it as
+	// Dropped
+	OverflowDropOldest
+	// OverflowReject returns ErrFull
+	OverflowReject
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "Block"
+	case OverflowDropNewest:
+		return "DropNewest"
+	case OverflowDropOldest:
+		return "DropOldest"
+	case OverflowReject:
+		return "Reject"
+	default:
+		return "OverflowPolicy(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// Mailbox is the mailbox of an actor: many senders, one owner goroutine processes the
+// messages by handler, one at a time in the order received, so the state owned by handler
+// needs no lock. It's a Blocking NodeBased ring, the owner parks while it's empty.
+//
+// Stop is graceful: senders get ErrClosed from then on, while the owner processes the
+// messages already in the mailbox before it exits.
+type Mailbox[T any] struct {
+	ring    *Blocking[T]
+	policy  OverflowPolicy
+	handler func(T)
+	dropped atomic.Uint64
+
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+// NewMailbox build a Mailbox of capacity (expands to power-of-two) messages that handles
+// them by handler, see Start and Run for the owner goroutine. The options are passed to the
+// ring and Blocking.
+func NewMailbox[T any](capacity uint64, policy OverflowPolicy, handler func(T), opts ...Option) *Mailbox[T] {
+	return &Mailbox[T]{
+		ring:    NewBlocking(New[T](NodeBased, capacity, opts...), opts...),
+		policy:  policy,
+		handler: handler,
+	}
+}
+
+// Send puts message to the mailbox, the overflow policy decides what happens if it's full.
+// It returns ErrClosed once stopped, ErrFull if rejected by OverflowReject, or ctx.Err() if
+// ctx done while OverflowBlock waits. A dropped message is not an error.
+func (m *Mailbox[T]) Send(ctx context.Context, message T) error {
+	if m.ring.Closed() {
+		return ErrClosed
+	}
+
+	switch m.policy {
+	case OverflowDropNewest:
+		if !m.offer(message) {
+			m.dropped.Add(1)
+		}
+		return nil
+	case OverflowDropOldest:
+		for !m.offer(message) {
+			if _, ok := m.ring.Poll(); ok {
+				m.dropped.Add(1)
+			}
+			cpuRelax()
+		}
+		return nil
+	case OverflowReject:
+		if !m.offer(message) {
+			return ErrFull
+		}
+		return nil
+	default:
+		return m.ring.Send(ctx, message)
+	}
+}
+
+// offer retries the offers lost in contention with the other senders, so the overflow policy
+// applies only when the mailbox is really full.
+func (m *Mailbox[T]) offer(message T) bool {
+	for !m.ring.Offer(message) {
+		if m.ring.Len() >= m.ring.Cap() {
+			return false
+		}
+		cpuRelax()
+	}
+	return true
+}
+
+// Start runs the owner goroutine, it returns ErrAlreadyRunning if started already.
+func (m *Mailbox[T]) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done != nil {
+		return ErrAlreadyRunning
+	}
+
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		m.Run(context.Background())
+	}()
+	return nil
+}
+
+// Run processes the messages in the calling goroutine, which becomes the owner, until ctx
+// done (returns ctx.Err()) or stopped and drained (returns nil). Use it instead of Start, not
+// both.
+func (m *Mailbox[T]) Run(ctx context.Context) error {
+	for {
+		message, err := m.ring.Recv(ctx)
+		if err == ErrClosed {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		m.handler(message)
+	}
+}
+
+// Stop closes the mailbox, and waits until the owner started by Start processed the rest
+// messages. It returns ErrClosed if stopped already.
+func (m *Mailbox[T]) Stop() error {
+	if err := m.ring.Close(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	done := m.done
+	m.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	return nil
+}
+
+// Len returns the approximate number of messages not processed yet.
+func (m *Mailbox[T]) Len() uint64 {
+	return m.ring.Len()
+}
+
+// Dropped returns how many messages are dropped by the overflow policy.
+func (m *Mailbox[T]) Dropped() uint64 {
+	return m.dropped.Load()
+}
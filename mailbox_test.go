@@ -0,0 +1,138 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"runtime"
+	"sync"
+)
+
+func (s *MySuite) TestMailboxProcessesInOrder(c *C) {
+	// given
+	var got []int
+	m := NewMailbox(4, OverflowBlock, func(v int) { got = append(got, v) })
+	c.Assert(m.Start(), IsNil)
+	c.Assert(m.Start(), Equals, ErrAlreadyRunning)
+
+	// when
+	for i := 0; i < 100; i++ {
+		c.Assert(m.Send(context.Background(), i), IsNil)
+	}
+	stopped := m.Stop()
+
+	// then
+	c.Assert(stopped, IsNil)
+	c.Assert(len(got), Equals, 100)
+	for i, v := range got {
+		c.Assert(v, Equals, i)
+	}
+	c.Assert(m.Send(context.Background(), 100), Equals, ErrClosed)
+	c.Assert(m.Stop(), Equals, ErrClosed)
+}
+
+func (s *MySuite) TestMailboxOverflowPolicies(c *C) {
+	for _, t := range []struct {
+		policy  OverflowPolicy
+		err     error
+		dropped uint64
+		kept    []int
+	}{
+		{OverflowDropNewest, nil, 1, []int{0, 1}},
+		{OverflowDropOldest, nil, 1, []int{1, 2}},
+		{OverflowReject, ErrFull, 0, []int{0, 1}},
+	} {
+		// given a mailbox not running
+		var got []int
+		m := NewMailbox(2, t.policy, func(v int) { got = append(got, v) })
+		m.Send(context.Background(), 0)
+		m.Send(context.Background(), 1)
+
+		// when
+		err := m.Send(context.Background(), 2)
+		m.Stop()
+		m.Run(context.Background())
+
+		// then
+		c.Assert(err, Equals, t.err, Commentf("%s", t.policy))
+		c.Assert(m.Dropped(), Equals, t.dropped, Commentf("%s", t.policy))
+		c.Assert(got, DeepEquals, t.kept, Commentf("%s", t.policy))
+	}
+}
+
+func (s *MySuite) TestMailboxBlockCanceled(c *C) {
+	// given
+	m := NewMailbox(2, OverflowBlock, func(int) {})
+	m.Send(context.Background(), 0)
+	m.Send(context.Background(), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	err := m.Send(ctx, 2)
+
+	// then
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(m.Len(), Equals, uint64(2))
+}
+
+func (s *MySuite) TestMailboxConcurrentSenders(c *C) {
+	// given
+	const senders, perSender = 4, 500
+	last := make([]int, senders)
+	ordered := true
+	m := NewMailbox(8, OverflowBlock, func(v [2]int) {
+		if v[1] != last[v[0]] {
+			ordered = false
+		}
+		last[v[0]]++
+	})
+	m.Start()
+	var wg sync.WaitGroup
+
+	// when
+	wg.Add(senders)
+	for s := 0; s < senders; s++ {
+		go func(s int) {
+			defer wg.Done()
+			for i := 0; i < perSender; i++ {
+				m.Send(context.Background(), [2]int{s, i})
+			}
+		}(s)
+	}
+	wg.Wait()
+	m.Stop()
+
+	// then the order of every sender is kept
+	c.Assert(ordered, Equals, true)
+	c.Assert(last, DeepEquals, []int{perSender, perSender, perSender, perSender})
+}
+
+func (s *MySuite) TestMailboxNoOverflowByContention(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest, OverflowReject} {
+		// given a mailbox with room for all the messages, not started
+		const senders, perSender = 4, 256
+		m := NewMailbox(senders*perSender, policy, func(int) {})
+		var wg sync.WaitGroup
+		errs := make(chan error, senders*perSender)
+
+		// when senders race on the tail
+		wg.Add(senders)
+		for s := 0; s < senders; s++ {
+			go func() {
+				defer wg.Done()
+				for i := 0; i < perSender; i++ {
+					if err := m.Send(context.Background(), i); err != nil {
+						errs <- err
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		// then
+		c.Assert(len(errs), Equals, 0, Commentf("%s", policy))
+		c.Assert(m.Dropped(), Equals, uint64(0), Commentf("%s", policy))
+		c.Assert(m.Len(), Equals, uint64(senders*perSender), Commentf("%s", policy))
+	}
+}
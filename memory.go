@@ -0,0 +1,46 @@
+package lfring
+
+// MemoryReporter is implemented by the buffers that report their memory footprint, namely
+// NodeBased, FetchAdd, Classical, Relaxed and Budgeted, so a capacity plan doesn't need to
+// compute the struct sizes from the source:
+//
+//	if m, ok := buffer.(lfring.MemoryReporter); ok {
+//		fmt.Printf("%+v, total: %d\n", m.MemoryUsage(), m.MemoryUsage().Total())
+//	}
+type MemoryReporter interface {
+	MemoryUsage() MemoryUsage
+}
+
+// MemoryUsage is an estimate of the bytes a buffer holds, by the struct sizes, it doesn't
+// count the internal fragmentation of the allocator.
+type MemoryUsage struct {
+	// Header is the bytes of the buffer structs, head, tail and their paddings
+	Header uint64 `json:"header"`
+	// Slots is the bytes of the slot arrays, Padding included
+	Slots uint64 `json:"slots"`
+	// Padding is the bytes of Slots spent against false sharing, see WithPadding
+	Padding uint64 `json:"padding"`
+	// Index is the bytes of the pointers to the slots
+	Index uint64 `json:"index"`
+	// Payload is the bytes retained out of the slots by the values in buffer: the values
+	// boxed by Classical, and the sizes accounted by Budgeted
+	Payload uint64 `json:"payload"`
+	// Segments is the number of slot arrays, e.g. the shards of Relaxed
+	Segments int `json:"segments"`
+}
+
+// Total returns the bytes of all the parts.
+func (u MemoryUsage) Total() uint64 {
+	return u.Header + u.Slots + u.Index + u.Payload
+}
+
+func (u MemoryUsage) add(other MemoryUsage) MemoryUsage {
+	return MemoryUsage{
+		Header:   u.Header + other.Header,
+		Slots:    u.Slots + other.Slots,
+		Padding:  u.Padding + other.Padding,
+		Index:    u.Index + other.Index,
+		Payload:  u.Payload + other.Payload,
+		Segments: u.Segments + other.Segments,
+	}
+}
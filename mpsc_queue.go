@@ -0,0 +1,108 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// MPSCNode is the link embedded in the values of MPSCQueue, e.g.
+//
+//	type command struct {
+//		lfring.MPSCNode[command]
+//		op int
+//	}
+//
+//	queue := lfring.NewMPSCQueue[command]()
+//	queue.Push(&command{op: 1})
+//
+// A value can sit in only one queue at a time, and must not be pushed again before it's
+// popped.
+type MPSCNode[T any] struct {
+	next atomic.Pointer[T]
+}
+
+func (n *MPSCNode[T]) mpscNode() *MPSCNode[T] {
+	return n
+}
+
+// MPSCQueue is the unbounded intrusive multi-producer single-consumer queue of Dmitry Vyukov:
+// http://www.1024cores.net/home/lock-free-algorithms/queues/intrusive-mpsc-node-based-queue
+//
+// The link lives in the values (see MPSCNode), so Push allocates nothing, which makes it a
+// command queue companion to the bounded ring buffers. Push is a single atomic swap of head
+// and then links the previous head to the value, hence it never fails nor waits. The single
+// consumer pops from tail, a stub value keeps the list never empty.
+//
+// Between the swap and the link of a Push the value is not reachable from tail yet, Pop
+// returns false meanwhile though the queue isn't empty, the consumer just tries again.
+type MPSCQueue[T any, PT interface {
+	*T
+	mpscNode() *MPSCNode[T]
+}] struct {
+	head      atomic.Pointer[T]
+	_padding0 [56]byte
+	tail      *T
+	stub      T
+
+	consumerGuard ownerGuard
+}
+
+// NewMPSCQueue build an empty MPSCQueue.
+func NewMPSCQueue[T any, PT interface {
+	*T
+	mpscNode() *MPSCNode[T]
+}]() *MPSCQueue[T, PT] {
+	q := &MPSCQueue[T, PT]{}
+	q.head.Store(&q.stub)
+	q.tail = &q.stub
+	return q
+}
+
+// Push appends value, it's safe for concurrent use.
+func (q *MPSCQueue[T, PT]) Push(value *T) {
+	PT(value).mpscNode().next.Store(nil)
+	prev := q.head.Swap(value)
+	PT(prev).mpscNode().next.Store(value)
+}
+
+// Pop removes the value at tail, return false if queue is empty or the Push of tail value is
+// in progress. The caller must be the only consumer, build with tag lfring_debug to detect
+// the violation.
+func (q *MPSCQueue[T, PT]) Pop() (value *T, success bool) {
+	if debugAssertions {
+		q.consumerGuard.enter("MPSCQueue.Pop")
+		defer q.consumerGuard.exit()
+	}
+
+	tail := q.tail
+	next := PT(tail).mpscNode().next.Load()
+	if tail == &q.stub {
+		if next == nil {
+			return nil, false
+		}
+		q.tail = next
+		tail = next
+		next = PT(tail).mpscNode().next.Load()
+	}
+	if next != nil {
+		q.tail = next
+		return tail, true
+	}
+
+	// tail is the last one linked, but a Push may have swapped head already
+	if tail != q.head.Load() {
+		return nil, false
+	}
+
+	// put the stub back behind tail, so tail can be popped
+	q.Push(&q.stub)
+	if next = PT(tail).mpscNode().next.Load(); next != nil {
+		q.tail = next
+		return tail, true
+	}
+	return nil, false
+}
+
+// Empty reports whether queue is empty, it's a hint as Len of the ring buffers.
+func (q *MPSCQueue[T, PT]) Empty() bool {
+	return q.head.Load() == &q.stub
+}
@@ -0,0 +1,69 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+	"sync"
+)
+
+type mpscCommand struct {
+	MPSCNode[mpscCommand]
+	producer int
+	seq      int
+}
+
+func (s *MySuite) TestMPSCQueueFIFO(c *C) {
+	// given
+	q := NewMPSCQueue[mpscCommand]()
+	first, second := &mpscCommand{seq: 1}, &mpscCommand{seq: 2}
+
+	// when
+	_, emptyPop := q.Pop()
+	q.Push(first)
+	q.Push(second)
+	a, okA := q.Pop()
+	q.Push(first)
+	b, okB := q.Pop()
+	d, okD := q.Pop()
+	_, okEmpty := q.Pop()
+
+	// then
+	c.Assert(emptyPop, Equals, false)
+	c.Assert(okA && okB && okD, Equals, true)
+	c.Assert([]*mpscCommand{a, b, d}, DeepEquals, []*mpscCommand{first, second, first})
+	c.Assert(okEmpty, Equals, false)
+	c.Assert(q.Empty(), Equals, true)
+}
+
+func (s *MySuite) TestMPSCQueueConcurrentPush(c *C) {
+	// given
+	const producers, perProducer = 4, 1000
+	q := NewMPSCQueue[mpscCommand]()
+	var wg sync.WaitGroup
+
+	// when
+	wg.Add(producers)
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				q.Push(&mpscCommand{producer: p, seq: i})
+			}
+		}(p)
+	}
+
+	next := make([]int, producers)
+	for popped := 0; popped < producers*perProducer; {
+		cmd, ok := q.Pop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		// then keeps the order of every producer
+		c.Assert(cmd.seq, Equals, next[cmd.producer])
+		next[cmd.producer]++
+		popped++
+	}
+	wg.Wait()
+	c.Assert(q.Empty(), Equals, true)
+}
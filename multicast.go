@@ -0,0 +1,421 @@
+package lfring
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Multicast is a multi-producer ring buffer in retention mode: published values stay in
+// their slots after being polled, until producers lap the ring and overwrite them. Hence
+// besides the shared head consumed by Poll, any number of read-only Cursor can tail the
+// stream independently without stealing values from the real consumers. It's impossible
+// on the normal ring buffers, whose slots are released to producers once polled.
+//
+// Producers never get blocked by default: a full ring drops its oldest values, consumers
+// (both Poll and Cursor) that fall a lap behind skip to the oldest retained value. A cursor
+// built by Subscribe can choose another LagPolicy instead.
+//
+// Every slot is guarded by a stamp that works like a seqlock: it's 2*seq+1 while the value
+// of seq being written, and 2*seq+2 once published. The value is published by a pointer to a
+// copy of its own, which is never written after, so readers load the pointer between two
+// loads of the stamp, the pointer is only valid if both loads are the published stamp of the
+// sequence they expect. It costs an allocation per Offer, but a reader never copies a value
+// being written.
+type Multicast[T any] struct {
+	head      uint64
+	_padding0 [56]byte
+	tail      uint64
+	_padding1 [56]byte
+	mask      uint64
+	base      uint64
+	slots     []multicastSlot[T]
+
+	mu    sync.Mutex
+	gates atomic.Pointer[[]*Cursor[T]]
+}
+
+type multicastSlot[T any] struct {
+	stamp    atomic.Uint64
+	value    atomic.Pointer[T]
+	_padding [48]byte
+}
+
+// readStatus tells the result of reading a sequence from slots.
+type readStatus int
+
+const (
+	readSuccess readStatus = iota
+	// readNotYet means the sequence is not published yet
+	readNotYet
+	// readOverwritten means the sequence has been overwritten by a later lap
+	readOverwritten
+)
+
+// NewMulticast build a Multicast, capacity expands to power-of-two as New does.
+func NewMulticast[T any](capacity uint64) *Multicast[T] {
+	return NewMulticastFrom[T](capacity, 0)
+}
+
+// NewMulticastFrom build a Multicast whose sequences start from start rather than 0, so a
+// restarted process can go on numbering from the offsets it checkpointed (see
+// Cursor.Checkpoint), e.g. by the sequence of the source it replays from.
+func NewMulticastFrom[T any](capacity uint64, start uint64) *Multicast[T] {
+	realCapacity := findPowerOfTwo(capacity)
+	return &Multicast[T]{
+		head:  start,
+		tail:  start,
+		mask:  realCapacity - 1,
+		base:  start,
+		slots: make([]multicastSlot[T], realCapacity),
+	}
+}
+
+// Offer publishes value and returns its sequence, the oldest value is overwritten if ring
+// is full. Sequences start from 0 (or the start of NewMulticastFrom) and assign by the order
+// producers claim slots.
+func (m *Multicast[T]) Offer(value T) (seq uint64) {
+	seq = atomic.AddUint64(&m.tail, 1) - 1
+	slot := &m.slots[seq&m.mask]
+
+	// wait the writer of previous lap (if any) done, it's rare that a producer falls a full
+	// lap behind others
+	var prevStamp uint64
+	if seq-m.base > m.mask {
+		prevStamp = publishedStamp(seq - m.mask - 1)
+	}
+	for slot.stamp.Load() != prevStamp {
+		cpuRelax()
+	}
+	if gates := m.gates.Load(); gates != nil {
+		m.waitGates(*gates, seq)
+	}
+
+	slot.stamp.Store(publishedStamp(seq) - 1)
+	slot.value.Store(&value)
+	slot.stamp.Store(publishedStamp(seq))
+	return seq
+}
+
+// Poll the value at shared head, return false if ring is empty, the head value is not
+// published yet, or the claim lost in contention.
+func (m *Multicast[T]) Poll() (value T, success bool) {
+	value, _, success = m.pollAt(&m.head)
+	return
+}
+
+// pollAt polls the value at *head shared by consumers, along with its sequence.
+func (m *Multicast[T]) pollAt(head *uint64) (value T, seq uint64, success bool) {
+	for {
+		oldHead := atomic.LoadUint64(head)
+		oldTail := atomic.LoadUint64(&m.tail)
+		if oldHead >= oldTail {
+			return
+		}
+
+		// fall a lap behind, skip to the oldest retained one
+		if oldTail-oldHead > m.mask+1 {
+			atomic.CompareAndSwapUint64(head, oldHead, oldTail-m.mask-1)
+			continue
+		}
+
+		v, status := m.read(oldHead)
+		switch status {
+		case readNotYet:
+			return
+		case readOverwritten:
+			atomic.CompareAndSwapUint64(head, oldHead, oldHead+1)
+			continue
+		}
+
+		if !atomic.CompareAndSwapUint64(head, oldHead, oldHead+1) {
+			return
+		}
+		return v, oldHead, true
+	}
+}
+
+// LagPolicy decides what happens to a Cursor that falls a full ring behind the producers.
+type LagPolicy int
+
+const (
+	// LagSkip advances the cursor to the oldest retained value (lossy), counts the gap as
+	// Skipped. It's the policy of Cursor, CursorFrom and CursorFromEarliest.
+	LagSkip LagPolicy = iota
+	// LagDetach detaches the cursor, Next fails from then on, see Detached.
+	LagDetach
+	// LagBackpressure never lets the cursor fall behind: producers wait before overwriting a
+	// value the cursor hasn't observed, so a stalled cursor stalls producers until closed.
+	LagBackpressure
+)
+
+// Subscribe returns a read-only cursor that starts from the next value to be published, and
+// handles falling behind by policy. Close the cursor once done, a LagBackpressure cursor
+// keeps gating producers until closed.
+func (m *Multicast[T]) Subscribe(policy LagPolicy) *Cursor[T] {
+	c := &Cursor[T]{m: m, next: atomic.LoadUint64(&m.tail), policy: policy}
+	if policy != LagBackpressure {
+		return c
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var gates []*Cursor[T]
+	if old := m.gates.Load(); old != nil {
+		gates = append(gates, *old...)
+	}
+	gates = append(gates, c)
+	m.gates.Store(&gates)
+	return c
+}
+
+func (m *Multicast[T]) removeGate(c *Cursor[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old := m.gates.Load()
+	if old == nil {
+		return
+	}
+
+	var gates []*Cursor[T]
+	for _, g := range *old {
+		if g != c {
+			gates = append(gates, g)
+		}
+	}
+	if len(gates) == 0 {
+		m.gates.Store(nil)
+		return
+	}
+	m.gates.Store(&gates)
+}
+
+// waitGates waits until every LagBackpressure cursor has observed the value seq overwrites.
+func (m *Multicast[T]) waitGates(gates []*Cursor[T], seq uint64) {
+	if seq-m.base <= m.mask {
+		return
+	}
+	for _, g := range gates {
+		for atomic.LoadInt32(&g.closed) == 0 && atomic.LoadUint64(&g.next)+m.mask < seq {
+			cpuRelax()
+			runtime.Gosched()
+		}
+	}
+}
+
+// Cursor returns a read-only cursor that starts from the next value to be published. The
+// cursor doesn't affect the shared head nor the other cursors, it's not safe for concurrent
+// use, use one cursor per goroutine instead.
+func (m *Multicast[T]) Cursor() *Cursor[T] {
+	return &Cursor[T]{m: m, next: atomic.LoadUint64(&m.tail)}
+}
+
+// CursorFrom returns a read-only cursor that replays from seq. If seq has been overwritten
+// already the cursor starts from the earliest retained one and counts the gap as skipped, if
+// seq is not published yet the cursor waits there.
+func (m *Multicast[T]) CursorFrom(seq uint64) *Cursor[T] {
+	return &Cursor[T]{m: m, next: seq}
+}
+
+// CursorFromEarliest returns a read-only cursor that replays from the earliest retained
+// value.
+func (m *Multicast[T]) CursorFromEarliest() *Cursor[T] {
+	return &Cursor[T]{m: m, next: m.Earliest()}
+}
+
+// Earliest returns the sequence of the earliest retained value. It's a snapshot, producers
+// may overwrite it at any time.
+func (m *Multicast[T]) Earliest() uint64 {
+	tail := atomic.LoadUint64(&m.tail)
+	if tail-m.base <= m.mask+1 {
+		return m.base
+	}
+	return tail - m.mask - 1
+}
+
+// Checkpoint returns the offset of shared head, i.e. the sequence of the value next Poll
+// returns, to persist the progress of the consumers. It's safe to call from any goroutine.
+func (m *Multicast[T]) Checkpoint() uint64 {
+	return atomic.LoadUint64(&m.head)
+}
+
+// Seek moves shared head to offset, e.g. a Checkpoint persisted before restart. The values
+// before offset are not polled any more, if offset has been overwritten already Poll goes on
+// from the earliest retained one.
+func (m *Multicast[T]) Seek(offset uint64) {
+	atomic.StoreUint64(&m.head, max(offset, m.base))
+}
+
+// Recent fills dst with the most recent values, at most len(dst), from the older to the
+// newer, returns how many values are filled. It's a snapshot for the debugging endpoints
+// that show the last events flowing through: it neither consumes the values nor blocks the
+// producers, values being written or overwritten meanwhile are left out.
+func (m *Multicast[T]) Recent(dst []T) (n int) {
+	tail := atomic.LoadUint64(&m.tail)
+	from := m.Earliest()
+	if want := uint64(len(dst)); tail-from > want {
+		from = tail - want
+	}
+
+	for seq := from; seq < tail; seq++ {
+		if v, status := m.read(seq); status == readSuccess {
+			dst[n] = v
+			n++
+		}
+	}
+	return n
+}
+
+// Tail returns the sequence that the next Offer will claim.
+func (m *Multicast[T]) Tail() uint64 {
+	return atomic.LoadUint64(&m.tail)
+}
+
+// Capacity returns the real (power-of-two) capacity.
+func (m *Multicast[T]) Capacity() uint64 {
+	return m.mask + 1
+}
+
+func (m *Multicast[T]) read(seq uint64) (value T, status readStatus) {
+	slot := &m.slots[seq&m.mask]
+	expected := publishedStamp(seq)
+	stamp := slot.stamp.Load()
+	if stamp < expected {
+		return value, readNotYet
+	}
+	if stamp > expected {
+		return value, readOverwritten
+	}
+
+	p := slot.value.Load()
+	if slot.stamp.Load() != expected {
+		return value, readOverwritten
+	}
+	return *p, readSuccess
+}
+
+func publishedStamp(seq uint64) uint64 {
+	return 2*seq + 2
+}
+
+// Cursor observes values of a Multicast in order without consuming them.
+type Cursor[T any] struct {
+	// first for the 64-bit atomics on 32-bit platforms
+	next     uint64
+	m        *Multicast[T]
+	skipped  uint64
+	policy   LagPolicy
+	detached bool
+	closed   int32
+}
+
+// Next returns the value next to the previous one this cursor observed, return false if
+// there's no more published value yet. Values that overwritten before the cursor reached
+// them are skipped, see Skipped.
+func (c *Cursor[T]) Next() (value T, success bool) {
+	if c.detached {
+		return
+	}
+
+	for {
+		tail := atomic.LoadUint64(&c.m.tail)
+		if c.next >= tail {
+			return
+		}
+		// before the start of ring
+		if c.next < c.m.base {
+			if c.fallBehind(c.m.base) {
+				return
+			}
+			continue
+		}
+
+		// the producers gated by a backpressure cursor claim but don't overwrite
+		if tail-c.next > c.m.mask+1 && c.policy != LagBackpressure {
+			if c.fallBehind(tail - c.m.mask - 1) {
+				return
+			}
+			continue
+		}
+
+		v, status := c.m.read(c.next)
+		switch status {
+		case readNotYet:
+			return
+		case readOverwritten:
+			if c.fallBehind(c.next + 1) {
+				return
+			}
+			continue
+		}
+
+		atomic.StoreUint64(&c.next, c.next+1)
+		return v, true
+	}
+}
+
+// fallBehind handles the cursor fell behind to seq by policy, returns true if detached.
+func (c *Cursor[T]) fallBehind(to uint64) (detached bool) {
+	if c.policy == LagDetach {
+		c.detached = true
+		return true
+	}
+	c.skip(to)
+	return false
+}
+
+// Lag returns how many published values the cursor hasn't observed yet, it's larger than
+// Capacity once the cursor fell behind.
+func (c *Cursor[T]) Lag() uint64 {
+	tail := atomic.LoadUint64(&c.m.tail)
+	if next := atomic.LoadUint64(&c.next); tail > next {
+		return tail - next
+	}
+	return 0
+}
+
+// Detached reports whether a LagDetach cursor fell behind and got detached.
+func (c *Cursor[T]) Detached() bool {
+	return c.detached
+}
+
+// Close stops the cursor gating producers, if it's LagBackpressure. Next still works on a
+// closed cursor, but may skip values.
+func (c *Cursor[T]) Close() {
+	if c.policy != LagBackpressure || !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return
+	}
+	c.policy = LagSkip
+	c.m.removeGate(c)
+}
+
+// Checkpoint returns the offset of the cursor, i.e. the sequence of the value next Next
+// returns, to persist the progress and resume by Seek or CursorFrom after restart. Unlike
+// Sequence, it's safe to call from another goroutine than the one calling Next, e.g. one
+// that checkpoints periodically.
+func (c *Cursor[T]) Checkpoint() uint64 {
+	return atomic.LoadUint64(&c.next)
+}
+
+// Seek moves the cursor to offset, forward or backward, and reattaches a detached cursor. As
+// CursorFrom, if offset has been overwritten already Next goes on from the earliest retained
+// one and counts the gap as skipped. It must be called by the goroutine calling Next.
+func (c *Cursor[T]) Seek(offset uint64) {
+	c.detached = false
+	atomic.StoreUint64(&c.next, offset)
+}
+
+// Sequence returns the sequence of the value that the next Next call will return.
+func (c *Cursor[T]) Sequence() uint64 {
+	return c.next
+}
+
+// Skipped returns how many values have been overwritten before this cursor observed.
+func (c *Cursor[T]) Skipped() uint64 {
+	return c.skipped
+}
+
+func (c *Cursor[T]) skip(to uint64) {
+	c.skipped += to - c.next
+	atomic.StoreUint64(&c.next, to)
+}
@@ -0,0 +1,240 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestMulticastCursorNotConsume(c *C) {
+	// given
+	m := NewMulticast[int](8)
+	cursor := m.Cursor()
+	for i := 0; i < 6; i++ {
+		c.Assert(m.Offer(i), Equals, uint64(i))
+	}
+
+	// when
+	polled1, _ := m.Poll()
+	polled2, _ := m.Poll()
+
+	// then
+	c.Assert(polled1, Equals, 0)
+	c.Assert(polled2, Equals, 1)
+	for i := 0; i < 6; i++ {
+		v, success := cursor.Next()
+		c.Assert(success, Equals, true)
+		c.Assert(v, Equals, i)
+	}
+	_, success := cursor.Next()
+	c.Assert(success, Equals, false)
+	polled3, _ := m.Poll()
+	c.Assert(polled3, Equals, 2)
+}
+
+func (s *MySuite) TestMulticastOverwriteOldest(c *C) {
+	// given
+	m := NewMulticast[int](4)
+	cursor := m.Cursor()
+
+	// when
+	for i := 0; i < 10; i++ {
+		m.Offer(i)
+	}
+
+	// then
+	for i := 6; i < 10; i++ {
+		v, success := cursor.Next()
+		c.Assert(success, Equals, true)
+		c.Assert(v, Equals, i)
+		polled, success := m.Poll()
+		c.Assert(success, Equals, true)
+		c.Assert(polled, Equals, i)
+	}
+	c.Assert(cursor.Skipped(), Equals, uint64(6))
+	_, success := m.Poll()
+	c.Assert(success, Equals, false)
+}
+
+func (s *MySuite) TestMulticastReplay(c *C) {
+	// given
+	m := NewMulticast[int](4)
+	for i := 0; i < 6; i++ {
+		m.Offer(i)
+	}
+
+	// when
+	earliest := m.CursorFromEarliest()
+	chosen := m.CursorFrom(4)
+	stale := m.CursorFrom(0)
+
+	// then
+	c.Assert(m.Earliest(), Equals, uint64(2))
+	c.Assert(earliest.Sequence(), Equals, uint64(2))
+	for i := 2; i < 6; i++ {
+		v, _ := earliest.Next()
+		c.Assert(v, Equals, i)
+	}
+	v, _ := chosen.Next()
+	c.Assert(v, Equals, 4)
+	v, _ = stale.Next()
+	c.Assert(v, Equals, 2)
+	c.Assert(stale.Skipped(), Equals, uint64(2))
+}
+
+func (s *MySuite) TestMulticastLagSkipAndDetach(c *C) {
+	// given
+	m := NewMulticast[int](4)
+	skipping := m.Subscribe(LagSkip)
+	detaching := m.Subscribe(LagDetach)
+
+	// when
+	for i := 0; i < 6; i++ {
+		m.Offer(i)
+	}
+
+	// then
+	c.Assert(skipping.Lag(), Equals, uint64(6))
+	c.Assert(detaching.Lag(), Equals, uint64(6))
+	v, ok := skipping.Next()
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 2)
+	c.Assert(skipping.Skipped(), Equals, uint64(2))
+	c.Assert(skipping.Lag(), Equals, uint64(3))
+	_, ok = detaching.Next()
+	c.Assert(ok, Equals, false)
+	c.Assert(detaching.Detached(), Equals, true)
+	m.Offer(6)
+	_, ok = detaching.Next()
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestMulticastLagBackpressure(c *C) {
+	// given
+	m := NewMulticast[int](4)
+	cursor := m.Subscribe(LagBackpressure)
+	for i := 0; i < 4; i++ {
+		m.Offer(i)
+	}
+	offered := make(chan uint64)
+
+	// when
+	go func() {
+		offered <- m.Offer(4)
+		offered <- m.Offer(5)
+	}()
+
+	// then
+	select {
+	case <-offered:
+		c.Fatal("producer overwrote a value the cursor hasn't observed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	v, _ := cursor.Next()
+	c.Assert(v, Equals, 0)
+	c.Assert(<-offered, Equals, uint64(4))
+
+	// when the cursor closed
+	cursor.Close()
+
+	// then
+	c.Assert(<-offered, Equals, uint64(5))
+	c.Assert(m.gates.Load(), IsNil)
+}
+
+func (s *MySuite) TestMulticastRecent(c *C) {
+	// given
+	m := NewMulticast[int](4)
+	dst := make([]int, 3)
+
+	// when
+	empty := m.Recent(dst)
+	m.Offer(1)
+	m.Offer(2)
+	partial := m.Recent(dst)
+	partialValues := append([]int(nil), dst[:partial]...)
+	for i := 3; i <= 6; i++ {
+		m.Offer(i)
+	}
+	full := m.Recent(dst)
+	wide := make([]int, 8)
+	retained := m.Recent(wide)
+
+	// then
+	c.Assert(empty, Equals, 0)
+	c.Assert(partialValues, DeepEquals, []int{1, 2})
+	c.Assert(dst[:full], DeepEquals, []int{4, 5, 6})
+	c.Assert(wide[:retained], DeepEquals, []int{3, 4, 5, 6})
+	_, polled := m.Poll()
+	c.Assert(polled, Equals, true)
+}
+
+func (s *MySuite) TestMulticastCheckpointAndSeek(c *C) {
+	// given
+	m := NewMulticast[int](4)
+	for i := 0; i < 3; i++ {
+		m.Offer(i)
+	}
+	cursor := m.Cursor()
+	cursor.Seek(0)
+	cursor.Next()
+	m.Poll()
+	m.Poll()
+
+	// when
+	cursorOffset := cursor.Checkpoint()
+	headOffset := m.Checkpoint()
+	cursor.Seek(2)
+	fromCursor, _ := cursor.Next()
+	m.Seek(0)
+	fromHead, _ := m.Poll()
+
+	// then
+	c.Assert(cursorOffset, Equals, uint64(1))
+	c.Assert(headOffset, Equals, uint64(2))
+	c.Assert(fromCursor, Equals, 2)
+	c.Assert(fromHead, Equals, 0)
+}
+
+func (s *MySuite) TestMulticastSeekReattach(c *C) {
+	// given a detached cursor
+	m := NewMulticast[int](2)
+	cursor := m.Subscribe(LagDetach)
+	for i := 0; i < 4; i++ {
+		m.Offer(i)
+	}
+	_, success := cursor.Next()
+	c.Assert(cursor.Detached(), Equals, true)
+
+	// when
+	cursor.Seek(m.Earliest())
+	v, resumed := cursor.Next()
+
+	// then
+	c.Assert(success, Equals, false)
+	c.Assert(resumed, Equals, true)
+	c.Assert(v, Equals, 2)
+	c.Assert(cursor.Detached(), Equals, false)
+}
+
+func (s *MySuite) TestMulticastFrom(c *C) {
+	// given a ring resumed from the offset checkpointed before restart
+	m := NewMulticastFrom[int](2, 100)
+	cursor := m.CursorFrom(90)
+
+	// when
+	first := m.Offer(100)
+	for i := 101; i < 104; i++ {
+		m.Offer(i)
+	}
+	v, success := cursor.Next()
+	head, _ := m.Poll()
+
+	// then
+	c.Assert(first, Equals, uint64(100))
+	c.Assert(m.Earliest(), Equals, uint64(102))
+	c.Assert(success, Equals, true)
+	c.Assert(v, Equals, 102)
+	c.Assert(cursor.Skipped(), Equals, uint64(12))
+	c.Assert(head, Equals, 102)
+	c.Assert(NewMulticastFrom[int](2, 100).Earliest(), Equals, uint64(100))
+}
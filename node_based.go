@@ -2,6 +2,8 @@ package lfring
 
 import (
 	atomic "sync/atomic"
+	"time"
+	"unsafe"
 )
 
 // nodeBased defines a multi-producer multi-consumer ring buffer.
@@ -36,34 +38,73 @@ import (
 //
 // The another difference between this to the mpsc is we no longer need isEmpty() and isFull()
 // to check the buffer status, if buffer full / empty will lead the producer / consumer never
-// pass the node.step check.
+// pass the node.step check. It also means neither side loads the index of the other, so
+// there's no opponent index to cache as classical does, the node.step check is already local
+// to the slot being claimed.
 type nodeBased[T any] struct {
 	head      uint64
 	_padding0 [56]byte
 	tail      uint64
 	_padding1 [56]byte
-	mask      uint64
-	_padding2 [56]byte
-	element   []*node[T]
+	// the read-mostly fields share a line
+	mask     uint64
+	maxBatch uint64
+	timed    bool
+	padded   bool
+	element  []*node[T]
+
+	producerGuard ownerGuard
+	consumerGuard ownerGuard
 }
 
 type node[T any] struct {
-	step     uint64
+	// atomic.Uint64 is 8-byte aligned wherever the node is, even packed in a slice on 32-bit
+	// platforms
+	step     atomic.Uint64
 	value    T
+	enqueued int64
+}
+
+// paddedNode keeps the neighbor nodes off the line, see WithPadding.
+type paddedNode[T any] struct {
+	node[T]
 	_padding [40]byte
 }
 
-func newNodeBased[T any](capacity uint64) RingBuffer[T] {
+func newNodeBased[T any](capacity uint64, c *config) RingBuffer[T] {
 	nodes := make([]*node[T], capacity)
-	for i := uint64(0); i < capacity; i++ {
-		nodes[i] = &node[T]{step: i}
+	if c.padding && c.hugePages {
+		padded := make([]paddedNode[T], capacity)
+		adviseHugePages(unsafe.Pointer(unsafe.SliceData(padded)), uintptr(capacity)*unsafe.Sizeof(padded[0]))
+		for i := range padded {
+			padded[i].step.Store(uint64(i))
+			nodes[i] = &padded[i].node
+		}
+	} else if c.padding {
+		for i := uint64(0); i < capacity; i++ {
+			padded := &paddedNode[T]{}
+			padded.step.Store(i)
+			nodes[i] = &padded.node
+		}
+	} else {
+		compact := make([]node[T], capacity)
+		if c.hugePages {
+			adviseHugePages(unsafe.Pointer(unsafe.SliceData(compact)), uintptr(capacity)*unsafe.Sizeof(compact[0]))
+		}
+		for i := range compact {
+			compact[i].step.Store(uint64(i))
+			nodes[i] = &compact[i]
+		}
 	}
 
 	return &nodeBased[T]{
-		head:    uint64(0),
-		tail:    uint64(0),
-		mask:    capacity - 1,
-		element: nodes,
+		head:     uint64(0),
+		tail:     uint64(0),
+		mask:     capacity - 1,
+		maxBatch: c.maxBatchScan,
+		timed:    c.enqueueTime,
+		padded:   c.padding,
+		element:  nodes,
 	}
 }
 
@@ -71,7 +112,7 @@ func newNodeBased[T any](capacity uint64) RingBuffer[T] {
 func (r *nodeBased[T]) Offer(value T) (success bool) {
 	oldTail := atomic.LoadUint64(&r.tail)
 	tailNode := r.element[oldTail&r.mask]
-	oldStep := atomic.LoadUint64(&tailNode.step)
+	oldStep := tailNode.step.Load()
 	// not published yet
 	if oldStep != oldTail {
 		return false
@@ -82,15 +123,106 @@ func (r *nodeBased[T]) Offer(value T) (success bool) {
 	}
 
 	tailNode.value = value
-	atomic.StoreUint64(&tailNode.step, tailNode.step+1)
+	if r.timed {
+		tailNode.enqueued = monotonicNow()
+	}
+	tailNode.step.Store(tailNode.step.Load() + 1)
+	return true
+}
+
+// OfferIf offers value only if cond holds for the occupancy at the claim, see
+// ConditionalProducer.
+func (r *nodeBased[T]) OfferIf(value T, cond func(len, cap uint64) bool) (success bool) {
+	oldTail := atomic.LoadUint64(&r.tail)
+	tailNode := r.element[oldTail&r.mask]
+	// not published yet
+	if tailNode.step.Load() != oldTail {
+		return false
+	}
+	if !cond(occupancy(r.mask+1, atomic.LoadUint64(&r.head), oldTail), r.mask+1) {
+		return false
+	}
+
+	if !atomic.CompareAndSwapUint64(&r.tail, oldTail, oldTail+1) {
+		return false
+	}
+
+	tailNode.value = value
+	if r.timed {
+		tailNode.enqueued = monotonicNow()
+	}
+	tailNode.step.Store(tailNode.step.Load() + 1)
+	return true
+}
+
+// OfferAllOrNothing offers all values or none of them, see BatchProducer. The slots after
+// tail can only be claimed by moving tail across them, so once they're all free, the CAS of
+// tail claims them at once.
+func (r *nodeBased[T]) OfferAllOrNothing(values []T) (success bool) {
+	n := uint64(len(values))
+	if n == 0 {
+		return true
+	}
+	if n > r.mask+1 {
+		return false
+	}
+
+	oldTail := atomic.LoadUint64(&r.tail)
+	for seq := oldTail; seq < oldTail+n; seq++ {
+		// not polled yet
+		if r.element[seq&r.mask].step.Load() != seq {
+			return false
+		}
+	}
+	if !atomic.CompareAndSwapUint64(&r.tail, oldTail, oldTail+n) {
+		return false
+	}
+
+	var enqueued int64
+	if r.timed {
+		enqueued = monotonicNow()
+	}
+	for i, value := range values {
+		seq := oldTail + uint64(i)
+		tailNode := r.element[seq&r.mask]
+		tailNode.value = value
+		tailNode.enqueued = enqueued
+		tailNode.step.Store(seq + 1)
+	}
 	return true
 }
 
+// OfferReject offers value, see OverwritingProducer.
+func (r *nodeBased[T]) OfferReject(value T) (success bool) {
+	return r.Offer(value)
+}
+
+// OfferOverwrite offers value, drops the oldest if full, see OverwritingProducer.
+func (r *nodeBased[T]) OfferOverwrite(value T) (dropped T, overwritten bool) {
+	return offerOverwrite[T](r, value, func() bool {
+		return r.Len() == r.mask+1
+	})
+}
+
 // Poll head value pointer.
 func (r *nodeBased[T]) Poll() (value T, success bool) {
+	value, _, success = r.poll()
+	return
+}
+
+// PollTimed polls head value along with its enqueue time, see WithEnqueueTime.
+func (r *nodeBased[T]) PollTimed() (value T, enqueued time.Time, success bool) {
+	value, at, success := r.poll()
+	if success && r.timed {
+		enqueued = monotonicTime(at)
+	}
+	return
+}
+
+func (r *nodeBased[T]) poll() (value T, enqueued int64, success bool) {
 	oldHead := atomic.LoadUint64(&r.head)
 	headNode := r.element[oldHead&r.mask]
-	oldStep := atomic.LoadUint64(&headNode.step)
+	oldStep := headNode.step.Load()
 	// not published yet
 	if oldStep != oldHead+1 {
 		return
@@ -101,96 +233,262 @@ func (r *nodeBased[T]) Poll() (value T, success bool) {
 	}
 
 	value = headNode.value
-	atomic.StoreUint64(&headNode.step, oldStep+r.mask)
-	return value, true
+	enqueued = headNode.enqueued
+	headNode.step.Store(oldStep + r.mask)
+	return value, enqueued, true
+}
+
+// BeginPoll claims head value by a transaction, see TransactionalConsumer. Head moves on like
+// Poll does, but the slot stays published until Commit.
+func (r *nodeBased[T]) BeginPoll() (tx *PollTx[T], success bool) {
+	oldHead := atomic.LoadUint64(&r.head)
+	headNode := r.element[oldHead&r.mask]
+	// not published yet
+	if headNode.step.Load() != oldHead+1 {
+		return
+	}
+
+	if !atomic.CompareAndSwapUint64(&r.head, oldHead, oldHead+1) {
+		return
+	}
+
+	return &PollTx[T]{owner: r, head: oldHead, value: headNode.value}, true
+}
+
+func (r *nodeBased[T]) commitPoll(head uint64) {
+	r.element[head&r.mask].step.Store(head + r.mask + 1)
+}
+
+// abortPoll moves head back, the slot is still published for it.
+func (r *nodeBased[T]) abortPoll(head uint64) bool {
+	return atomic.CompareAndSwapUint64(&r.head, head+1, head)
 }
 
+// SingleProducerOffer offers values from valueSupplier until finish or buffer full. The caller
+// must be the only producer, so tail moves by plain store rather than CAS. Build with tag
+// lfring_debug to detect the violation.
 func (r *nodeBased[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
-	// TODO: currently just wrapper
+	if debugAssertions {
+		r.producerGuard.enter("SingleProducerOffer")
+		defer r.producerGuard.exit()
+	}
+
+	oldTail := atomic.LoadUint64(&r.tail)
+	tail := oldTail
 	for {
+		tailNode := r.element[tail&r.mask]
+		// not polled yet, buffer is full
+		if tailNode.step.Load() != tail {
+			break
+		}
+
 		v, finish := valueSupplier()
 		if finish {
-			return
+			break
 		}
-
-		for !r.Offer(v) {
+		tailNode.value = v
+		if r.timed {
+			tailNode.enqueued = monotonicNow()
 		}
+		tailNode.step.Store(tail + 1)
+		tail++
 	}
+
+	if debugAssertions && atomic.LoadUint64(&r.tail) != oldTail {
+		panic("lfring: tail moved by other producer during SingleProducerOffer")
+	}
+	atomic.StoreUint64(&r.tail, tail)
 }
 
+// SingleConsumerPoll passes every value in buffer to valueConsumer, until meets a value not
+// published yet or the tail at entry. The caller must be the only consumer, so head moves by
+// plain store rather than CAS. Build with tag lfring_debug to detect the violation.
 func (r *nodeBased[T]) SingleConsumerPoll(valueConsumer func(T)) {
-	// TODO: currently just wrapper
-	for {
-		v, success := r.Poll()
-		if !success {
-			return
+	if debugAssertions {
+		r.consumerGuard.enter("SingleConsumerPoll")
+		defer r.consumerGuard.exit()
+	}
+
+	oldHead := atomic.LoadUint64(&r.head)
+	oldTail := atomic.LoadUint64(&r.tail)
+	head := oldHead
+	for ; head < oldTail; head++ {
+		headNode := r.element[head&r.mask]
+		// not published yet
+		if headNode.step.Load() != head+1 {
+			break
 		}
+
+		v := headNode.value
+		headNode.step.Store(head + r.mask + 1)
 		valueConsumer(v)
 	}
+
+	r.storeSingleConsumerHead(oldHead, head)
 }
 
-func (r *nodeBased[T]) SingleConsumerPollVec(ret []T) (end uint64) {
-	// TODO: currently just wrapper
-	var cnt int
-	for ; cnt < len(ret); cnt++ {
-		v, success := r.Poll()
-		if !success {
+// SingleConsumerPollVec fills ret with values in buffer, returns how many values are filled.
+// The caller must be the only consumer, see SingleConsumerPoll.
+func (r *nodeBased[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
+	if debugAssertions {
+		r.consumerGuard.enter("SingleConsumerPollVec")
+		defer r.consumerGuard.exit()
+	}
+
+	oldHead := atomic.LoadUint64(&r.head)
+	head := oldHead
+	for ; head-oldHead < uint64(len(ret)); head++ {
+		headNode := r.element[head&r.mask]
+		// not published yet
+		if headNode.step.Load() != head+1 {
 			break
 		}
 
-		ret[cnt] = v
+		ret[head-oldHead] = headNode.value
+		headNode.step.Store(head + r.mask + 1)
 	}
 
-	return uint64(cnt)
+	r.storeSingleConsumerHead(oldHead, head)
+	return head - oldHead
 }
 
-// Alternative optimized version that tries to batch claim multiple positions
-// This is more complex but could be even faster under high contention
+// Requeue puts value back to tail, see Requeuer.
+func (r *nodeBased[T]) Requeue(value T) (success bool) {
+	for !r.Offer(value) {
+		if r.Len() == r.mask+1 {
+			return false
+		}
+		cpuRelax()
+	}
+	return true
+}
+
+func (r *nodeBased[T]) storeSingleConsumerHead(oldHead uint64, head uint64) {
+	if debugAssertions && atomic.LoadUint64(&r.head) != oldHead {
+		panic("lfring: head moved by other consumer during single consumer poll")
+	}
+	atomic.StoreUint64(&r.head, head)
+}
+
+func (r *nodeBased[T]) full() bool {
+	return r.Len() == r.mask+1
+}
+
+func (r *nodeBased[T]) Len() uint64 {
+	return r.State().Occupancy
+}
+
+func (r *nodeBased[T]) SizeConsistent() (size uint64, ok bool) {
+	return consistentSize(r.mask+1, &r.head, &r.tail)
+}
+
+func (r *nodeBased[T]) Layout() Layout {
+	nodeSize := unsafe.Sizeof(node[T]{})
+	if r.padded {
+		nodeSize = unsafe.Sizeof(paddedNode[T]{})
+	}
+	return Layout{
+		CacheLine: cacheLineSize,
+		Head:      unsafe.Offsetof(r.head),
+		Tail:      unsafe.Offsetof(r.tail),
+		Mask:      unsafe.Offsetof(r.mask),
+		Element:   unsafe.Offsetof(r.element),
+		SlotSize:  nodeSize,
+		Padded:    r.padded,
+	}
+}
+
+// prefault writes the value of every node, a large value spans the pages the step written by
+// newNodeBased doesn't.
+func (r *nodeBased[T]) prefault() {
+	var zero T
+	for _, n := range r.element {
+		n.value = zero
+	}
+}
+
+func (r *nodeBased[T]) MemoryUsage() MemoryUsage {
+	capacity := r.mask + 1
+	u := MemoryUsage{
+		Header:   uint64(unsafe.Sizeof(*r)),
+		Slots:    capacity * uint64(r.Layout().SlotSize),
+		Index:    capacity * uint64(unsafe.Sizeof(r.element[0])),
+		Segments: 1,
+	}
+	if r.padded {
+		u.Padding = capacity * uint64(unsafe.Sizeof(paddedNode[T]{})-unsafe.Sizeof(node[T]{}))
+	}
+	return u
+}
+
+func (r *nodeBased[T]) Cap() uint64 {
+	return r.mask + 1
+}
+
+func (r *nodeBased[T]) State() State {
+	head := atomic.LoadUint64(&r.head)
+	tail := atomic.LoadUint64(&r.tail)
+	return newState(NodeBased, r.mask+1, head, tail)
+}
+
+func (r *nodeBased[T]) String() string {
+	return r.State().String()
+}
+
+// PollNBatched polls at most n values, see PollBatchInto.
 func (r *nodeBased[T]) PollNBatched(n uint64) (values []T, count uint64) {
 	if n == 0 {
 		return nil, 0
 	}
 
-	values = make([]T, 0, n)
-	
+	values = make([]T, n)
+	count = r.PollBatchInto(values)
+	return values[:count], count
+}
+
+// PollBatchInto fills dst with the head values, returns how many values are filled.
+// Alternative optimized version that tries to batch claim multiple positions
+// This is more complex but could be even faster under high contention
+func (r *nodeBased[T]) PollBatchInto(dst []T) (count uint64) {
+	n := uint64(len(dst))
 	for count < n {
 		oldHead := atomic.LoadUint64(&r.head)
-		
+
 		// Check how many consecutive values are available
 		available := uint64(0)
-		for i := uint64(0); i < n-count && available < 8; i++ { // Limit batch size to avoid long loops
+		for i := uint64(0); i < n-count && available < r.maxBatch; i++ { // Limit batch size to avoid long loops
 			nodeIdx := (oldHead + i) & r.mask
 			node := r.element[nodeIdx]
-			step := atomic.LoadUint64(&node.step)
-			
+			step := node.step.Load()
+
 			if step != oldHead+i+1 {
 				break // This value is not ready
 			}
 			available++
 		}
-		
+
 		if available == 0 {
 			break // No values available
 		}
-		
+
 		// Try to claim this batch
 		if !atomic.CompareAndSwapUint64(&r.head, oldHead, oldHead+available) {
-			// Another consumer interfered, try again with single item
+			// Another consumer interfered, try again
 			continue
 		}
-		
+
 		// Successfully claimed batch, extract values
 		for i := uint64(0); i < available; i++ {
 			nodeIdx := (oldHead + i) & r.mask
 			node := r.element[nodeIdx]
-			step := atomic.LoadUint64(&node.step)
-			
-			values = append(values, node.value)
-			atomic.StoreUint64(&node.step, step+r.mask)
+			step := node.step.Load()
+
+			dst[count+i] = node.value
+			node.step.Store(step + r.mask)
 		}
-		
+
 		count += available
 	}
 
-	return values, count
+	return count
 }
@@ -0,0 +1,47 @@
+package lfring
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// closedChan is returned by the readiness methods when ready already.
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// notifier parks goroutines until the next broadcast. A waiter must get the channel by wait
+// before it checks the condition (e.g. try Poll again), then a broadcast happens after the
+// check closes exactly that channel, so no wakeup gets lost. Broadcast is a single atomic
+// load when nobody waits, which keeps it cheap enough for the hot path.
+type notifier struct {
+	waiting int32
+	mu      sync.Mutex
+	ch      chan struct{}
+}
+
+func (n *notifier) wait() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+	}
+	atomic.StoreInt32(&n.waiting, 1)
+	return n.ch
+}
+
+func (n *notifier) broadcast() {
+	if atomic.LoadInt32(&n.waiting) == 0 {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ch != nil {
+		close(n.ch)
+		n.ch = nil
+	}
+	atomic.StoreInt32(&n.waiting, 0)
+}
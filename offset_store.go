@@ -0,0 +1,97 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OffsetStore persists the offsets checkpointed by the consumer groups (see Cursor.Checkpoint
+// and Multicast.Checkpoint), one offset per group name. FileOffsetStore is the reference
+// implementation, a store over Redis, SQL and so on only needs the two methods:
+//
+//	func (s *redisStore) Save(ctx context.Context, group string, offset uint64) error {
+//		return s.client.Set(ctx, "offset:"+group, offset, 0).Err()
+//	}
+type OffsetStore interface {
+	// Save persists offset of group, overwrites the previous one.
+	Save(ctx context.Context, group string, offset uint64) error
+	// Load returns the offset saved of group, found is false if nothing saved yet.
+	Load(ctx context.Context, group string) (offset uint64, found bool, err error)
+}
+
+// FileOffsetStore is an OffsetStore keeps the offset of every group in a file of dir. A file
+// is replaced by rename on Save, so a crash in between leaves the previous offset rather than
+// a torn one.
+type FileOffsetStore struct {
+	dir string
+}
+
+// NewFileOffsetStore build a FileOffsetStore over dir, dir is created if not exists.
+func NewFileOffsetStore(dir string) (*FileOffsetStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileOffsetStore{dir: dir}, nil
+}
+
+// Save writes offset of group to its file.
+func (s *FileOffsetStore) Save(_ context.Context, group string, offset uint64) error {
+	f, err := os.CreateTemp(s.dir, ".offset-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(strconv.FormatUint(offset, 10) + "\n")
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(group))
+}
+
+// Load reads offset of group from its file.
+func (s *FileOffsetStore) Load(_ context.Context, group string) (offset uint64, found bool, err error) {
+	data, err := os.ReadFile(s.path(group))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	offset, err = strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return offset, true, nil
+}
+
+// path escapes group, so any group name maps to a file right in dir.
+func (s *FileOffsetStore) path(group string) string {
+	return filepath.Join(s.dir, url.PathEscape(group)+".offset")
+}
+
+// CursorFromStore returns a cursor of m that resumes from the offset of group in store, or
+// from the earliest retained value if nothing saved yet. Save cursor.Checkpoint() to store
+// periodically to keep the reprocessing after restart small.
+func CursorFromStore[T any](ctx context.Context, m *Multicast[T], store OffsetStore, group string) (*Cursor[T], error) {
+	offset, found, err := store.Load(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return m.CursorFromEarliest(), nil
+	}
+	return m.CursorFrom(offset), nil
+}
@@ -0,0 +1,66 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"os"
+	"path/filepath"
+)
+
+func (s *MySuite) TestFileOffsetStore(c *C) {
+	// given
+	ctx := context.Background()
+	store, err := NewFileOffsetStore(filepath.Join(c.MkDir(), "offsets"))
+	c.Assert(err, IsNil)
+
+	// when
+	_, foundBefore, errBefore := store.Load(ctx, "billing/v1")
+	c.Assert(store.Save(ctx, "billing/v1", 41), IsNil)
+	c.Assert(store.Save(ctx, "billing/v1", 42), IsNil)
+	c.Assert(store.Save(ctx, "audit", 7), IsNil)
+	offset, found, err := store.Load(ctx, "billing/v1")
+
+	// then
+	c.Assert(errBefore, IsNil)
+	c.Assert(foundBefore, Equals, false)
+	c.Assert(err, IsNil)
+	c.Assert(found, Equals, true)
+	c.Assert(offset, Equals, uint64(42))
+	entries, _ := os.ReadDir(store.dir)
+	c.Assert(entries, HasLen, 2)
+}
+
+func (s *MySuite) TestFileOffsetStoreCorrupted(c *C) {
+	// given
+	store, _ := NewFileOffsetStore(c.MkDir())
+	c.Assert(os.WriteFile(store.path("g"), []byte("x"), 0o644), IsNil)
+
+	// when
+	_, found, err := store.Load(context.Background(), "g")
+
+	// then
+	c.Assert(err, NotNil)
+	c.Assert(found, Equals, false)
+}
+
+func (s *MySuite) TestCursorFromStore(c *C) {
+	// given
+	ctx := context.Background()
+	store, _ := NewFileOffsetStore(c.MkDir())
+	m := NewMulticast[int](8)
+	for i := 0; i < 4; i++ {
+		m.Offer(i)
+	}
+
+	// when
+	fresh, _ := CursorFromStore(ctx, m, store, "g")
+	fresh.Next()
+	fresh.Next()
+	c.Assert(store.Save(ctx, "g", fresh.Checkpoint()), IsNil)
+	resumed, err := CursorFromStore(ctx, m, store, "g")
+
+	// then
+	c.Assert(err, IsNil)
+	v, _ := resumed.Next()
+	c.Assert(v, Equals, 2)
+}
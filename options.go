@@ -0,0 +1,219 @@
+package lfring
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// Option configures the optional behaviors of ring buffer and the helpers built on it.
+// An option that doesn't apply to the thing being built is simply ignored.
+type Option func(*config)
+
+type config struct {
+	name         string
+	trace        bool
+	logger       *slog.Logger
+	highMark     uint64
+	lowMark      uint64
+	enqueueTime  bool
+	padding      bool
+	batchSize    uint64
+	maxBatchScan uint64
+	spinLimit    int
+	maxRetries   int
+	shards       uint64
+	quantum      uint64
+	warmup       bool
+	warmupCycles int
+	hugePages    bool
+	profiler     *Profiler
+	deadLetter   func(value any, err error)
+	wait         WaitStrategy
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		batchSize:    64,
+		maxBatchScan: 8,
+		spinLimit:    64,
+		maxRetries:   3,
+		padding:      true,
+		shards:       uint64(runtime.GOMAXPROCS(0)),
+		wait:         YieldingWait(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithBatchSize sets how many values a consumer loop drains from the buffer in one pass.
+func WithBatchSize(size uint64) Option {
+	return func(c *config) {
+		if size > 0 {
+			c.batchSize = size
+		}
+	}
+}
+
+// WithWaitStrategy sets how a loop waits when the buffer is empty (or full), default is
+// YieldingWait.
+func WithWaitStrategy(wait WaitStrategy) Option {
+	return func(c *config) {
+		if wait != nil {
+			c.wait = wait
+		}
+	}
+}
+
+// WithMaxBatchScan sets how many consecutive slots PollNBatched scans and claims by one CAS,
+// default is 8. A larger limit saves CAS rounds when consumer drains lots of values per
+// call, a smaller one keeps the claim fair among consumers.
+func WithMaxBatchScan(limit uint64) Option {
+	return func(c *config) {
+		if limit > 0 {
+			c.maxBatchScan = limit
+		}
+	}
+}
+
+// WithSpinLimit sets how many attempts a blocking call spins (by the wait strategy) before
+// parks, default is 64. Zero parks at once.
+func WithSpinLimit(attempts int) Option {
+	return func(c *config) {
+		if attempts >= 0 {
+			c.spinLimit = attempts
+		}
+	}
+}
+
+// WithMaxRetries sets how many times Consume retries a value that handler decides Retry,
+// default is 3.
+func WithMaxRetries(retries int) Option {
+	return func(c *config) {
+		if retries >= 0 {
+			c.maxRetries = retries
+		}
+	}
+}
+
+// WithDeadLetter sets where Consume sends the values that decided DeadLetter or run out of
+// retries, along with the error from handler. The values are dropped (and logged by
+// WithLogger) by default.
+func WithDeadLetter(deadLetter func(value any, err error)) Option {
+	return func(c *config) {
+		c.deadLetter = deadLetter
+	}
+}
+
+// WithShards sets how many shards a Relaxed buffer splits into, rounded up to power-of-two,
+// default is GOMAXPROCS. The shards share the capacity, at least 2 slots each.
+func WithShards(shards uint64) Option {
+	return func(c *config) {
+		if shards > 0 {
+			c.shards = shards
+		}
+	}
+}
+
+// WithProfiler sets the Profiler that histograms the retries of Blocking calls, disabled by
+// default.
+func WithProfiler(profiler *Profiler) Option {
+	return func(c *config) {
+		c.profiler = profiler
+	}
+}
+
+// WithName names the ring, the long-running goroutines of the helpers (e.g.
+// BatchEventProcessor, ConsumeParallel) are tagged with the name by pprof labels, see
+// Labeled.
+func WithName(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// WithTrace emits the runtime/trace regions of claim, publish and consume batch (see
+// RegionClaim and the others) while the trace is running, disabled by default.
+func WithTrace() Option {
+	return func(c *config) {
+		c.trace = true
+	}
+}
+
+// WithLogger sets the logger of the rare events, e.g. Blocking closed, values dropped by
+// OfferUntil or Consume, watermarks crossed (see WithWatermarks) and stalls detected by
+// Watchdog. The hot path never logs. Disabled by default.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// WithWatermarks logs (by WithLogger) once the occupancy of Blocking reaches high, and once
+// it falls back to low after, the gap between them keeps a buffer hovering around high from
+// flooding the log. It's checked after every offer and poll through Blocking, which costs a
+// Len per call while set. Default is 0, disabled, low is taken as high if above it.
+func WithWatermarks(high, low uint64) Option {
+	return func(c *config) {
+		c.highMark, c.lowMark = high, min(low, high)
+	}
+}
+
+// WithEnqueueTime records the monotonic enqueue time in every slot of NodeBased, FetchAdd
+// and Relaxed buffers, which can be retrieved by PollTimed, see TimedConsumer. It costs a
+// clock read per offer, disabled by default.
+func WithEnqueueTime() Option {
+	return func(c *config) {
+		c.enqueueTime = true
+	}
+}
+
+// WithPadding sets whether the slots of NodeBased, FetchAdd and Relaxed buffers are padded
+// against false sharing between neighbors, default is true. Without padding the slots are
+// packed in one allocation, which saves 40 bytes per slot for memory-constrained uses with
+// little contention. It doesn't disable the padding entirely: head, tail and the read-mostly
+// fields are always on their own lines, as head and tail are written by every offer and poll,
+// sharing a line makes every producer stall every consumer, see Layout.
+func WithPadding(enabled bool) Option {
+	return func(c *config) {
+		c.padding = enabled
+	}
+}
+
+// WithStarvationQuantum sets the anti-starvation quantum of Priority: a waiting level is
+// served once after quantum values of the higher levels passed it, so a busy high level
+// can't starve the lower ones forever. Default is 0, the strict priority.
+func WithStarvationQuantum(quantum uint64) Option {
+	return func(c *config) {
+		c.quantum = quantum
+	}
+}
+
+// WithWarmup touches every slot of NodeBased, FetchAdd, Classical and Relaxed buffers at
+// construction, so the page faults are taken by New rather than by the first seconds of
+// traffic. Then it runs cycles rounds of filling the buffer with zero values and draining it,
+// to warm up the caches and the allocations (e.g. the boxes of Classical), so head and tail
+// don't start from 0 then. Disabled by default.
+func WithWarmup(cycles int) Option {
+	return func(c *config) {
+		c.warmup = true
+		c.warmupCycles = max(cycles, 0)
+	}
+}
+
+// WithHugePages asks for the transparent huge pages (madvise MADV_HUGEPAGE) on the slot
+// arrays of NodeBased, FetchAdd, Classical and Relaxed buffers on Linux, which cuts the TLB
+// misses of the very large rings, e.g. millions of slots. The padded slots are allocated in
+// one array then, rather than one by one. It's only a hint: the arrays smaller than a huge
+// page, THP disabled by the kernel, and the other platforms just go on with the normal pages.
+// Disabled by default.
+//
+// The explicit huge pages (MAP_HUGETLB) are not offered, the slots may hold pointers, which
+// must stay in the memory of the Go heap.
+func WithHugePages() Option {
+	return func(c *config) {
+		c.hugePages = true
+	}
+}
@@ -0,0 +1,137 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ConsumeOrdered processes the values of consumer by n goroutines concurrently, but releases
+// the results to emit strictly in the order the values are polled, for pipelines parallelize
+// CPU work but must preserve output order.
+//
+// A dispatcher (the only consumer of consumer) tags every value with its sequence and hands
+// it to the workers, and the results wait in a reorder window keyed by sequence until the
+// results before them are emitted. The window holds WithBatchSize+n results, a worker ahead
+// of the window waits, so a slow value stalls at most the window. emit is never called
+// concurrently.
+//
+// It returns like ConsumeParallel: the process errors (or *PanicError) joined, ctx.Err() when
+// ctx done, nil when consumer closed and drained and every result emitted.
+func ConsumeOrdered[T, R any](ctx context.Context, consumer Consumer[T], n int, process func(T) (R, error), emit func(R), opts ...Option) error {
+	if n < 1 {
+		n = 1
+	}
+	c := newConfig(opts)
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	work := NewBlocking[sequenced[T]](New[sequenced[T]](NodeBased, c.batchSize), opts...)
+	window := newReorderWindow[R](c.batchSize+uint64(n), emit)
+
+	var mu sync.Mutex
+	var errs []error
+	report := func(err error) {
+		// stopped by ctx, reported once at last
+		if err == nil || (workerCtx.Err() != nil && errors.Is(err, workerCtx.Err())) {
+			return
+		}
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+		cancel()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(n + 1)
+	go labeled(workerCtx, c.name, RoleConsumer, func(ctx context.Context) {
+		defer wg.Done()
+		defer work.Close()
+		var seq uint64
+		report(SingleConsumerStream(ctx, consumer, func(v T) {
+			if work.Send(ctx, sequenced[T]{seq: seq, value: v}) == nil {
+				seq++
+			}
+		}, opts...))
+	})
+	for i := 0; i < n; i++ {
+		go labeled(workerCtx, c.name, RoleConsumer, func(ctx context.Context) {
+			defer wg.Done()
+			report(RunConsumer[sequenced[T]](ctx, work, func(s sequenced[T]) error {
+				r, err := process(s.value)
+				if err != nil {
+					return err
+				}
+				return window.complete(ctx, s.seq, r)
+			}))
+		})
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+type sequenced[T any] struct {
+	seq   uint64
+	value T
+}
+
+// reorderWindow emits the completed results in sequence order, the result of seq is kept at
+// slots[seq%len(slots)] until every result before it emitted.
+type reorderWindow[R any] struct {
+	mu       sync.Mutex
+	next     uint64
+	slots    []R
+	ready    []bool
+	emit     func(R)
+	advanced notifier
+}
+
+func newReorderWindow[R any](size uint64, emit func(R)) *reorderWindow[R] {
+	return &reorderWindow[R]{
+		slots: make([]R, size),
+		ready: make([]bool, size),
+		emit:  emit,
+	}
+}
+
+// complete stores the result of seq, emits the results become in order, and waits while seq
+// is ahead of the window.
+func (w *reorderWindow[R]) complete(ctx context.Context, seq uint64, result R) error {
+	size := uint64(len(w.slots))
+	for {
+		parked := w.advanced.wait()
+		w.mu.Lock()
+		if seq < w.next+size {
+			break
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-parked:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer w.mu.Unlock()
+
+	w.slots[seq%size] = result
+	w.ready[seq%size] = true
+	if seq != w.next {
+		return nil
+	}
+
+	var zero R
+	for idx := w.next % size; w.ready[idx]; idx = w.next % size {
+		w.emit(w.slots[idx])
+		w.slots[idx] = zero
+		w.ready[idx] = false
+		w.next++
+	}
+	w.advanced.broadcast()
+	return nil
+}
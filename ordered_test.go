@@ -0,0 +1,55 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+	"math/rand/v2"
+	"time"
+)
+
+func (s *MySuite) TestConsumeOrderedKeepsOrder(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 256))
+	for i := 0; i < 200; i++ {
+		buffer.Put(i)
+	}
+	buffer.Close()
+	var emitted []int
+
+	// when
+	err := ConsumeOrdered[int, int](context.Background(), buffer, 4, func(v int) (int, error) {
+		// finish out of order
+		time.Sleep(time.Duration(rand.IntN(100)) * time.Microsecond)
+		return v * 2, nil
+	}, func(r int) {
+		emitted = append(emitted, r)
+	}, WithBatchSize(8))
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(len(emitted), Equals, 200)
+	for i, r := range emitted {
+		c.Assert(r, Equals, i*2)
+	}
+}
+
+func (s *MySuite) TestConsumeOrderedStopsOnError(c *C) {
+	// given
+	buffer := NewBlocking[int](New[int](NodeBased, 16))
+	for i := 0; i < 10; i++ {
+		buffer.Put(i)
+	}
+	failure := errors.New("failure")
+
+	// when
+	err := ConsumeOrdered[int, int](context.Background(), buffer, 2, func(v int) (int, error) {
+		if v == 3 {
+			return 0, failure
+		}
+		return v, nil
+	}, func(int) {})
+
+	// then
+	c.Assert(errors.Is(err, failure), Equals, true)
+}
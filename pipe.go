@@ -0,0 +1,223 @@
+package lfring
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// RingPipe returns two connected in-memory net.Conn backed by two ByteRing of size bytes
+// (expands to power-of-two), one per direction. Unlike net.Pipe, writes are buffered: a
+// Write returns once copied into the ring rather than handed over to a Read, so it's faster
+// for tests and in-process transports. A call that doesn't wait never allocates, a call
+// that waits for the peer allocates a notifier channel, and a timer if a deadline is set.
+//
+// Close works like a socket: the peer reads the rest bytes then gets io.EOF, and the peer
+// writes get io.ErrClosedPipe. Deadlines are supported, an expired call returns
+// os.ErrDeadlineExceeded.
+func RingPipe(size uint64) (net.Conn, net.Conn) {
+	ab := newPipeHalf(size)
+	ba := newPipeHalf(size)
+	a := &pipeConn{in: ba, out: ab, closed: make(chan struct{})}
+	b := &pipeConn{in: ab, out: ba, closed: make(chan struct{})}
+	return a, b
+}
+
+// pipeHalf is a direction of RingPipe.
+type pipeHalf struct {
+	ring     *ByteRing
+	readMu   sync.Mutex
+	writeMu  sync.Mutex
+	readable notifier
+	writable notifier
+	once     sync.Once
+	done     chan struct{}
+}
+
+func newPipeHalf(size uint64) *pipeHalf {
+	return &pipeHalf{ring: NewByteRing(size), done: make(chan struct{})}
+}
+
+func (h *pipeHalf) close() {
+	h.once.Do(func() {
+		close(h.done)
+		h.readable.broadcast()
+		h.writable.broadcast()
+	})
+}
+
+func (h *pipeHalf) isDone() bool {
+	select {
+	case <-h.done:
+		return true
+	default:
+		return false
+	}
+}
+
+type pipeConn struct {
+	in   *pipeHalf
+	out  *pipeHalf
+	once sync.Once
+
+	closed        chan struct{}
+	readDeadline  pipeDeadline
+	writeDeadline pipeDeadline
+}
+
+func (c *pipeConn) Read(p []byte) (n int, err error) {
+	c.in.readMu.Lock()
+	defer c.in.readMu.Unlock()
+
+	for {
+		if c.isClosed() {
+			return 0, io.ErrClosedPipe
+		}
+		if n = c.in.ring.POD.Read(p); n > 0 || len(p) == 0 {
+			c.in.writable.broadcast()
+			return n, nil
+		}
+		if c.in.isDone() {
+			// bytes may be written right before closed
+			if n = c.in.ring.POD.Read(p); n > 0 {
+				return n, nil
+			}
+			return 0, io.EOF
+		}
+
+		parked := c.in.readable.wait()
+		if c.in.ring.Len() > 0 {
+			continue
+		}
+		if err = c.wait(parked, c.in.done, &c.readDeadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (c *pipeConn) Write(p []byte) (n int, err error) {
+	c.out.writeMu.Lock()
+	defer c.out.writeMu.Unlock()
+
+	for {
+		if c.isClosed() || c.out.isDone() {
+			return n, io.ErrClosedPipe
+		}
+		n += c.out.ring.POD.Write(p[n:])
+		c.out.readable.broadcast()
+		if n == len(p) {
+			return n, nil
+		}
+
+		parked := c.out.writable.wait()
+		if c.out.ring.Len() < c.out.ring.Cap() {
+			continue
+		}
+		if err = c.wait(parked, c.out.done, &c.writeDeadline); err != nil {
+			return n, err
+		}
+	}
+}
+
+// wait waits parked until the half done, the conn closed or the deadline passed.
+func (c *pipeConn) wait(parked <-chan struct{}, done <-chan struct{}, deadline *pipeDeadline) error {
+	t, changed := deadline.get()
+	var timeout <-chan time.Time
+	if !t.IsZero() {
+		d := time.Until(t)
+		if d <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-parked:
+	case <-done:
+	case <-c.closed:
+	case <-changed:
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+	}
+	return nil
+}
+
+func (c *pipeConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *pipeConn) Close() error {
+	err := io.ErrClosedPipe
+	c.once.Do(func() {
+		close(c.closed)
+		c.in.close()
+		c.out.close()
+		err = nil
+	})
+	return err
+}
+
+func (c *pipeConn) LocalAddr() net.Addr {
+	return pipeAddr{}
+}
+
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return pipeAddr{}
+}
+
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	c.writeDeadline.set(t)
+	return nil
+}
+
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.set(t)
+	return nil
+}
+
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline.set(t)
+	return nil
+}
+
+// pipeDeadline holds a deadline, changed is closed once the deadline set again, so the
+// blocked calls reevaluate it.
+type pipeDeadline struct {
+	mu      sync.Mutex
+	t       time.Time
+	changed chan struct{}
+}
+
+func (d *pipeDeadline) get() (t time.Time, changed <-chan struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.changed == nil {
+		d.changed = make(chan struct{})
+	}
+	return d.t, d.changed
+}
+
+func (d *pipeDeadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.t = t
+	if d.changed != nil {
+		close(d.changed)
+		d.changed = nil
+	}
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "ringpipe" }
+func (pipeAddr) String() string  { return "ringpipe" }
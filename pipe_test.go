@@ -0,0 +1,83 @@
+package lfring
+
+import (
+	"bytes"
+	. "gopkg.in/check.v1"
+	"io"
+	"os"
+	"time"
+)
+
+func (s *MySuite) TestRingPipeDuplex(c *C) {
+	// given
+	a, b := RingPipe(16)
+	payload := bytes.Repeat([]byte("0123456789"), 100)
+	echoed := make(chan []byte)
+
+	// when
+	go func() {
+		// echo back until a closed
+		_, _ = io.Copy(b, b)
+	}()
+	go func() {
+		data := make([]byte, len(payload))
+		_, _ = io.ReadFull(a, data)
+		echoed <- data
+	}()
+	n, err := a.Write(payload)
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, len(payload))
+	c.Assert(<-echoed, DeepEquals, payload)
+	c.Assert(a.Close(), IsNil)
+}
+
+func (s *MySuite) TestRingPipeCloseAndEOF(c *C) {
+	// given
+	a, b := RingPipe(8)
+	_, _ = a.Write([]byte("bye"))
+
+	// when
+	c.Assert(a.Close(), IsNil)
+
+	// then
+	data, err := io.ReadAll(b)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "bye")
+	_, err = b.Write([]byte("x"))
+	c.Assert(err, Equals, io.ErrClosedPipe)
+	_, err = a.Read(make([]byte, 1))
+	c.Assert(err, Equals, io.ErrClosedPipe)
+	c.Assert(a.Close(), Equals, io.ErrClosedPipe)
+	c.Assert(a.LocalAddr().Network(), Equals, "ringpipe")
+}
+
+func (s *MySuite) TestRingPipeDeadlines(c *C) {
+	// given
+	a, b := RingPipe(4)
+	_ = b.SetReadDeadline(time.Now().Add(5 * time.Millisecond))
+	_ = a.SetWriteDeadline(time.Now().Add(5 * time.Millisecond))
+
+	// when
+	_, readErr := b.Read(make([]byte, 1))
+	n, writeErr := a.Write([]byte("too long"))
+
+	// then
+	c.Assert(readErr, Equals, os.ErrDeadlineExceeded)
+	c.Assert(writeErr, Equals, os.ErrDeadlineExceeded)
+	c.Assert(n, Equals, 4)
+
+	// when the deadline extended while blocked
+	_ = b.SetReadDeadline(time.Time{})
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		_ = a.SetWriteDeadline(time.Time{})
+	}()
+	buf := make([]byte, 4)
+	n, err := b.Read(buf)
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(string(buf[:n]), Equals, "too ")
+}
@@ -0,0 +1,123 @@
+package lfring
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+)
+
+// ErrPointers is returned by NewPOD when T contains pointers.
+var ErrPointers = errors.New("lfring: type contains pointers")
+
+// ErrCapacity is returned by NewPODOver when the storage length is not power-of-two.
+var ErrCapacity = errors.New("lfring: capacity is not power-of-two")
+
+// POD is a single-producer single-consumer ring buffer of pointer-free values (plain old
+// data, e.g. numbers, arrays and structs of them), which unlocks what's unsafe for the
+// pointerful T: values are moved by bulk copy rather than one by one, and the storage can
+// be any memory, e.g. off-heap or a shared-memory mapping, since the garbage collector
+// doesn't need to scan it (see NewPODOver).
+//
+// Go can't express "pointer-free" as a constraint, so the constructors check T at runtime.
+// Write must be called by only one producer and Read by only one consumer.
+type POD[T any] struct {
+	head      uint64
+	_padding0 [56]byte
+	tail      uint64
+	_padding1 [56]byte
+	mask      uint64
+	storage   []T
+}
+
+// NewPOD build a POD with capacity, expands to power-of-two as New does, returns ErrPointers
+// if T contains pointers.
+func NewPOD[T any](capacity uint64) (*POD[T], error) {
+	return NewPODOver(make([]T, findPowerOfTwo(capacity)))
+}
+
+// NewPODOver build a POD over storage (e.g. unsafe.Slice over a mmap region), whose length
+// must be power-of-two. It returns ErrPointers if T contains pointers.
+func NewPODOver[T any](storage []T) (*POD[T], error) {
+	if hasPointers(reflect.TypeFor[T]()) {
+		return nil, ErrPointers
+	}
+	if len(storage) == 0 || len(storage)&(len(storage)-1) != 0 {
+		return nil, ErrCapacity
+	}
+
+	return &POD[T]{
+		mask:    uint64(len(storage)) - 1,
+		storage: storage,
+	}, nil
+}
+
+// hasPointers reports whether values of t contain any pointer.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// Write copies values from src as many as buffer can hold, returns how many are written.
+func (r *POD[T]) Write(src []T) (n int) {
+	tail := r.tail
+	head := atomic.LoadUint64(&r.head)
+	n = min(len(src), int(r.mask+1-(tail-head)))
+	if n == 0 {
+		return 0
+	}
+
+	r.copyIn(tail, src[:n])
+	atomic.StoreUint64(&r.tail, tail+uint64(n))
+	return n
+}
+
+// copyIn copies src to the storage from seq, wraps around the end of storage.
+func (r *POD[T]) copyIn(seq uint64, src []T) {
+	copied := copy(r.storage[seq&r.mask:], src)
+	copy(r.storage, src[copied:])
+}
+
+// Read copies values to dst as many as buffer has, returns how many are read.
+func (r *POD[T]) Read(dst []T) (n int) {
+	head := r.head
+	tail := atomic.LoadUint64(&r.tail)
+	n = min(len(dst), int(tail-head))
+	if n == 0 {
+		return 0
+	}
+
+	r.copyOut(head, dst[:n])
+	atomic.StoreUint64(&r.head, head+uint64(n))
+	return n
+}
+
+// copyOut copies the storage from seq to dst, wraps around the end of storage.
+func (r *POD[T]) copyOut(seq uint64, dst []T) {
+	copied := copy(dst, r.storage[seq&r.mask:])
+	copy(dst[copied:], r.storage)
+}
+
+// Len returns the number of values in buffer.
+func (r *POD[T]) Len() uint64 {
+	return occupancy(r.mask+1, atomic.LoadUint64(&r.head), atomic.LoadUint64(&r.tail))
+}
+
+// Cap returns the real (power-of-two) capacity.
+func (r *POD[T]) Cap() uint64 {
+	return r.mask + 1
+}
@@ -0,0 +1,85 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+)
+
+type podPoint struct {
+	X, Y int32
+	Tags [2]uint8
+}
+
+func (s *MySuite) TestNewPODRejectsPointers(c *C) {
+	_, err := NewPOD[*int](4)
+	c.Assert(err, Equals, ErrPointers)
+	_, err = NewPOD[string](4)
+	c.Assert(err, Equals, ErrPointers)
+	_, err = NewPOD[struct{ B []byte }](4)
+	c.Assert(err, Equals, ErrPointers)
+	_, err = NewPOD[podPoint](4)
+	c.Assert(err, IsNil)
+	_, err = NewPODOver(make([]int, 3))
+	c.Assert(err, Equals, ErrCapacity)
+}
+
+func (s *MySuite) TestPODBulkWriteAndReadAcrossWrap(c *C) {
+	// given
+	buffer, _ := NewPOD[podPoint](4)
+	dst := make([]podPoint, 4)
+
+	// when
+	written := buffer.Write([]podPoint{{X: 1}, {X: 2}, {X: 3}})
+	read := buffer.Read(dst[:2])
+	wrapped := buffer.Write([]podPoint{{X: 4}, {X: 5}, {X: 6}, {X: 7}})
+
+	// then
+	c.Assert(written, Equals, 3)
+	c.Assert(read, Equals, 2)
+	c.Assert(wrapped, Equals, 3)
+	c.Assert(buffer.Len(), Equals, uint64(4))
+	c.Assert(buffer.Read(dst), Equals, 4)
+	c.Assert(dst, DeepEquals, []podPoint{{X: 3}, {X: 4}, {X: 5}, {X: 6}})
+	c.Assert(buffer.Read(dst), Equals, 0)
+}
+
+func (s *MySuite) TestPODConcurrentProducerAndConsumer(c *C) {
+	// given
+	buffer, _ := NewPOD[uint64](16)
+	const total = 10000
+	done := make(chan bool)
+
+	// when
+	go func() {
+		src := make([]uint64, 7)
+		for next := uint64(0); next < total; {
+			n := min(len(src), int(total-next))
+			for i := 0; i < n; i++ {
+				src[i] = next + uint64(i)
+			}
+			written := buffer.Write(src[:n])
+			if written == 0 {
+				runtime.Gosched()
+			}
+			next += uint64(written)
+		}
+	}()
+	go func() {
+		dst := make([]uint64, 5)
+		ordered := true
+		for next := uint64(0); next < total; {
+			n := buffer.Read(dst)
+			if n == 0 {
+				runtime.Gosched()
+			}
+			for _, v := range dst[:n] {
+				ordered = ordered && v == next
+				next++
+			}
+		}
+		done <- ordered
+	}()
+
+	// then
+	c.Assert(<-done, Equals, true)
+}
@@ -0,0 +1,97 @@
+package lfring
+
+import (
+	"runtime"
+)
+
+// TransactionalConsumer is implemented by the buffers can poll a value by a transaction, namely
+// NodeBased and FetchAdd, so whether a value is consumed at-most-once or at-least-once is up to
+// the caller per value:
+//
+//	tx, ok := buffer.(lfring.TransactionalConsumer[Job]).BeginPoll()
+//	if ok {
+//		if err := handle(tx.Value()); err != nil {
+//			tx.Abort()
+//		} else {
+//			tx.Commit()
+//		}
+//	}
+type TransactionalConsumer[T any] interface {
+	// BeginPoll claims head value without releasing its slot, return false if buffer is empty
+	// or the claim lost in contention. Other consumers go on with the values behind, producers
+	// can't reuse the slot until the transaction ends (the overshooting FetchAdd producers
+	// wait for it), so it should end promptly.
+	BeginPoll() (tx *PollTx[T], success bool)
+}
+
+// PollTx is a value claimed by BeginPoll, it must be ended by either Commit or Abort once, the
+// later calls do nothing.
+type PollTx[T any] struct {
+	owner pollTxOwner[T]
+	head  uint64
+	value T
+	ended bool
+}
+
+type pollTxOwner[T any] interface {
+	commitPoll(head uint64)
+	abortPoll(head uint64) bool
+}
+
+// Value returns the claimed value.
+func (tx *PollTx[T]) Value() T {
+	return tx.value
+}
+
+// Commit consumes the value and releases its slot to producers.
+func (tx *PollTx[T]) Commit() {
+	if tx.ended {
+		return
+	}
+	tx.ended = true
+	tx.owner.commitPoll(tx.head)
+}
+
+// Abort puts the value back in place, so it's the next to poll. Return false if other
+// consumers have polled the values behind meanwhile, then it can't be put back in place, the
+// transaction is committed instead and the value is left to the caller (e.g. Requeue it).
+func (tx *PollTx[T]) Abort() (success bool) {
+	if tx.ended {
+		return false
+	}
+	tx.ended = true
+	if tx.owner.abortPoll(tx.head) {
+		return true
+	}
+	tx.owner.commitPoll(tx.head)
+	return false
+}
+
+// Transfer moves head value of src to dst, return false if src is empty, the claim lost in
+// contention, or dst is full, then the value is left in src. The value is offered to dst
+// before its slot released in src, so there's no moment it exists in neither, and a crashed
+// stage of pipeline can't lose it in between.
+//
+// If other consumers of src have polled meanwhile, the value can't be put back to src in
+// place, then Transfer waits for room in dst rather than drop it.
+func Transfer[T any](src TransactionalConsumer[T], dst Producer[T]) (success bool) {
+	tx, success := src.BeginPoll()
+	if !success {
+		return false
+	}
+	if dst.Offer(tx.Value()) {
+		tx.Commit()
+		return true
+	}
+	if tx.owner.abortPoll(tx.head) {
+		tx.ended = true
+		return false
+	}
+
+	for !dst.Offer(tx.Value()) {
+		cpuRelax()
+		runtime.Gosched()
+	}
+	tx.Commit()
+	return true
+}
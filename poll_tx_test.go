@@ -0,0 +1,163 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+)
+
+func (s *MySuite) TestPollTxCommit(c *C) {
+	// given
+	buffer := New[int](NodeBased, 2)
+	buffer.Offer(1)
+	buffer.Offer(2)
+
+	// when
+	tx, success := buffer.(TransactionalConsumer[int]).BeginPoll()
+	full := !buffer.Offer(3)
+	next, _ := buffer.Poll()
+	tx.Commit()
+	tx.Commit()
+
+	// then the slot is released by Commit only
+	c.Assert(success, Equals, true)
+	c.Assert(tx.Value(), Equals, 1)
+	c.Assert(full, Equals, true)
+	c.Assert(next, Equals, 2)
+	c.Assert(buffer.Offer(3), Equals, true)
+	c.Assert(buffer.Offer(4), Equals, true)
+}
+
+func (s *MySuite) TestPollTxFetchAdd(c *C) {
+	// given
+	buffer := New[int](FetchAdd, 2)
+	buffer.Offer(1)
+	buffer.Offer(2)
+	tx, _ := buffer.(TransactionalConsumer[int]).BeginPoll()
+	buffer.Poll()
+
+	// when the overshooting producer waits for the slot
+	offered := make(chan bool)
+	go func() {
+		offered <- buffer.Offer(3)
+	}()
+	tx.Commit()
+
+	// then
+	c.Assert(<-offered, Equals, true)
+	c.Assert(tx.Value(), Equals, 1)
+}
+
+func (s *MySuite) TestPollTxAbort(c *C) {
+	// given
+	buffer := New[int](NodeBased, 4)
+	buffer.Offer(1)
+	buffer.Offer(2)
+	consumer := buffer.(TransactionalConsumer[int])
+
+	// when
+	tx, _ := consumer.BeginPoll()
+	aborted := tx.Abort()
+	again, _ := buffer.Poll()
+
+	// then
+	c.Assert(aborted, Equals, true)
+	c.Assert(tx.Abort(), Equals, false)
+	c.Assert(again, Equals, 1)
+}
+
+func (s *MySuite) TestPollTxAbortAfterOthersPolled(c *C) {
+	// given
+	buffer := New[int](NodeBased, 4)
+	buffer.Offer(1)
+	buffer.Offer(2)
+	consumer := buffer.(TransactionalConsumer[int])
+	tx, _ := consumer.BeginPoll()
+	buffer.Poll()
+
+	// when
+	aborted := tx.Abort()
+
+	// then committed instead, the slot is released
+	c.Assert(aborted, Equals, false)
+	c.Assert(buffer.Len(), Equals, uint64(0))
+	for i := 0; i < 4; i++ {
+		c.Assert(buffer.Offer(i), Equals, true)
+	}
+}
+
+func (s *MySuite) TestPollTxEmpty(c *C) {
+	// given
+	buffer := New[int](NodeBased, 2)
+
+	// when
+	_, success := buffer.(TransactionalConsumer[int]).BeginPoll()
+
+	// then
+	c.Assert(success, Equals, false)
+}
+
+func (s *MySuite) TestTransfer(c *C) {
+	// given
+	src := New[int](NodeBased, 4)
+	dst := New[int](Classical, 2)
+	for i := 0; i < 3; i++ {
+		src.Offer(i)
+	}
+	consumer := src.(TransactionalConsumer[int])
+
+	// when dst is full after the first one
+	first := Transfer(consumer, dst)
+	second := Transfer(consumer, dst)
+
+	// then
+	c.Assert(first, Equals, true)
+	c.Assert(second, Equals, false)
+	c.Assert(src.Len(), Equals, uint64(2))
+	c.Assert(dst.Len(), Equals, uint64(1))
+	next, _ := src.Poll()
+	c.Assert(next, Equals, 1)
+	moved, _ := dst.Poll()
+	c.Assert(moved, Equals, 0)
+}
+
+func (s *MySuite) TestTransferConcurrency(c *C) {
+	// given
+	const total = 1000
+	src := New[int](NodeBased, 8)
+	dst := New[int](NodeBased, 8)
+	done := make(chan struct{})
+
+	// when a stage moves values from src to dst
+	go func() {
+		defer close(done)
+		consumer := src.(TransactionalConsumer[int])
+		for moved := 0; moved < total; {
+			if Transfer(consumer, dst) {
+				moved++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	go func() {
+		for i := 0; i < total; {
+			if src.Offer(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	// then every value arrives in order
+	for i := 0; i < total; {
+		v, ok := dst.Poll()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		c.Assert(v, Equals, i)
+		i++
+	}
+	<-done
+}
@@ -0,0 +1,269 @@
+package lfring
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Priority is a bounded priority queue of layered rings: every priority level is a NodeBased
+// ring, Offer goes to the ring of its level, Poll scans from the highest level and returns
+// the first value found. Values of the same level are FIFO. Both sides stay lock-free, so it
+// replaces the heap plus mutex of a job scheduler when the levels are a few.
+//
+// Priority 0 is the highest. A lower level is served only if all the higher ones are empty,
+// a busy high level starves the lower ones, unless WithStarvationQuantum: then a waiting level
+// is served once after the quantum of values of the higher levels passed it.
+//
+// Every level counts its offers, drops and polls, see Stats. The levels built WithEnqueueTime
+// measure the latency (enqueue to poll) too.
+//
+// Priority implements Consumer, so it works with the consuming helpers (e.g. Consume).
+type Priority[T any] struct {
+	levels  []RingBuffer[T]
+	stats   []priorityCounters
+	quantum uint64
+	timed   bool
+}
+
+type priorityCounters struct {
+	offered    uint64
+	dropped    uint64
+	polled     uint64
+	promoted   uint64
+	passed     uint64
+	latency    int64
+	maxLatency int64
+}
+
+// PriorityStats is a snapshot of the counters of a level of Priority.
+type PriorityStats struct {
+	Priority int    `json:"priority"`
+	Len      uint64 `json:"len"`
+	Offered  uint64 `json:"offered"`
+	// Dropped counts the offers failed, the level was full or the claim lost in contention
+	Dropped uint64 `json:"dropped"`
+	Polled  uint64 `json:"polled"`
+	// Promoted counts the values polled by the quantum ahead of a higher level
+	Promoted uint64 `json:"promoted"`
+	// MeanLatency and MaxLatency are zero unless built WithEnqueueTime
+	MeanLatency time.Duration `json:"mean_latency"`
+	MaxLatency  time.Duration `json:"max_latency"`
+}
+
+// NewPriority build a Priority of levels (at least 1) levels, each is a NodeBased ring of
+// capacity built with opts, see WithStarvationQuantum for the anti-starvation.
+func NewPriority[T any](levels int, capacity uint64, opts ...Option) *Priority[T] {
+	if levels < 1 {
+		levels = 1
+	}
+
+	c := newConfig(opts)
+	p := &Priority[T]{
+		levels:  make([]RingBuffer[T], levels),
+		stats:   make([]priorityCounters, levels),
+		quantum: c.quantum,
+		timed:   c.enqueueTime,
+	}
+	for i := range p.levels {
+		p.levels[i] = New[T](NodeBased, capacity, opts...)
+	}
+	return p
+}
+
+// Offer value at priority, return false if the level is full or the claim lost in
+// contention. It panics if priority is out of [0, Levels).
+func (p *Priority[T]) Offer(priority int, value T) (success bool) {
+	if success = p.levels[priority].Offer(value); success {
+		atomic.AddUint64(&p.stats[priority].offered, 1)
+	} else {
+		atomic.AddUint64(&p.stats[priority].dropped, 1)
+	}
+	return
+}
+
+// Poll the value of the highest non-empty level.
+func (p *Priority[T]) Poll() (value T, success bool) {
+	value, _, success = p.PollPriority()
+	return
+}
+
+// PollPriority is Poll along with the priority of value.
+func (p *Priority[T]) PollPriority() (value T, priority int, success bool) {
+	if p.quantum > 0 {
+		// the lowest starving level first
+		for i := len(p.levels) - 1; i > 0; i-- {
+			if atomic.LoadUint64(&p.stats[i].passed) < p.quantum {
+				continue
+			}
+			atomic.StoreUint64(&p.stats[i].passed, 0)
+			if value, success = p.poll(i); success {
+				atomic.AddUint64(&p.stats[i].promoted, 1)
+				return value, i, true
+			}
+		}
+	}
+
+	for i := range p.levels {
+		if value, success = p.poll(i); success {
+			if p.quantum > 0 {
+				p.pass(i)
+			}
+			return value, i, true
+		}
+	}
+	return
+}
+
+func (p *Priority[T]) poll(priority int) (value T, success bool) {
+	stats := &p.stats[priority]
+	if !p.timed {
+		if value, success = p.levels[priority].Poll(); success {
+			atomic.AddUint64(&stats.polled, 1)
+		}
+		return
+	}
+
+	value, enqueued, success := p.levels[priority].(TimedConsumer[T]).PollTimed()
+	if !success {
+		return
+	}
+	atomic.AddUint64(&stats.polled, 1)
+	latency := int64(time.Since(enqueued))
+	atomic.AddInt64(&stats.latency, latency)
+	for prev := atomic.LoadInt64(&stats.maxLatency); latency > prev; prev = atomic.LoadInt64(&stats.maxLatency) {
+		if atomic.CompareAndSwapInt64(&stats.maxLatency, prev, latency) {
+			break
+		}
+	}
+	return
+}
+
+// pass counts a value of priority polled ahead of the lower levels waiting.
+func (p *Priority[T]) pass(priority int) {
+	for i := priority + 1; i < len(p.levels); i++ {
+		if p.levels[i].Len() > 0 {
+			atomic.AddUint64(&p.stats[i].passed, 1)
+		}
+	}
+}
+
+// perValue tells whether the batches must be polled value by value, for the quantum or the
+// latency.
+func (p *Priority[T]) perValue() bool {
+	return p.quantum > 0 || p.timed
+}
+
+func (p *Priority[T]) pollEach(dst []T) (count uint64) {
+	for count < uint64(len(dst)) {
+		v, ok := p.Poll()
+		if !ok {
+			break
+		}
+		dst[count] = v
+		count++
+	}
+	return count
+}
+
+// PollNBatched polls at most n values, see PollBatchInto.
+func (p *Priority[T]) PollNBatched(n uint64) (values []T, count uint64) {
+	if n == 0 {
+		return nil, 0
+	}
+
+	values = make([]T, n)
+	count = p.PollBatchInto(values)
+	return values[:count], count
+}
+
+// PollBatchInto fills dst with the values from the highest level down, returns how many
+// values are filled.
+func (p *Priority[T]) PollBatchInto(dst []T) (count uint64) {
+	if p.perValue() {
+		return p.pollEach(dst)
+	}
+	for i, level := range p.levels {
+		if count == uint64(len(dst)) {
+			break
+		}
+		n := level.PollBatchInto(dst[count:])
+		atomic.AddUint64(&p.stats[i].polled, n)
+		count += n
+	}
+	return count
+}
+
+// SingleConsumerPoll passes every value to valueConsumer from the highest level down, the
+// caller must be the only consumer.
+func (p *Priority[T]) SingleConsumerPoll(valueConsumer func(T)) {
+	if p.perValue() {
+		for v, ok := p.Poll(); ok; v, ok = p.Poll() {
+			valueConsumer(v)
+		}
+		return
+	}
+	for i, level := range p.levels {
+		var n uint64
+		level.SingleConsumerPoll(func(v T) {
+			n++
+			valueConsumer(v)
+		})
+		atomic.AddUint64(&p.stats[i].polled, n)
+	}
+}
+
+// SingleConsumerPollVec fills ret with the values from the highest level down, the caller
+// must be the only consumer.
+func (p *Priority[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
+	if p.perValue() {
+		return p.pollEach(ret)
+	}
+	for i, level := range p.levels {
+		if validCnt == uint64(len(ret)) {
+			break
+		}
+		n := level.SingleConsumerPollVec(ret[validCnt:])
+		atomic.AddUint64(&p.stats[i].polled, n)
+		validCnt += n
+	}
+	return validCnt
+}
+
+// Len returns the approximate number of values of all levels.
+func (p *Priority[T]) Len() (n uint64) {
+	for _, level := range p.levels {
+		n += level.Len()
+	}
+	return n
+}
+
+// LevelLen returns the approximate number of values at priority.
+func (p *Priority[T]) LevelLen(priority int) uint64 {
+	return p.levels[priority].Len()
+}
+
+// Levels returns the number of levels.
+func (p *Priority[T]) Levels() int {
+	return len(p.levels)
+}
+
+// Stats returns the counters of every level, from the highest down.
+func (p *Priority[T]) Stats() []PriorityStats {
+	stats := make([]PriorityStats, len(p.levels))
+	for i := range p.levels {
+		counters := &p.stats[i]
+		stats[i] = PriorityStats{
+			Priority:   i,
+			Len:        p.levels[i].Len(),
+			Offered:    atomic.LoadUint64(&counters.offered),
+			Dropped:    atomic.LoadUint64(&counters.dropped),
+			Polled:     atomic.LoadUint64(&counters.polled),
+			Promoted:   atomic.LoadUint64(&counters.promoted),
+			MaxLatency: time.Duration(atomic.LoadInt64(&counters.maxLatency)),
+		}
+		if p.timed && stats[i].Polled > 0 {
+			stats[i].MeanLatency = time.Duration(atomic.LoadInt64(&counters.latency) / int64(stats[i].Polled))
+		}
+	}
+	return stats
+}
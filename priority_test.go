@@ -0,0 +1,94 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestPriorityPollsHighestFirst(c *C) {
+	// given
+	p := NewPriority[string](3, 4)
+	p.Offer(2, "low")
+	p.Offer(1, "mid")
+	p.Offer(0, "high")
+	p.Offer(1, "mid2")
+
+	// when
+	var values []string
+	var priorities []int
+	for v, priority, ok := p.PollPriority(); ok; v, priority, ok = p.PollPriority() {
+		values = append(values, v)
+		priorities = append(priorities, priority)
+	}
+
+	// then
+	c.Assert(values, DeepEquals, []string{"high", "mid", "mid2", "low"})
+	c.Assert(priorities, DeepEquals, []int{0, 1, 1, 2})
+	c.Assert(p.Levels(), Equals, 3)
+}
+
+func (s *MySuite) TestPriorityBatch(c *C) {
+	// given
+	p := NewPriority[int](2, 4)
+	for i := 0; i < 3; i++ {
+		p.Offer(1, 10+i)
+		p.Offer(0, i)
+	}
+
+	// when
+	batch := make([]int, 4)
+	n := p.SingleConsumerPollVec(batch)
+	rest, count := p.PollNBatched(4)
+
+	// then
+	c.Assert(batch[:n], DeepEquals, []int{0, 1, 2, 10})
+	c.Assert(rest[:count], DeepEquals, []int{11, 12})
+	c.Assert(p.Len(), Equals, uint64(0))
+	c.Assert(p.LevelLen(0), Equals, uint64(0))
+}
+
+func (s *MySuite) TestPriorityStarvationQuantum(c *C) {
+	// given a busy high level
+	p := NewPriority[string](3, 8, WithStarvationQuantum(2))
+	for _, v := range []string{"h1", "h2", "h3", "h4", "h5"} {
+		p.Offer(0, v)
+	}
+	p.Offer(1, "mid")
+	p.Offer(2, "low")
+
+	// when
+	dst := make([]string, 8)
+	count := p.SingleConsumerPollVec(dst)
+
+	// then the waiting levels are served once per quantum
+	c.Assert(dst[:count], DeepEquals, []string{"h1", "h2", "low", "mid", "h3", "h4", "h5"})
+	stats := p.Stats()
+	c.Assert(stats[1].Promoted, Equals, uint64(1))
+	c.Assert(stats[2].Promoted, Equals, uint64(1))
+	c.Assert(stats[0].Promoted, Equals, uint64(0))
+}
+
+func (s *MySuite) TestPriorityStats(c *C) {
+	// given
+	p := NewPriority[int](2, 2, WithEnqueueTime())
+	p.Offer(1, 1)
+	p.Offer(1, 2)
+	p.Offer(1, 3)
+	p.Offer(0, 4)
+
+	// when
+	time.Sleep(5 * time.Millisecond)
+	p.Poll()
+	p.Poll()
+
+	// then
+	stats := p.Stats()
+	c.Assert(stats[0].Offered, Equals, uint64(1))
+	c.Assert(stats[0].Polled, Equals, uint64(1))
+	c.Assert(stats[1].Offered, Equals, uint64(2))
+	c.Assert(stats[1].Dropped, Equals, uint64(1))
+	c.Assert(stats[1].Polled, Equals, uint64(1))
+	c.Assert(stats[1].Len, Equals, uint64(1))
+	c.Assert(stats[1].MeanLatency >= 5*time.Millisecond, Equals, true)
+	c.Assert(stats[1].MaxLatency >= stats[1].MeanLatency, Equals, true)
+}
@@ -0,0 +1,152 @@
+package lfring
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Probe measures the end-to-end latency of a pipeline of rings by marker items: Mark at the
+// entry returns an id to be carried by a marker item, Observe at the exit with that id
+// records the latency. It works both in tests and as a lightweight canary in production,
+// injecting a marker every now and then alongside the real traffic.
+//
+// The latencies of the last window observed markers are kept for the percentiles.
+type Probe struct {
+	mu        sync.Mutex
+	next      uint64
+	inFlight  map[uint64]time.Time
+	latencies []time.Duration
+	observed  uint64
+}
+
+// LatencySummary is the percentiles of the latencies kept by Probe.
+type LatencySummary struct {
+	Count uint64        `json:"count"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// NewProbe build a Probe keeps the latencies of the last window markers.
+func NewProbe(window int) *Probe {
+	return &Probe{
+		inFlight:  make(map[uint64]time.Time),
+		latencies: make([]time.Duration, 0, max(window, 1)),
+	}
+}
+
+// Mark starts a marker, returns the id to be carried through the pipeline.
+func (p *Probe) Mark() (id uint64) {
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id = p.next
+	p.next++
+	p.inFlight[id] = now
+	return id
+}
+
+// Observe ends the marker of id, returns its latency, or false if id is unknown (e.g.
+// observed already).
+func (p *Probe) Observe(id uint64) (latency time.Duration, ok bool) {
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	start, ok := p.inFlight[id]
+	if !ok {
+		return 0, false
+	}
+	delete(p.inFlight, id)
+
+	latency = now.Sub(start)
+	if len(p.latencies) < cap(p.latencies) {
+		p.latencies = append(p.latencies, latency)
+	} else {
+		p.latencies[p.observed%uint64(cap(p.latencies))] = latency
+	}
+	p.observed++
+	return latency, true
+}
+
+// InFlight returns how many markers not observed yet, a growing number means markers get
+// lost (or stuck) in the pipeline.
+func (p *Probe) InFlight() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.inFlight)
+}
+
+// Percentile returns the q (in [0, 1]) percentile of the kept latencies.
+func (p *Probe) Percentile(q float64) time.Duration {
+	return percentile(p.sorted(), q)
+}
+
+// Summary returns the percentiles of the kept latencies, Count is the number of all the
+// observed markers.
+func (p *Probe) Summary() LatencySummary {
+	sorted := p.sorted()
+	p.mu.Lock()
+	count := p.observed
+	p.mu.Unlock()
+
+	summary := LatencySummary{Count: count}
+	if len(sorted) > 0 {
+		summary.P50 = percentile(sorted, 0.5)
+		summary.P90 = percentile(sorted, 0.9)
+		summary.P99 = percentile(sorted, 0.99)
+		summary.Max = sorted[len(sorted)-1]
+	}
+	return summary
+}
+
+func (p *Probe) sorted() []time.Duration {
+	p.mu.Lock()
+	sorted := slices.Clone(p.latencies)
+	p.mu.Unlock()
+	slices.Sort(sorted)
+	return sorted
+}
+
+// percentile picks the nearest rank of q from sorted.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(q*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// PingPong is a test harness of Probe: it sends n markers one by one into in, and waits
+// every marker comes out from out before sends the next, so the summary is the round trip
+// latency of an idle pipeline. marker builds the item carries id, markerID extracts it back,
+// items without id from out are ignored.
+func PingPong[In, Out any](
+	ctx context.Context,
+	in Sender[In],
+	out Receiver[Out],
+	n int,
+	marker func(id uint64) In,
+	markerID func(Out) (id uint64, ok bool),
+) (LatencySummary, error) {
+	probe := NewProbe(n)
+	for i := 0; i < n; i++ {
+		id := probe.Mark()
+		if err := in.Send(ctx, marker(id)); err != nil {
+			return probe.Summary(), err
+		}
+		for {
+			item, err := out.Recv(ctx)
+			if err != nil {
+				return probe.Summary(), err
+			}
+			if got, ok := markerID(item); ok && got == id {
+				probe.Observe(id)
+				break
+			}
+		}
+	}
+	return probe.Summary(), nil
+}
@@ -0,0 +1,71 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestProbeSummary(c *C) {
+	// given
+	probe := NewProbe(4)
+	start := time.Now()
+
+	// when
+	ids := []uint64{probe.Mark(), probe.Mark(), probe.Mark()}
+	for i, id := range ids {
+		probe.mu.Lock()
+		probe.inFlight[id] = start.Add(-time.Duration(i+1) * time.Second)
+		probe.mu.Unlock()
+	}
+	for _, id := range ids[:2] {
+		_, ok := probe.Observe(id)
+		c.Assert(ok, Equals, true)
+	}
+	_, ok := probe.Observe(ids[0])
+
+	// then
+	c.Assert(ok, Equals, false)
+	c.Assert(probe.InFlight(), Equals, 1)
+	summary := probe.Summary()
+	c.Assert(summary.Count, Equals, uint64(2))
+	c.Assert(summary.P50 >= time.Second && summary.P50 < 2*time.Second, Equals, true)
+	c.Assert(summary.Max >= 2*time.Second, Equals, true)
+	c.Assert(probe.Percentile(1), Equals, summary.Max)
+}
+
+func (s *MySuite) TestProbeKeepsWindow(c *C) {
+	// given
+	probe := NewProbe(2)
+
+	// when
+	for i := 0; i < 5; i++ {
+		probe.Observe(probe.Mark())
+	}
+
+	// then
+	c.Assert(len(probe.latencies), Equals, 2)
+	c.Assert(probe.Summary().Count, Equals, uint64(5))
+}
+
+func (s *MySuite) TestPingPongThroughPipeline(c *C) {
+	// given
+	in := NewChan[uint64](8)
+	out := NewChan[uint64](8)
+	go func() {
+		_ = RunConsumer[uint64](context.Background(), in, func(id uint64) error {
+			return out.Send(context.Background(), id)
+		})
+	}()
+
+	// when
+	summary, err := PingPong[uint64, uint64](context.Background(), in, out, 20,
+		func(id uint64) uint64 { return id },
+		func(id uint64) (uint64, bool) { return id, true })
+	_ = in.Close()
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(summary.Count, Equals, uint64(20))
+	c.Assert(summary.Max >= summary.P50, Equals, true)
+}
@@ -0,0 +1,153 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrAlreadyRunning is returned when start a processor that is running.
+var ErrAlreadyRunning = errors.New("lfring: processor is already running")
+
+// EventHandler handles a value polled by BatchEventProcessor. The sequence is the ordinal
+// of value seen by the processor (starts from 0), endOfBatch is true at the last value of
+// a batch which polled in one pass, it's a good point to flush buffered work.
+type EventHandler[T any] func(value T, sequence uint64, endOfBatch bool)
+
+// BatchEventProcessor owns a consumer loop of a RingBuffer: it drains values in batches,
+// hands them to the EventHandler one by one, and waits by WaitStrategy when buffer is empty.
+//
+// The processor consumes by SingleConsumerPollVec, so it must be the only consumer of the
+// buffer.
+type BatchEventProcessor[T any] struct {
+	name     string
+	trace    bool
+	buffer   Consumer[T]
+	handler  EventHandler[T]
+	wait     WaitStrategy
+	batch    []T
+	sequence uint64
+
+	mu   sync.Mutex
+	halt chan struct{}
+	done chan struct{}
+}
+
+// NewBatchEventProcessor build a processor that consumes buffer with handler, see
+// WithBatchSize and WithWaitStrategy for the options, WithName for the pprof labels and
+// WithTrace for the runtime/trace regions of the consumer loop.
+func NewBatchEventProcessor[T any](buffer Consumer[T], handler EventHandler[T], opts ...Option) *BatchEventProcessor[T] {
+	c := newConfig(opts)
+	return &BatchEventProcessor[T]{
+		name:    c.name,
+		trace:   c.trace,
+		buffer:  buffer,
+		handler: handler,
+		wait:    c.wait,
+		batch:   make([]T, c.batchSize),
+	}
+}
+
+// Start runs the consumer loop in a new goroutine.
+func (p *BatchEventProcessor[T]) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.halt != nil {
+		return ErrAlreadyRunning
+	}
+
+	p.halt = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.labeledRun(context.Background(), p.halt, p.done)
+	return nil
+}
+
+// Run runs the consumer loop in the calling goroutine until ctx done (returns ctx.Err()) or
+// Halt called (returns nil). The cancellation is checked between batches, so a busy loop
+// still stops within one batch.
+func (p *BatchEventProcessor[T]) Run(ctx context.Context) error {
+	p.mu.Lock()
+	if p.halt != nil {
+		p.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	halt := make(chan struct{})
+	p.halt = halt
+	p.done = make(chan struct{})
+	done := p.done
+	p.mu.Unlock()
+
+	p.labeledRun(ctx, halt, done)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// not halted by Halt
+	if p.halt == halt {
+		p.halt = nil
+		p.done = nil
+	}
+	return ctx.Err()
+}
+
+// Halt stops the consumer loop and waits until the loop exits, values not drained yet are
+// left in buffer. The processor can be started again after Halt.
+func (p *BatchEventProcessor[T]) Halt() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.halt == nil {
+		return
+	}
+
+	close(p.halt)
+	<-p.done
+	p.halt = nil
+	p.done = nil
+}
+
+// IsRunning reports whether the consumer loop is running.
+func (p *BatchEventProcessor[T]) IsRunning() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.halt != nil
+}
+
+func (p *BatchEventProcessor[T]) labeledRun(ctx context.Context, halt <-chan struct{}, done chan<- struct{}) {
+	labeled(ctx, p.name, RoleConsumer, func(ctx context.Context) {
+		p.run(ctx, halt, done)
+	})
+}
+
+func (p *BatchEventProcessor[T]) run(ctx context.Context, halt <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	cancel := ctx.Done()
+	ctx, task := newTask(p.trace, ctx, TaskProcessor)
+	defer endTask(task)
+
+	attempt := 0
+	for {
+		select {
+		case <-halt:
+			return
+		case <-cancel:
+			return
+		default:
+		}
+
+		validCnt := p.buffer.SingleConsumerPollVec(p.batch)
+		if validCnt == 0 {
+			attempt++
+			region := startRegion(p.trace, ctx, RegionWaitForValues)
+			p.wait.Wait(attempt)
+			endRegion(region)
+			continue
+		}
+
+		attempt = 0
+		region := startRegion(p.trace, ctx, RegionConsumeBatch)
+		for i := uint64(0); i < validCnt; i++ {
+			p.handler(p.batch[i], p.sequence, i == validCnt-1)
+			p.sequence++
+		}
+		endRegion(region)
+	}
+}
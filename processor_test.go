@@ -0,0 +1,125 @@
+package lfring
+
+import (
+	"context"
+	. "gopkg.in/check.v1"
+	"runtime"
+	"sync/atomic"
+)
+
+func (s *MySuite) TestBatchEventProcessorHandleInOrder(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 16)
+		received := make([]int, 0, 100)
+		var sequences []uint64
+		var handled int64
+		var batchEnds int64
+		processor := NewBatchEventProcessor[int](buffer, func(v int, seq uint64, endOfBatch bool) {
+			received = append(received, v)
+			sequences = append(sequences, seq)
+			if endOfBatch {
+				atomic.AddInt64(&batchEnds, 1)
+			}
+			atomic.AddInt64(&handled, 1)
+		}, WithBatchSize(4))
+
+		// when
+		c.Assert(processor.Start(), IsNil)
+		for i := 0; i < 100; i++ {
+			for !buffer.Offer(i) {
+				runtime.Gosched()
+			}
+		}
+		for atomic.LoadInt64(&handled) < 100 {
+			runtime.Gosched()
+		}
+		processor.Halt()
+
+		// then
+		c.Assert(processor.IsRunning(), Equals, false)
+		c.Assert(atomic.LoadInt64(&batchEnds) >= 25, Equals, true)
+		for i := 0; i < 100; i++ {
+			c.Assert(received[i], Equals, i)
+			c.Assert(sequences[i], Equals, uint64(i))
+		}
+	}
+}
+
+func (s *MySuite) TestBatchEventProcessorStartTwice(c *C) {
+	// given
+	buffer := New[int](NodeBased, 4)
+	processor := NewBatchEventProcessor[int](buffer, func(int, uint64, bool) {}, WithWaitStrategy(BusySpinWait()))
+
+	// when
+	first := processor.Start()
+	second := processor.Start()
+	processor.Halt()
+	third := processor.Start()
+	processor.Halt()
+
+	// then
+	c.Assert(first, IsNil)
+	c.Assert(second, Equals, ErrAlreadyRunning)
+	c.Assert(third, IsNil)
+}
+
+func (s *MySuite) TestBatchEventProcessorRunUntilCancelled(c *C) {
+	// given
+	buffer := New[int](NodeBased, 8)
+	ctx, cancel := context.WithCancel(context.Background())
+	var handled int64
+	processor := NewBatchEventProcessor[int](buffer, func(int, uint64, bool) {
+		atomic.AddInt64(&handled, 1)
+	})
+	result := make(chan error)
+
+	// when
+	go func() { result <- processor.Run(ctx) }()
+	for i := 0; i < 8; i++ {
+		for !buffer.Offer(i) {
+			runtime.Gosched()
+		}
+	}
+	for atomic.LoadInt64(&handled) < 8 {
+		runtime.Gosched()
+	}
+	cancel()
+
+	// then
+	c.Assert(<-result, Equals, context.Canceled)
+	c.Assert(processor.IsRunning(), Equals, false)
+}
+
+func (s *MySuite) TestSingleConsumerPollContextStopWhenBusy(c *C) {
+	for _, t := range bufferSet {
+		// given
+		buffer := New[int](t, 64)
+		for i := 0; i < 32; i++ {
+			buffer.Offer(i)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		handled := 0
+
+		// when
+		err := SingleConsumerPollContext[int](ctx, buffer, func(int) {
+			handled++
+			// keep buffer busy
+			buffer.Offer(handled)
+			if handled == 10 {
+				cancel()
+			}
+		}, WithBatchSize(4))
+
+		// then
+		c.Assert(err, Equals, context.Canceled)
+		c.Assert(handled <= 12, Equals, true)
+
+		// when
+		drained := SingleConsumerPollContext[int](context.Background(), buffer, func(int) {})
+
+		// then
+		c.Assert(drained, IsNil)
+		c.Assert(buffer.Len(), Equals, uint64(0))
+	}
+}
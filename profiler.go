@@ -0,0 +1,114 @@
+package lfring
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// histogramBuckets is the number of power-of-two buckets of histogram, the last one also
+// counts everything larger.
+const histogramBuckets = 32
+
+// histogram counts values by power-of-two buckets, bucket i counts the values in
+// [2^(i-1), 2^i), bucket 0 counts zeros.
+type histogram struct {
+	buckets [histogramBuckets]uint64
+}
+
+func (h *histogram) record(v uint64) {
+	i := bits.Len64(v)
+	if i >= histogramBuckets {
+		i = histogramBuckets - 1
+	}
+	atomic.AddUint64(&h.buckets[i], 1)
+}
+
+func (h *histogram) snapshot() []Bucket {
+	var buckets []Bucket
+	for i := range h.buckets {
+		count := atomic.LoadUint64(&h.buckets[i])
+		if count == 0 {
+			continue
+		}
+		upper := uint64(1)<<i - 1
+		if i == histogramBuckets-1 {
+			upper = math.MaxUint64
+		}
+		buckets = append(buckets, Bucket{UpperBound: upper, Count: count})
+	}
+	return buckets
+}
+
+// Bucket is a bucket of histogram, counts the values not larger than UpperBound (and larger
+// than the UpperBound of previous bucket).
+type Bucket struct {
+	UpperBound uint64 `json:"le"`
+	Count      uint64 `json:"count"`
+}
+
+// OpProfile is the histograms of an Op: how many attempts until success, and how long (in
+// nanoseconds) since the first attempt.
+type OpProfile struct {
+	Attempts []Bucket `json:"attempts"`
+	Latency  []Bucket `json:"latency_ns"`
+}
+
+// Profile is a snapshot of Profiler.
+type Profile struct {
+	Offer OpProfile `json:"offer"`
+	Poll  OpProfile `json:"poll"`
+}
+
+// Profiler histograms the retries of the blocking calls, Offer and Poll separately. An
+// attempt fails when buffer is full / empty, or lost the CAS to other producers / consumers,
+// so a profile that mostly succeeds at the first attempt but takes long to, or has a long
+// tail of attempts under a half full buffer, is a sign of contention: consider the Relaxed
+// buffer, ConsumeBatches or fewer goroutines.
+//
+// It's opt-in by WithProfiler, a Blocking without profiler doesn't pay for the time reads.
+type Profiler struct {
+	offerAttempts histogram
+	offerLatency  histogram
+	pollAttempts  histogram
+	pollLatency   histogram
+}
+
+// NewProfiler build an empty Profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{}
+}
+
+// Profile returns the histograms recorded so far, empty buckets are omitted.
+func (p *Profiler) Profile() Profile {
+	return Profile{
+		Offer: OpProfile{Attempts: p.offerAttempts.snapshot(), Latency: p.offerLatency.snapshot()},
+		Poll:  OpProfile{Attempts: p.pollAttempts.snapshot(), Latency: p.pollLatency.snapshot()},
+	}
+}
+
+func (p *Profiler) record(op Op, attempts uint64, latency time.Duration) {
+	switch op {
+	case OpOffer:
+		p.offerAttempts.record(attempts)
+		p.offerLatency.record(uint64(latency))
+	case OpPoll:
+		p.pollAttempts.record(attempts)
+		p.pollLatency.record(uint64(latency))
+	}
+}
+
+// profile wraps try to count the attempts, records them once try succeeds.
+func (p *Profiler) profile(op Op, try func() bool) func() bool {
+	start := time.Now()
+	attempts := uint64(0)
+	return func() bool {
+		attempts++
+		if !try() {
+			return false
+		}
+		p.record(op, attempts, time.Since(start))
+		return true
+	}
+}
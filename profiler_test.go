@@ -0,0 +1,50 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"math"
+	"time"
+)
+
+func (s *MySuite) TestHistogramBuckets(c *C) {
+	// given
+	var h histogram
+
+	// when
+	h.record(0)
+	h.record(1)
+	h.record(3)
+	h.record(3)
+	h.record(math.MaxUint64)
+
+	// then
+	c.Assert(h.snapshot(), DeepEquals, []Bucket{
+		{UpperBound: 0, Count: 1},
+		{UpperBound: 1, Count: 1},
+		{UpperBound: 3, Count: 2},
+		{UpperBound: math.MaxUint64, Count: 1},
+	})
+}
+
+func (s *MySuite) TestProfilerRecordsBlockingRetries(c *C) {
+	// given
+	profiler := NewProfiler()
+	buffer := NewBlocking[int](New[int](NodeBased, 2), WithProfiler(profiler), WithSpinLimit(0))
+	buffer.Put(1)
+	buffer.Put(2)
+
+	// when
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		buffer.Take()
+	}()
+	buffer.Put(3)
+
+	// then
+	profile := profiler.Profile()
+	c.Assert(profile.Offer.Attempts[0], Equals, Bucket{UpperBound: 1, Count: 2})
+	c.Assert(len(profile.Offer.Attempts), Equals, 2)
+	c.Assert(profile.Offer.Attempts[1].UpperBound > 1, Equals, true)
+	c.Assert(profile.Poll.Attempts, DeepEquals, []Bucket{{UpperBound: 1, Count: 1}})
+	c.Assert(len(profile.Offer.Latency) > 0, Equals, true)
+}
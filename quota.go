@@ -0,0 +1,181 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// QuotaRing is a ring buffer shared by producer handles with quotas, so one chatty producer
+// of a multi-tenant service can't monopolize the ring: every handle may have at most
+// maxInFlight values offered but not polled yet. The values carry their handle through the
+// ring, so polling them gives back the quota, and every handle counts its own traffic.
+//
+// QuotaRing is the Consumer side, offer by the handles from Producer.
+type QuotaRing[T any] struct {
+	buffer RingBuffer[quotaItem[T]]
+	// the batch polls take it to poll into, and put it back after
+	scratch atomic.Pointer[[]quotaItem[T]]
+}
+
+type quotaItem[T any] struct {
+	value  T
+	handle *ProducerHandle[T]
+}
+
+// NewQuotaRing build a QuotaRing over a RingBuffer of BufferType, capacity and options.
+func NewQuotaRing[T any](t BufferType, capacity uint64, opts ...Option) *QuotaRing[T] {
+	return &QuotaRing[T]{buffer: New[quotaItem[T]](t, capacity, opts...)}
+}
+
+// Producer returns a new handle named name, maxInFlight zero means no quota.
+func (r *QuotaRing[T]) Producer(name string, maxInFlight uint64) *ProducerHandle[T] {
+	return &ProducerHandle[T]{ring: r, name: name, maxInFlight: maxInFlight}
+}
+
+// ProducerHandle offers values to a QuotaRing within its quota, it's safe for concurrent
+// use.
+type ProducerHandle[T any] struct {
+	ring        *QuotaRing[T]
+	name        string
+	maxInFlight uint64
+
+	inFlight      atomic.Uint64
+	offered       atomic.Uint64
+	polled        atomic.Uint64
+	rejectedQuota atomic.Uint64
+	rejectedFull  atomic.Uint64
+}
+
+// ProducerStats is a snapshot of the counters of a ProducerHandle.
+type ProducerStats struct {
+	Name          string `json:"name"`
+	MaxInFlight   uint64 `json:"max_in_flight"`
+	InFlight      uint64 `json:"in_flight"`
+	Offered       uint64 `json:"offered"`
+	Polled        uint64 `json:"polled"`
+	RejectedQuota uint64 `json:"rejected_quota"`
+	RejectedFull  uint64 `json:"rejected_full"`
+}
+
+// Offer a value, return false if the handle runs out of quota, buffer is full or the claim
+// lost in contention.
+func (h *ProducerHandle[T]) Offer(value T) (success bool) {
+	if h.inFlight.Add(1) > h.maxInFlight && h.maxInFlight > 0 {
+		h.inFlight.Add(^uint64(0))
+		h.rejectedQuota.Add(1)
+		return false
+	}
+
+	if !h.ring.buffer.Offer(quotaItem[T]{value: value, handle: h}) {
+		h.inFlight.Add(^uint64(0))
+		h.rejectedFull.Add(1)
+		return false
+	}
+	h.offered.Add(1)
+	return true
+}
+
+// Stats returns the counters of the handle.
+func (h *ProducerHandle[T]) Stats() ProducerStats {
+	return ProducerStats{
+		Name:          h.name,
+		MaxInFlight:   h.maxInFlight,
+		InFlight:      h.inFlight.Load(),
+		Offered:       h.offered.Load(),
+		Polled:        h.polled.Load(),
+		RejectedQuota: h.rejectedQuota.Load(),
+		RejectedFull:  h.rejectedFull.Load(),
+	}
+}
+
+func (h *ProducerHandle[T]) release() {
+	h.inFlight.Add(^uint64(0))
+	h.polled.Add(1)
+}
+
+func (r *QuotaRing[T]) unwrap(item quotaItem[T]) T {
+	item.handle.release()
+	return item.value
+}
+
+// Poll head value, gives back the quota of its handle.
+func (r *QuotaRing[T]) Poll() (value T, success bool) {
+	item, success := r.buffer.Poll()
+	if !success {
+		return
+	}
+	return r.unwrap(item), true
+}
+
+// PollNBatched polls at most n values, see PollBatchInto.
+func (r *QuotaRing[T]) PollNBatched(n uint64) (values []T, count uint64) {
+	if n == 0 {
+		return nil, 0
+	}
+
+	values = make([]T, n)
+	count = r.PollBatchInto(values)
+	return values[:count], count
+}
+
+// PollBatchInto fills dst with the head values, returns how many values are filled.
+func (r *QuotaRing[T]) PollBatchInto(dst []T) (count uint64) {
+	items := r.items(len(dst))
+	count = r.buffer.PollBatchInto(*items)
+	r.unwrapAll(dst, items, count)
+	return count
+}
+
+// items returns a scratch slice of n items, reused across the batch polls so they don't
+// allocate, give it back by unwrapAll. The concurrent batch polls find it taken, they
+// allocate one of their own.
+func (r *QuotaRing[T]) items(n int) *[]quotaItem[T] {
+	items := r.scratch.Swap(nil)
+	if items == nil || cap(*items) < n {
+		s := make([]quotaItem[T], n)
+		items = &s
+	}
+	*items = (*items)[:n]
+	return items
+}
+
+// unwrapAll unwraps the first count items into dst, then gives items back.
+func (r *QuotaRing[T]) unwrapAll(dst []T, items *[]quotaItem[T], count uint64) {
+	for i := uint64(0); i < count; i++ {
+		dst[i] = r.unwrap((*items)[i])
+	}
+	// don't keep the values and handles alive
+	clear((*items)[:count])
+	r.scratch.CompareAndSwap(nil, items)
+}
+
+// SingleConsumerPoll passes every value in buffer to valueConsumer, the caller must be the
+// only consumer.
+func (r *QuotaRing[T]) SingleConsumerPoll(valueConsumer func(T)) {
+	r.buffer.SingleConsumerPoll(func(item quotaItem[T]) {
+		valueConsumer(r.unwrap(item))
+	})
+}
+
+// SingleConsumerPollVec fills ret with values in buffer, the caller must be the only
+// consumer.
+func (r *QuotaRing[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
+	items := r.items(len(ret))
+	validCnt = r.buffer.SingleConsumerPollVec(*items)
+	r.unwrapAll(ret, items, validCnt)
+	return validCnt
+}
+
+// Len returns the approximate number of values in buffer.
+func (r *QuotaRing[T]) Len() uint64 {
+	return r.buffer.Len()
+}
+
+// Cap returns the real (power-of-two) capacity.
+func (r *QuotaRing[T]) Cap() uint64 {
+	return r.buffer.Cap()
+}
+
+// State returns a snapshot of the internal state.
+func (r *QuotaRing[T]) State() State {
+	return r.buffer.State()
+}
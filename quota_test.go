@@ -0,0 +1,74 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"testing"
+)
+
+func (s *MySuite) TestQuotaRingLimitsInFlight(c *C) {
+	// given
+	ring := NewQuotaRing[int](NodeBased, 8)
+	chatty := ring.Producer("chatty", 2)
+	quiet := ring.Producer("quiet", 0)
+
+	// when
+	results := []bool{chatty.Offer(1), chatty.Offer(2), chatty.Offer(3)}
+	c.Assert(quiet.Offer(10), Equals, true)
+
+	// then
+	c.Assert(results, DeepEquals, []bool{true, true, false})
+	c.Assert(chatty.Stats(), Equals, ProducerStats{Name: "chatty", MaxInFlight: 2, InFlight: 2, Offered: 2, RejectedQuota: 1})
+
+	// when polled, the quota is given back
+	c.Assert(MustPoll[int](ring), Equals, 1)
+
+	// then
+	c.Assert(chatty.Offer(4), Equals, true)
+	dst := make([]int, 8)
+	c.Assert(ring.SingleConsumerPollVec(dst), Equals, uint64(3))
+	c.Assert(dst[:3], DeepEquals, []int{2, 10, 4})
+	c.Assert(chatty.Stats().InFlight, Equals, uint64(0))
+	c.Assert(chatty.Stats().Polled, Equals, uint64(3))
+	c.Assert(quiet.Stats().Polled, Equals, uint64(1))
+}
+
+func (s *MySuite) TestQuotaRingCountsFull(c *C) {
+	// given
+	ring := NewQuotaRing[int](NodeBased, 2)
+	handle := ring.Producer("p", 0)
+	handle.Offer(1)
+	handle.Offer(2)
+
+	// when
+	offered := handle.Offer(3)
+
+	// then
+	c.Assert(offered, Equals, false)
+	c.Assert(handle.Stats().RejectedFull, Equals, uint64(1))
+	c.Assert(handle.Stats().InFlight, Equals, uint64(2))
+	values, count := ring.PollNBatched(4)
+	c.Assert(values, DeepEquals, []int{1, 2})
+	c.Assert(count, Equals, uint64(2))
+}
+
+func (s *MySuite) TestQuotaRingBatchPollsDontAllocate(c *C) {
+	// given
+	ring := NewQuotaRing[int](NodeBased, 8)
+	handle := ring.Producer("p", 0)
+	dst := make([]int, 4)
+	ring.SingleConsumerPollVec(dst)
+
+	// when
+	allocs := testing.AllocsPerRun(100, func() {
+		handle.Offer(1)
+		handle.Offer(2)
+		ring.PollBatchInto(dst)
+		handle.Offer(3)
+		ring.SingleConsumerPollVec(dst)
+	})
+
+	// then
+	c.Assert(allocs, Equals, float64(0))
+	c.Assert(dst[0], Equals, 3)
+	c.Assert(handle.Stats().InFlight, Equals, uint64(0))
+}
@@ -0,0 +1,69 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// Readiness wraps a RingBuffer and calls a "data available" callback on the offer that finds
+// the consumer armed, for the event-loop runtimes (gnet, netpoll and the like) that drain the
+// ring within their loop, rather than spend a goroutine parking on it. The callback is meant
+// to schedule the drain on the loop, e.g. the Wake of a gnet connection or a netpoll trigger:
+//
+//	ready := lfring.NewReadiness(buffer, func() { conn.Wake(nil) })
+//
+//	// in the loop, on wake up
+//	for {
+//		for v, ok := ready.Poll(); ok; v, ok = ready.Poll() {
+//			handle(v)
+//		}
+//		if !ready.Arm() {
+//			break
+//		}
+//	}
+//
+// The callback runs once per Arm, on the goroutine of the offer, so it must be quick and
+// never block. The other methods of the wrapped buffer are available as is.
+type Readiness[T any] struct {
+	RingBuffer[T]
+	onReadable func()
+	armed      int32
+}
+
+// NewReadiness wraps buffer with onReadable. The consumer starts armed, the first offer calls
+// back.
+func NewReadiness[T any](buffer RingBuffer[T], onReadable func()) *Readiness[T] {
+	return &Readiness[T]{RingBuffer: buffer, onReadable: onReadable, armed: 1}
+}
+
+// Arm asks for the callback on the next offer, then returns whether buffer has values
+// already, the consumer should drain again rather than wait then. As the callback may fire
+// for the values drained by that, a wake up on an empty buffer is expected.
+func (r *Readiness[T]) Arm() (ready bool) {
+	atomic.StoreInt32(&r.armed, 1)
+	return r.Len() > 0
+}
+
+// Offer a value, calls back if the consumer armed, return false if buffer is full or the
+// claim lost in contention.
+func (r *Readiness[T]) Offer(value T) (success bool) {
+	if !r.RingBuffer.Offer(value) {
+		return false
+	}
+	r.notify()
+	return true
+}
+
+// SingleProducerOffer offers values from valueSupplier until finish or buffer full, calls
+// back once after. The caller must be the only producer.
+func (r *Readiness[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	r.RingBuffer.SingleProducerOffer(valueSupplier)
+	if r.Len() > 0 {
+		r.notify()
+	}
+}
+
+func (r *Readiness[T]) notify() {
+	if atomic.LoadInt32(&r.armed) == 1 && atomic.SwapInt32(&r.armed, 0) == 1 {
+		r.onReadable()
+	}
+}
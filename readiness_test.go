@@ -0,0 +1,78 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+	"sync/atomic"
+)
+
+func (s *MySuite) TestReadiness(c *C) {
+	// given
+	var calls int
+	ready := NewReadiness(New[int](NodeBased, 4), func() { calls++ })
+
+	// when
+	ready.Offer(1)
+	ready.Offer(2)
+	callsBeforeArm := calls
+	ready.Poll()
+	stillReady := ready.Arm()
+	ready.Poll()
+	drained := ready.Arm()
+	ready.SingleProducerOffer(func() (int, bool) { return 0, true })
+	nothingOffered := calls
+	i := 0
+	ready.SingleProducerOffer(func() (int, bool) {
+		i++
+		return i, i > 2
+	})
+
+	// then called back once per arm
+	c.Assert(callsBeforeArm, Equals, 1)
+	c.Assert(stillReady, Equals, true)
+	c.Assert(drained, Equals, false)
+	c.Assert(nothingOffered, Equals, 1)
+	c.Assert(calls, Equals, 2)
+	c.Assert(ready.Len(), Equals, uint64(2))
+}
+
+func (s *MySuite) TestReadinessEventLoop(c *C) {
+	// given a loop woken by the callback
+	const total = 1000
+	wake := make(chan struct{}, 1)
+	ready := NewReadiness(New[int](NodeBased, 16), func() {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	})
+	var wakes int64
+
+	// when
+	go func() {
+		for i := 0; i < total; {
+			if ready.Offer(i) {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	// then every value is drained by the loop
+	next := 0
+	for next < total {
+		<-wake
+		atomic.AddInt64(&wakes, 1)
+		for {
+			for v, ok := ready.Poll(); ok; v, ok = ready.Poll() {
+				c.Assert(v, Equals, next)
+				next++
+			}
+			if !ready.Arm() {
+				break
+			}
+		}
+	}
+	c.Assert(atomic.LoadInt64(&wakes) <= total, Equals, true)
+}
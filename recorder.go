@@ -0,0 +1,177 @@
+package lfring
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Op is the kind of operation captured by Recorder.
+type Op uint8
+
+const (
+	// OpOffer is an Offer call
+	OpOffer Op = iota + 1
+	// OpPoll is a Poll call
+	OpPoll
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpOffer:
+		return "offer"
+	case OpPoll:
+		return "poll"
+	default:
+		return "op(" + strconv.Itoa(int(o)) + ")"
+	}
+}
+
+// Record is an operation captured by Recorder. Seq is the ordinal of the successful
+// operation of the same Op (starts from 0), failed operations don't have a Seq.
+type Record struct {
+	Op        Op
+	Success   bool
+	Seq       uint64
+	Goroutine uint64
+	Time      time.Time
+}
+
+func (r Record) String() string {
+	result := "failed"
+	if r.Success {
+		result = "seq=" + strconv.FormatUint(r.Seq, 10)
+	}
+	return fmt.Sprintf("%s goroutine=%d %s %s", r.Time.Format(time.RFC3339Nano), r.Goroutine, r.Op, result)
+}
+
+// Recorder is a flight recorder keeps the most recent operations in a Multicast ring, older
+// records are overwritten. It's meant for the post-mortem of rare failures, so it favors
+// detail (e.g. goroutine id) over speed, don't enable it on a hot path by default.
+type Recorder struct {
+	records *Multicast[Record]
+}
+
+// NewRecorder build a Recorder that keeps the last capacity (expands to power-of-two)
+// records.
+func NewRecorder(capacity uint64) *Recorder {
+	return &Recorder{records: NewMulticast[Record](capacity)}
+}
+
+// Record captures an operation of the calling goroutine.
+func (r *Recorder) Record(op Op, success bool, seq uint64) {
+	r.records.Offer(Record{
+		Op:        op,
+		Success:   success,
+		Seq:       seq,
+		Goroutine: goroutineID(),
+		Time:      time.Now(),
+	})
+}
+
+// Snapshot returns the retained records from the oldest to the newest.
+func (r *Recorder) Snapshot() []Record {
+	var records []Record
+	cursor := r.records.CursorFromEarliest()
+	for {
+		record, success := cursor.Next()
+		if !success {
+			return records
+		}
+		records = append(records, record)
+	}
+}
+
+// Dump writes the retained records to w, one record per line.
+func (r *Recorder) Dump(w io.Writer) error {
+	for _, record := range r.Snapshot() {
+		if _, err := fmt.Fprintln(w, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DumpOnPanic dumps records to w if the goroutine is panicking, then continues panicking.
+// It must be deferred directly:
+//
+//	defer recorder.DumpOnPanic(os.Stderr)
+func (r *Recorder) DumpOnPanic(w io.Writer) {
+	if p := recover(); p != nil {
+		_ = r.Dump(w)
+		panic(p)
+	}
+}
+
+// DumpOnSignal dumps records to w every time the process receives one of sigs (e.g.
+// syscall.SIGUSR1), until the returned stop is called.
+func (r *Recorder) DumpOnSignal(w io.Writer, sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = r.Dump(w)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// Recorded wraps buffer so that every Offer and Poll is captured by recorder, the other
+// methods go to buffer directly.
+func Recorded[T any](buffer RingBuffer[T], recorder *Recorder) RingBuffer[T] {
+	return &recorded[T]{RingBuffer: buffer, recorder: recorder}
+}
+
+type recorded[T any] struct {
+	RingBuffer[T]
+	recorder *Recorder
+	offered  atomic.Uint64
+	polled   atomic.Uint64
+}
+
+func (r *recorded[T]) Offer(value T) (success bool) {
+	success = r.RingBuffer.Offer(value)
+	r.recorder.Record(OpOffer, success, nextSeq(&r.offered, success))
+	return
+}
+
+func (r *recorded[T]) Poll() (value T, success bool) {
+	value, success = r.RingBuffer.Poll()
+	r.recorder.Record(OpPoll, success, nextSeq(&r.polled, success))
+	return
+}
+
+func nextSeq(counter *atomic.Uint64, success bool) uint64 {
+	if !success {
+		return 0
+	}
+	return counter.Add(1) - 1
+}
+
+// goroutineID parses id from the head of stack trace, "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i > 0 {
+		stack = stack[:i]
+	}
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+	return id
+}
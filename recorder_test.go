@@ -0,0 +1,48 @@
+package lfring
+
+import (
+	"bytes"
+	. "gopkg.in/check.v1"
+	"strings"
+)
+
+func (s *MySuite) TestRecorderCaptureRecentOperations(c *C) {
+	// given
+	recorder := NewRecorder(4)
+	buffer := Recorded[int](New[int](NodeBased, 2), recorder)
+
+	// when
+	buffer.Offer(1)
+	buffer.Offer(2)
+	buffer.Offer(3)
+	buffer.Poll()
+	buffer.Poll()
+
+	// then
+	records := recorder.Snapshot()
+	c.Assert(len(records), Equals, 4)
+	c.Assert(records[0].Op, Equals, OpOffer)
+	c.Assert(records[0].Seq, Equals, uint64(1))
+	c.Assert(records[1].Op, Equals, OpOffer)
+	c.Assert(records[1].Success, Equals, false)
+	c.Assert(records[3].Op, Equals, OpPoll)
+	c.Assert(records[3].Seq, Equals, uint64(1))
+	c.Assert(records[3].Goroutine, Not(Equals), uint64(0))
+}
+
+func (s *MySuite) TestRecorderDumpOnPanic(c *C) {
+	// given
+	recorder := NewRecorder(4)
+	recorder.Record(OpOffer, true, 0)
+	var out bytes.Buffer
+
+	// when
+	func() {
+		defer func() { recover() }()
+		defer recorder.DumpOnPanic(&out)
+		panic("corrupted")
+	}()
+
+	// then
+	c.Assert(strings.Contains(out.String(), "offer seq=0"), Equals, true)
+}
@@ -0,0 +1,206 @@
+package lfring
+
+import (
+	"math/rand/v2"
+	"time"
+	"unsafe"
+)
+
+// relaxed trades the global FIFO for throughput: the capacity is split into NodeBased shards,
+// and every call starts at a random shard, then goes on to the next ones until success. So
+// the producers and consumers mostly hit different head / tail, rather than contend on the
+// only one, and values are FIFO only within a shard.
+//
+// It's for the workloads where ordering is irrelevant, e.g. connection pools and object
+// recycling. Offer fails only when every shard is full (or lost in contention), Poll fails
+// only when every shard is empty.
+type relaxed[T any] struct {
+	shards []RingBuffer[T]
+	mask   uint64
+}
+
+func newRelaxed[T any](capacity uint64, c *config) RingBuffer[T] {
+	shardNum := findPowerOfTwo(c.shards)
+	for shardNum > 1 && capacity/shardNum < 2 {
+		shardNum >>= 1
+	}
+
+	shards := make([]RingBuffer[T], shardNum)
+	for i := range shards {
+		shards[i] = newNodeBased[T](capacity/shardNum, c)
+	}
+
+	return &relaxed[T]{
+		shards: shards,
+		mask:   shardNum - 1,
+	}
+}
+
+func (r *relaxed[T]) start() uint64 {
+	if r.mask == 0 {
+		return 0
+	}
+	return rand.Uint64() & r.mask
+}
+
+// Offer a value to the first shard not full.
+func (r *relaxed[T]) Offer(value T) (success bool) {
+	start := r.start()
+	for i := uint64(0); i <= r.mask; i++ {
+		if r.shards[(start+i)&r.mask].Offer(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Poll a value from the first shard not empty.
+func (r *relaxed[T]) Poll() (value T, success bool) {
+	start := r.start()
+	for i := uint64(0); i <= r.mask; i++ {
+		if value, success = r.shards[(start+i)&r.mask].Poll(); success {
+			return value, true
+		}
+	}
+	return
+}
+
+// PollTimed polls a value along with its enqueue time from the first shard not empty, see
+// WithEnqueueTime.
+func (r *relaxed[T]) PollTimed() (value T, enqueued time.Time, success bool) {
+	start := r.start()
+	for i := uint64(0); i <= r.mask; i++ {
+		shard := r.shards[(start+i)&r.mask].(TimedConsumer[T])
+		if value, enqueued, success = shard.PollTimed(); success {
+			return
+		}
+	}
+	return
+}
+
+// SingleProducerOffer offers values from valueSupplier shard by shard, until finish or every
+// shard full. The caller must be the only producer.
+func (r *relaxed[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	finished := false
+	supplier := func() (v T, finish bool) {
+		v, finish = valueSupplier()
+		finished = finish
+		return v, finish
+	}
+
+	start := r.start()
+	for i := uint64(0); i <= r.mask && !finished; i++ {
+		r.shards[(start+i)&r.mask].SingleProducerOffer(supplier)
+	}
+}
+
+// SingleConsumerPoll passes the values of every shard to valueConsumer. The caller must be the
+// only consumer.
+func (r *relaxed[T]) SingleConsumerPoll(valueConsumer func(T)) {
+	start := r.start()
+	for i := uint64(0); i <= r.mask; i++ {
+		r.shards[(start+i)&r.mask].SingleConsumerPoll(valueConsumer)
+	}
+}
+
+// SingleConsumerPollVec fills ret with the values of shards, returns how many values are
+// filled. The caller must be the only consumer.
+func (r *relaxed[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
+	start := r.start()
+	for i := uint64(0); i <= r.mask && validCnt < uint64(len(ret)); i++ {
+		validCnt += r.shards[(start+i)&r.mask].SingleConsumerPollVec(ret[validCnt:])
+	}
+	return validCnt
+}
+
+// PollNBatched polls at most n values, see PollBatchInto.
+func (r *relaxed[T]) PollNBatched(n uint64) (values []T, count uint64) {
+	if n == 0 {
+		return nil, 0
+	}
+
+	values = make([]T, n)
+	count = r.PollBatchInto(values)
+	return values[:count], count
+}
+
+// PollBatchInto fills dst with the values of shards, returns how many values are filled.
+func (r *relaxed[T]) PollBatchInto(dst []T) (count uint64) {
+	start := r.start()
+	for i := uint64(0); i <= r.mask && count < uint64(len(dst)); i++ {
+		count += r.shards[(start+i)&r.mask].PollBatchInto(dst[count:])
+	}
+	return count
+}
+
+func (r *relaxed[T]) Len() uint64 {
+	return r.State().Occupancy
+}
+
+// Layout returns the layout of a shard, they're all the same.
+func (r *relaxed[T]) Layout() Layout {
+	return r.shards[0].(Layouter).Layout()
+}
+
+// full reports whether all the shards are full, an offer goes on to the next shards.
+func (r *relaxed[T]) full() bool {
+	for _, shard := range r.shards {
+		if !bufferFull(shard) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *relaxed[T]) prefault() {
+	for _, shard := range r.shards {
+		shard.(prefaulter).prefault()
+	}
+}
+
+// MemoryUsage sums up the usages of shards, a segment per shard.
+func (r *relaxed[T]) MemoryUsage() MemoryUsage {
+	u := MemoryUsage{
+		Header: uint64(unsafe.Sizeof(*r)),
+		Index:  uint64(len(r.shards)) * uint64(unsafe.Sizeof(r.shards[0])),
+	}
+	for _, shard := range r.shards {
+		u = u.add(shard.(MemoryReporter).MemoryUsage())
+	}
+	return u
+}
+
+// SizeConsistent sums up the consistent sizes of shards, each shard is consistent on its own
+// but they are read one after another, ok is false if any shard is not stable.
+func (r *relaxed[T]) SizeConsistent() (size uint64, ok bool) {
+	ok = true
+	for _, shard := range r.shards {
+		shardSize, shardOk := shard.SizeConsistent()
+		size += shardSize
+		ok = ok && shardOk
+	}
+	return size, ok
+}
+
+func (r *relaxed[T]) Cap() uint64 {
+	return r.shards[0].Cap() * (r.mask + 1)
+}
+
+// State sums up the states of shards, Head and Tail are the totals of shards.
+func (r *relaxed[T]) State() State {
+	var head, tail, occupied uint64
+	for _, shard := range r.shards {
+		state := shard.State()
+		head += state.Head
+		tail += state.Tail
+		occupied += state.Occupancy
+	}
+
+	state := newState(Relaxed, r.Cap(), head, tail)
+	state.Occupancy = occupied
+	return state
+}
+
+func (r *relaxed[T]) String() string {
+	return r.State().String()
+}
@@ -0,0 +1,107 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+func (s *MySuite) TestRelaxedOfferUntilFullAndPollAll(c *C) {
+	// given
+	buffer := New[int](Relaxed, 16, WithShards(4))
+	offered := 0
+	for buffer.Offer(offered) {
+		offered++
+	}
+
+	// when
+	var polled []int
+	for v, ok := buffer.Poll(); ok; v, ok = buffer.Poll() {
+		polled = append(polled, v)
+	}
+
+	// then
+	c.Assert(uint64(offered), Equals, buffer.Cap())
+	c.Assert(buffer.Cap(), Equals, uint64(16))
+	sort.Ints(polled)
+	c.Assert(len(polled), Equals, offered)
+	for i, v := range polled {
+		c.Assert(v, Equals, i)
+	}
+	c.Assert(buffer.State().Type, Equals, Relaxed)
+}
+
+func (s *MySuite) TestRelaxedShardsShareCapacity(c *C) {
+	// given
+	buffer := New[int](Relaxed, 4, WithShards(64))
+
+	// when
+	state := buffer.State()
+
+	// then
+	c.Assert(state.Capacity, Equals, uint64(4))
+	c.Assert(buffer.(*relaxed[int]).mask, Equals, uint64(1))
+}
+
+func (s *MySuite) TestRelaxedSingleShardIsFIFO(c *C) {
+	// given
+	buffer := New[int](Relaxed, 8, WithShards(1))
+	i := 0
+	buffer.SingleProducerOffer(func() (v int, finish bool) {
+		i++
+		return i, i > 5
+	})
+
+	// when
+	dst := make([]int, 8)
+	count := buffer.SingleConsumerPollVec(dst)
+
+	// then
+	c.Assert(dst[:count], DeepEquals, []int{1, 2, 3, 4, 5})
+}
+
+func (s *MySuite) TestRelaxedConcurrentProducersAndConsumers(c *C) {
+	// given
+	buffer := New[int](Relaxed, 64, WithShards(4))
+	const producers, perProducer = 4, 1000
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+
+	// when
+	wg.Add(producers * 2)
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; {
+				if buffer.Offer(p*perProducer + i) {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+		go func() {
+			defer wg.Done()
+			dst := make([]int, 8)
+			for received := 0; received < perProducer; {
+				count := buffer.PollBatchInto(dst[:min(8, perProducer-received)])
+				mu.Lock()
+				for _, v := range dst[:count] {
+					seen[v] = true
+				}
+				mu.Unlock()
+				received += int(count)
+				if count == 0 {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// then
+	c.Assert(len(seen), Equals, producers*perProducer)
+	c.Assert(buffer.Len(), Equals, uint64(0))
+}
@@ -1,15 +1,159 @@
 package lfring
 
+import (
+	"fmt"
+	"sync/atomic"
+)
+
 // RingBuffer defines the behavior of ring buffer
 type RingBuffer[T any] interface {
+	Producer[T]
+	Consumer[T]
+	fmt.Stringer
+	// Len returns the approximate number of values in buffer, it's a snapshot of concurrently
+	// changing head and tail.
+	Len() uint64
+	// Cap returns the real (power-of-two) capacity.
+	Cap() uint64
+	// State returns a snapshot of the internal state.
+	State() State
+	// SizeConsistent returns the number of values from a stable pair of head and tail, for the
+	// decisions (e.g. admission control) need a trustworthy number. It retries a bounded
+	// number of times, ok is false if head kept moving, and size is the last approximation.
+	SizeConsistent() (size uint64, ok bool)
+}
+
+// Producer is the offer side view of RingBuffer
+type Producer[T any] interface {
 	Offer(T) (success bool)
+	SingleProducerOffer(valueSupplier func() (v T, finish bool))
+}
+
+// Consumer is the poll side view of RingBuffer
+type Consumer[T any] interface {
 	Poll() (value T, success bool)
 	PollNBatched(n uint64) (values []T, count uint64)
-	SingleProducerOffer(valueSupplier func() (v T, finish bool))
+	PollBatchInto(dst []T) (count uint64)
 	SingleConsumerPoll(valueConsumer func(T))
 	SingleConsumerPollVec(ret []T) (validCnt uint64)
 }
 
+// ConditionalProducer is implemented by the buffers can couple an occupancy check with the
+// claim, namely NodeBased, FetchAdd and Classical. OfferIf offers value only if cond returns
+// true for the occupancy and capacity, e.g. admit only while less than 80% full:
+//
+//	admitted := buffer.(lfring.ConditionalProducer[Job]).OfferIf(job, func(len, cap uint64) bool {
+//		return len*10 < cap*8
+//	})
+//
+// The check is coupled by the CAS of tail: the offer fails if another producer claimed
+// after the occupancy read, so the occupancy can only shrink (by consumers) before the value
+// gets in, unlike a racy Len then Offer. As Offer, it returns false if buffer is full or the
+// claim lost in contention.
+type ConditionalProducer[T any] interface {
+	OfferIf(value T, cond func(len, cap uint64) bool) (success bool)
+}
+
+// OverwritingProducer is implemented by the buffers that offer both full policies per call,
+// namely NodeBased, FetchAdd and Classical. So a call site can choose by the value, e.g.
+// critical events overwrite the oldest ones while debug events are rejected when full:
+//
+//	producer := buffer.(lfring.OverwritingProducer[Event])
+//	if event.Critical {
+//		producer.OfferOverwrite(event)
+//	} else {
+//		producer.OfferReject(event)
+//	}
+type OverwritingProducer[T any] interface {
+	// OfferReject offers value, return false if buffer is full, it's the same as Offer.
+	OfferReject(value T) (success bool)
+	// OfferOverwrite offers value, polls (drops) the oldest value to make room if buffer is
+	// full, it retries until success. The last value dropped is returned if any, more than
+	// one may be dropped under contention.
+	OfferOverwrite(value T) (dropped T, overwritten bool)
+}
+
+// BatchProducer is implemented by the buffers can offer a batch all or nothing, namely
+// NodeBased, FetchAdd and Classical. OfferAllOrNothing claims the contiguous slots for all the
+// values by one CAS of tail, so related values (e.g. a message and its continuation frames)
+// are never split by a full buffer:
+//
+//	frames := []Frame{header, body, trailer}
+//	for !buffer.(lfring.BatchProducer[Frame]).OfferAllOrNothing(frames) {
+//		runtime.Gosched()
+//	}
+//
+// It returns false if there's no room for all the values or the claim lost in contention,
+// nothing is offered then. The values are published in order, consumers may poll the first
+// ones before the rest published.
+type BatchProducer[T any] interface {
+	OfferAllOrNothing(values []T) (success bool)
+}
+
+// Requeuer is implemented by the buffers that a consumer can put back a value it polled but
+// can't handle yet, namely NodeBased and FetchAdd. The value goes to tail only: putting it
+// back to head would move head backwards, which races the consumers still polling the old
+// head, and breaks the head only grows invariant the others rely on:
+//
+//	job, _ := buffer.Poll()
+//	if !job.Ready() {
+//		buffer.(lfring.Requeuer[Job]).Requeue(job)
+//	}
+type Requeuer[T any] interface {
+	// Requeue puts value back to tail, behind the values offered meanwhile. Unlike Offer it
+	// retries on contention, return false only if buffer is full.
+	Requeue(value T) (success bool)
+}
+
+// fuller is implemented by the buffers that tell full better than Len and Cap, e.g.
+// Classical holds Cap-1 values at most, and Relaxed is full only if all its shards are.
+type fuller interface {
+	full() bool
+}
+
+// bufferFull reports whether buffer is full, by full if it has one.
+func bufferFull[T any](buffer RingBuffer[T]) bool {
+	if f, ok := buffer.(fuller); ok {
+		return f.full()
+	}
+	return buffer.Len() >= buffer.Cap()
+}
+
+// offerOverwrite implements OfferOverwrite of buffer by Offer and Poll, an Offer failed is
+// only taken as full if full says so, otherwise it's contention and retried.
+func offerOverwrite[T any](buffer RingBuffer[T], value T, full func() bool) (dropped T, overwritten bool) {
+	for !buffer.Offer(value) {
+		if !full() {
+			cpuRelax()
+			continue
+		}
+		if v, ok := buffer.Poll(); ok {
+			dropped, overwritten = v, true
+		}
+	}
+	return
+}
+
+// AsProducer returns the Producer view of buffer, which can't be type-asserted back to the
+// RingBuffer, to make sure the receiver only offers.
+func AsProducer[T any](buffer RingBuffer[T]) Producer[T] {
+	return producerView[T]{buffer}
+}
+
+// AsConsumer returns the Consumer view of buffer, which can't be type-asserted back to the
+// RingBuffer, to make sure the receiver only polls.
+func AsConsumer[T any](buffer RingBuffer[T]) Consumer[T] {
+	return consumerView[T]{buffer}
+}
+
+type producerView[T any] struct {
+	Producer[T]
+}
+
+type consumerView[T any] struct {
+	Consumer[T]
+}
+
 // BufferType contains different type names of ring buffer
 type BufferType int
 
@@ -20,21 +164,122 @@ const (
 	// NodeBased is a type of ring buffer that implemented as node based,
 	// see https://www.1024cores.net/home/lock-free-algorithms/queues/bounded-mpmc-queue
 	NodeBased
+
+	// Relaxed is a type of ring buffer that shards the capacity into NodeBased rings, values
+	// are FIFO only within a shard, see WithShards
+	Relaxed
+
+	// FetchAdd is a type of NodeBased ring buffer whose producers claim slots by fetch-and-add,
+	// which never fails for contention, but a producer overshot the free slots blocks until its
+	// slot polled. The consumer side and options are the same as NodeBased
+	FetchAdd
 )
 
-// New build a RingBuffer with BufferType and capacity.
+// String returns the name of BufferType.
+func (t BufferType) String() string {
+	switch t {
+	case Classical:
+		return "Classical"
+	case NodeBased:
+		return "NodeBased"
+	case Relaxed:
+		return "Relaxed"
+	case FetchAdd:
+		return "FetchAdd"
+	default:
+		return fmt.Sprintf("BufferType(%d)", int(t))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so BufferType is marshaled as its name.
+func (t BufferType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// PolicyReject is the full buffer policy that Offer fails rather than overwrites.
+const PolicyReject = "reject"
+
+// State is a snapshot of ring buffer, which can be marshaled to JSON for admin endpoints
+// and log lines. Head and Tail are read without synchronization, so Occupancy is clamped
+// to [0, Capacity].
+type State struct {
+	Type      BufferType `json:"type"`
+	Capacity  uint64     `json:"capacity"`
+	Head      uint64     `json:"head"`
+	Tail      uint64     `json:"tail"`
+	Occupancy uint64     `json:"occupancy"`
+	Policy    string     `json:"policy"`
+}
+
+func newState(t BufferType, capacity uint64, head uint64, tail uint64) State {
+	return State{
+		Type:      t,
+		Capacity:  capacity,
+		Head:      head,
+		Tail:      tail,
+		Occupancy: occupancy(capacity, head, tail),
+		Policy:    PolicyReject,
+	}
+}
+
+func (s State) String() string {
+	return fmt.Sprintf("%s(capacity=%d, head=%d, tail=%d, occupancy=%d)", s.Type, s.Capacity, s.Head, s.Tail, s.Occupancy)
+}
+
+// occupancy calculate (tail - head) with the none-sync read of head and tail, see
+// classical.isFull for why tail maybe smaller than head.
+func occupancy(capacity uint64, head uint64, tail uint64) uint64 {
+	if tail < head {
+		return 0
+	}
+	if tail-head > capacity {
+		return capacity
+	}
+	return tail - head
+}
+
+// sizeRetries bounds how many times consistentSize reads head and tail.
+const sizeRetries = 16
+
+// consistentSize reads head, tail, then head again, the pair is stable if head didn't move
+// in between (head only grows, so it can't be ABA), that is the occupancy at the moment tail
+// read. Tail counts the claimed slots, which may be not published yet.
+func consistentSize(capacity uint64, head *uint64, tail *uint64) (size uint64, ok bool) {
+	for i := 0; i < sizeRetries; i++ {
+		h := atomic.LoadUint64(head)
+		t := atomic.LoadUint64(tail)
+		size = occupancy(capacity, h, t)
+		if atomic.LoadUint64(head) == h {
+			return size, true
+		}
+	}
+	return size, false
+}
+
+// New build a RingBuffer with BufferType, capacity and options.
 // Expand capacity as power-of-two, to make head/tail calculate faster and simpler
-func New[T any](t BufferType, capacity uint64) RingBuffer[T] {
+func New[T any](t BufferType, capacity uint64, opts ...Option) RingBuffer[T] {
 	realCapacity := findPowerOfTwo(capacity)
+	c := newConfig(opts)
 
+	var buffer RingBuffer[T]
 	switch t {
 	case NodeBased:
-		return newNodeBased[T](realCapacity)
+		buffer = newNodeBased[T](realCapacity, c)
 	case Classical:
-		return newClassical[T](realCapacity)
+		buffer = newClassical[T](realCapacity, c)
+	case Relaxed:
+		buffer = newRelaxed[T](realCapacity, c)
+	case FetchAdd:
+		buffer = newFetchAdd[T](realCapacity, c)
 	default:
 		panic("shouldn't goes here.")
 	}
+
+	if c.warmup {
+		warmup(buffer, c.warmupCycles)
+	}
+	return buffer
 }
 
 // findPowerOfTwo return the input number as round up to it's power of two
@@ -53,3 +298,22 @@ func findPowerOfTwo(givenMum uint64) uint64 {
 
 	return givenMum
 }
+
+// MustOffer offers value to producer, panics if failed. It's meant for tests, examples and
+// setup code, where a failure is a programmer error. Note that Offer may also fail when
+// lost in contention, so don't use it with concurrent producers.
+func MustOffer[T any](producer Producer[T], value T) {
+	if !producer.Offer(value) {
+		panic("lfring: MustOffer failed, buffer is full")
+	}
+}
+
+// MustPoll polls a value from consumer, panics if failed. Like MustOffer, it's not for
+// concurrent consumers.
+func MustPoll[T any](consumer Consumer[T]) T {
+	value, success := consumer.Poll()
+	if !success {
+		panic("lfring: MustPoll failed, buffer is empty")
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package ringlog
+
+import (
+	"context"
+	"log/slog"
+
+	lfring "github.com/gsingh-ds/go-lock-free-ring-buffer"
+)
+
+// Handler is a slog.Handler enqueues the records, and hands them to the inner handler in a
+// background goroutine, in order. The formatting and writing of the inner handler are both
+// off the logging call, which only clones the record.
+//
+// Handle never blocks nor fails, the record is dropped if the ring is full. The handlers
+// derived by WithAttrs and WithGroup share the ring and the flusher.
+type Handler struct {
+	inner slog.Handler
+	q     *queue[entry]
+}
+
+type entry struct {
+	handler slog.Handler
+	ctx     context.Context
+	record  slog.Record
+}
+
+// NewHandler build a Handler over inner, capacity is the number of records buffered and
+// expands to power-of-two. The options are passed to the ring, e.g. WithSpinLimit.
+func NewHandler(inner slog.Handler, capacity uint64, opts ...lfring.Option) *Handler {
+	return &Handler{inner: inner, q: newQueue(capacity, func(e entry) error {
+		return e.handler.Handle(e.ctx, e.record)
+	}, opts)}
+}
+
+// Enabled reports whether the inner handler handles level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle enqueues a clone of r, it always returns nil, see Stats for the drops. The context
+// passed to the inner handler is ctx without its cancellation, since it's used after Handle
+// returns.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	h.q.offer(entry{handler: h.inner, ctx: context.WithoutCancel(ctx), record: r.Clone()})
+	return nil
+}
+
+// WithAttrs returns a Handler whose inner handler has attrs, over the same ring.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{inner: h.inner.WithAttrs(attrs), q: h.q}
+}
+
+// WithGroup returns a Handler whose inner handler has the group name, over the same ring.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{inner: h.inner.WithGroup(name), q: h.q}
+}
+
+// Close stops accepting records, waits until the buffered ones handled, and returns the
+// first error of the inner handler. It closes the derived handlers as well.
+func (h *Handler) Close() error {
+	return h.q.close()
+}
+
+// Stats returns the counters of handled and dropped records, the derived handlers included.
+func (h *Handler) Stats() Stats {
+	return h.q.stats()
+}
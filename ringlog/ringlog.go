@@ -0,0 +1,104 @@
+// Package ringlog is an asynchronous logging sink built on lfring: the logging call only
+// enqueues the record into a ring, and a background flusher writes it to the real sink, so
+// slow disks and network writers never stall the hot path.
+//
+// When the ring is full the record is dropped rather than blocking the caller, the drops are
+// counted and reported by Stats:
+//
+//	w := ringlog.NewWriter(os.Stderr, 1024)
+//	defer w.Close()
+//	logger := slog.New(ringlog.NewHandler(slog.NewJSONHandler(os.Stderr, nil), 1024))
+//
+// Writer takes the formatted bytes of any logger, Handler takes the slog records and formats
+// them on the flusher, which moves the formatting off the hot path as well.
+package ringlog
+
+import (
+	"sync/atomic"
+
+	lfring "github.com/gsingh-ds/go-lock-free-ring-buffer"
+)
+
+// Stats is a snapshot of the counters of a sink.
+type Stats struct {
+	// Written is how many records are flushed to the real sink
+	Written uint64 `json:"written"`
+	// Dropped is how many records are dropped because the ring was full, or the sink closed
+	Dropped uint64 `json:"dropped"`
+	// Failed is how many records the real sink returned an error on
+	Failed uint64 `json:"failed"`
+}
+
+// queue is the ring and the flusher shared by Writer and Handler.
+type queue[E any] struct {
+	ring    *lfring.Blocking[E]
+	written atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+	err     atomic.Pointer[error]
+	done    chan struct{}
+}
+
+func newQueue[E any](capacity uint64, flush func(E) error, opts []lfring.Option) *queue[E] {
+	q := &queue[E]{
+		ring: lfring.NewBlocking(lfring.New[E](lfring.NodeBased, capacity, opts...), opts...),
+		done: make(chan struct{}),
+	}
+	go q.run(flush)
+	return q
+}
+
+// run flushes records until the ring closed and drained, it parks while the ring is empty.
+func (q *queue[E]) run(flush func(E) error) {
+	defer close(q.done)
+	for {
+		e, err := q.ring.PollWait()
+		if err != nil {
+			return
+		}
+
+		if err := flush(e); err != nil {
+			q.failed.Add(1)
+			q.err.CompareAndSwap(nil, &err)
+			continue
+		}
+		q.written.Add(1)
+	}
+}
+
+// offer enqueues e, it retries the offers lost in contention with the other loggers, so only
+// a full ring drops.
+func (q *queue[E]) offer(e E) {
+	if q.ring.Closed() {
+		q.dropped.Add(1)
+		return
+	}
+	for !q.ring.Offer(e) {
+		if q.ring.Len() >= q.ring.Cap() {
+			q.dropped.Add(1)
+			return
+		}
+	}
+}
+
+// close closes the ring, waits until the flusher drained it, returns the first error of the
+// real sink.
+func (q *queue[E]) close() error {
+	if err := q.ring.Close(); err != nil {
+		return err
+	}
+	<-q.done
+
+	if err := q.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (q *queue[E]) stats() Stats {
+	return Stats{
+		Written: q.written.Load(),
+		Dropped: q.dropped.Load(),
+		Failed:  q.failed.Load(),
+	}
+}
@@ -0,0 +1,151 @@
+package ringlog
+
+import (
+	"bytes"
+	"errors"
+	. "gopkg.in/check.v1"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// hook up go-check to go testing
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// blockingSink blocks the first Write until released.
+type blockingSink struct {
+	bytes.Buffer
+	started  chan struct{}
+	released chan struct{}
+}
+
+func (s *blockingSink) Write(p []byte) (int, error) {
+	if s.started != nil {
+		close(s.started)
+		s.started = nil
+		<-s.released
+	}
+	return s.Buffer.Write(p)
+}
+
+func (s *MySuite) TestWriterFlushesInOrder(c *C) {
+	// given
+	var sink bytes.Buffer
+	w := NewWriter(&sink, 16)
+	p := []byte("a\n")
+
+	// when
+	w.Write(p)
+	p[0] = 'b' // reused by the caller
+	w.Write(p)
+	err := w.Close()
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(sink.String(), Equals, "a\nb\n")
+	c.Assert(w.Stats(), Equals, Stats{Written: 2})
+}
+
+func (s *MySuite) TestWriterDropsOnFull(c *C) {
+	// given
+	sink := &blockingSink{started: make(chan struct{}), released: make(chan struct{})}
+	started := sink.started
+	w := NewWriter(sink, 2)
+	w.Write([]byte("a"))
+	<-started
+
+	// when
+	w.Write([]byte("b"))
+	w.Write([]byte("c"))
+	n, err := w.Write([]byte("d"))
+	close(sink.released)
+	w.Close()
+
+	// then
+	c.Assert(n, Equals, 1)
+	c.Assert(err, IsNil)
+	c.Assert(sink.String(), Equals, "abc")
+	c.Assert(w.Stats(), Equals, Stats{Written: 3, Dropped: 1})
+}
+
+type failingSink struct{}
+
+var errSink = errors.New("sink failed")
+
+func (failingSink) Write(p []byte) (int, error) { return 0, errSink }
+
+func (s *MySuite) TestWriterReportsSinkError(c *C) {
+	// given
+	w := NewWriter(failingSink{}, 4)
+
+	// when
+	w.Write([]byte("a"))
+	err := w.Close()
+
+	// then
+	c.Assert(err, Equals, errSink)
+	c.Assert(w.Stats(), Equals, Stats{Failed: 1})
+	c.Assert(w.Close(), NotNil)
+}
+
+func (s *MySuite) TestHandler(c *C) {
+	// given
+	var sink bytes.Buffer
+	h := NewHandler(slog.NewTextHandler(&sink, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}), 16)
+	logger := slog.New(h)
+
+	// when
+	logger.Debug("hidden")
+	logger.Info("first", "n", 1)
+	logger.With("ring", "r").WithGroup("g").Warn("second", "n", 2)
+	err := h.Close()
+	logger.Info("after close")
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(strings.Split(strings.TrimSpace(sink.String()), "\n"), DeepEquals, []string{
+		"level=INFO msg=first n=1",
+		"level=WARN msg=second ring=r g.n=2",
+	})
+	c.Assert(h.Stats(), Equals, Stats{Written: 2, Dropped: 1})
+}
+
+func (s *MySuite) TestWriterNoDropByContention(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	// given a ring with room for all the records
+	const loggers, lines = 4, 256
+	var sink bytes.Buffer
+	w := NewWriter(&sink, loggers*lines)
+	var wg sync.WaitGroup
+
+	// when loggers race on the tail
+	wg.Add(loggers)
+	for i := 0; i < loggers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				w.Write([]byte("x\n"))
+			}
+		}()
+	}
+	wg.Wait()
+	err := w.Close()
+
+	// then
+	c.Assert(err, IsNil)
+	c.Assert(w.Stats(), Equals, Stats{Written: loggers * lines})
+	c.Assert(strings.Count(sink.String(), "\n"), Equals, loggers*lines)
+}
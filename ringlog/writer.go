@@ -0,0 +1,43 @@
+package ringlog
+
+import (
+	"io"
+
+	lfring "github.com/gsingh-ds/go-lock-free-ring-buffer"
+)
+
+// Writer is an io.Writer enqueues every Write as one record, and flushes the records to the
+// real sink in a background goroutine, in order.
+//
+// Write never blocks nor fails: the bytes are copied (the caller may reuse p, as io.Writer
+// requires), and dropped if the ring is full. Hence a Writer fits the loggers that issue one
+// Write per line, e.g. log.Logger and the slog handlers.
+type Writer struct {
+	q *queue[[]byte]
+}
+
+// NewWriter build a Writer over sink, capacity is the number of records buffered and
+// expands to power-of-two. The options are passed to the ring, e.g. WithSpinLimit.
+func NewWriter(sink io.Writer, capacity uint64, opts ...lfring.Option) *Writer {
+	return &Writer{q: newQueue(capacity, func(p []byte) error {
+		_, err := sink.Write(p)
+		return err
+	}, opts)}
+}
+
+// Write enqueues a copy of p, it always returns len(p) and nil, see Stats for the drops.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.q.offer(append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// Close stops accepting records, waits until the buffered ones flushed, and returns the
+// first error of the sink. It doesn't close the sink.
+func (w *Writer) Close() error {
+	return w.q.close()
+}
+
+// Stats returns the counters of written and dropped records.
+func (w *Writer) Stats() Stats {
+	return w.q.stats()
+}
@@ -0,0 +1,164 @@
+package lfring
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// RoundRobin sequences the values of registered producer handles round-robin rather than in
+// the raw race order, for the multi-producer ingestion of comparable streams: one fast
+// producer can't bury the values of the others deep in the queue. Every handle offers to a
+// ring of its own, and the consumer takes one value per handle in turn, skipping the empty
+// ones, so the values of a handle keep their order.
+//
+// The turn is exact for a single consumer, and roughly kept with concurrent consumers.
+// RoundRobin is the Consumer side, offer by the handles from Register.
+type RoundRobin[T any] struct {
+	t        BufferType
+	capacity uint64
+	opts     []Option
+
+	// guards the changes of producers, polls read it as is
+	mu        sync.Mutex
+	producers atomic.Pointer[[]*RoundRobinProducer[T]]
+	next      atomic.Uint64
+}
+
+// NewRoundRobin build a RoundRobin, every handle registered gets a RingBuffer of BufferType,
+// capacity and options.
+func NewRoundRobin[T any](t BufferType, capacity uint64, opts ...Option) *RoundRobin[T] {
+	r := &RoundRobin[T]{t: t, capacity: capacity, opts: opts}
+	r.producers.Store(&[]*RoundRobinProducer[T]{})
+	return r
+}
+
+// RoundRobinProducer offers values to a RoundRobin, it's safe for concurrent use.
+type RoundRobinProducer[T any] struct {
+	owner  *RoundRobin[T]
+	ring   RingBuffer[T]
+	closed atomic.Bool
+}
+
+// Register returns a new handle, it takes its turn from the next poll.
+func (r *RoundRobin[T]) Register() *RoundRobinProducer[T] {
+	p := &RoundRobinProducer[T]{owner: r, ring: New[T](r.t, r.capacity, r.opts...)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	producers := append(append([]*RoundRobinProducer[T]{}, *r.producers.Load()...), p)
+	r.producers.Store(&producers)
+	return p
+}
+
+func (r *RoundRobin[T]) unregister(p *RoundRobinProducer[T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current := *r.producers.Load()
+	producers := make([]*RoundRobinProducer[T], 0, len(current))
+	for _, other := range current {
+		if other != p {
+			producers = append(producers, other)
+		}
+	}
+	r.producers.Store(&producers)
+}
+
+// Offer a value, return false if the handle is closed, its ring is full or the claim lost
+// in contention.
+func (p *RoundRobinProducer[T]) Offer(value T) (success bool) {
+	if p.closed.Load() {
+		return false
+	}
+	return p.ring.Offer(value)
+}
+
+// SingleProducerOffer offers values from valueSupplier until finish or the ring of handle
+// full, the caller must be the only producer of the handle.
+func (p *RoundRobinProducer[T]) SingleProducerOffer(valueSupplier func() (v T, finish bool)) {
+	if p.closed.Load() {
+		return
+	}
+	p.ring.SingleProducerOffer(valueSupplier)
+}
+
+// Len returns the approximate number of values of the handle not polled yet.
+func (p *RoundRobinProducer[T]) Len() uint64 {
+	return p.ring.Len()
+}
+
+// Close stops the offers of the handle, the values offered are still polled, then the
+// handle is unregistered. It must not race the offers of the handle.
+func (p *RoundRobinProducer[T]) Close() {
+	if p.closed.Swap(true) || p.ring.Len() > 0 {
+		return
+	}
+	p.owner.unregister(p)
+}
+
+// Producers returns the number of handles registered.
+func (r *RoundRobin[T]) Producers() int {
+	return len(*r.producers.Load())
+}
+
+// Len returns the approximate number of values of all the handles.
+func (r *RoundRobin[T]) Len() (n uint64) {
+	for _, p := range *r.producers.Load() {
+		n += p.ring.Len()
+	}
+	return n
+}
+
+// Poll the value of the next handle in turn that has any, return false if all are empty.
+func (r *RoundRobin[T]) Poll() (value T, success bool) {
+	producers := *r.producers.Load()
+	n := uint64(len(producers))
+	start := r.next.Load()
+	for i := uint64(0); i < n; i++ {
+		at := (start + i) % n
+		p := producers[at]
+		if value, success = p.ring.Poll(); success {
+			r.next.Store(at + 1)
+			return
+		}
+		if p.closed.Load() && p.ring.Len() == 0 {
+			r.unregister(p)
+		}
+	}
+	return
+}
+
+// PollNBatched polls at most n values, see PollBatchInto.
+func (r *RoundRobin[T]) PollNBatched(n uint64) (values []T, count uint64) {
+	if n == 0 {
+		return nil, 0
+	}
+
+	values = make([]T, n)
+	count = r.PollBatchInto(values)
+	return values[:count], count
+}
+
+// PollBatchInto fills dst with the values in turn, returns how many values are filled.
+func (r *RoundRobin[T]) PollBatchInto(dst []T) (count uint64) {
+	for count < uint64(len(dst)) {
+		v, ok := r.Poll()
+		if !ok {
+			break
+		}
+		dst[count] = v
+		count++
+	}
+	return count
+}
+
+// SingleConsumerPoll passes every value in turn to valueConsumer until all the handles are
+// empty.
+func (r *RoundRobin[T]) SingleConsumerPoll(valueConsumer func(T)) {
+	for v, ok := r.Poll(); ok; v, ok = r.Poll() {
+		valueConsumer(v)
+	}
+}
+
+// SingleConsumerPollVec fills ret with the values in turn.
+func (r *RoundRobin[T]) SingleConsumerPollVec(ret []T) (validCnt uint64) {
+	return r.PollBatchInto(ret)
+}
@@ -0,0 +1,74 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRoundRobinInterleaveProducers(c *C) {
+	// given a fast producer and a slow one
+	ring := NewRoundRobin[int](NodeBased, 8)
+	fast := ring.Register()
+	slow := ring.Register()
+	for i := 1; i <= 5; i++ {
+		fast.Offer(i)
+	}
+	slow.Offer(10)
+	slow.Offer(20)
+
+	// when
+	got, count := ring.PollNBatched(16)
+
+	// then
+	c.Assert(count, Equals, uint64(7))
+	c.Assert(got, DeepEquals, []int{1, 10, 2, 20, 3, 4, 5})
+	c.Assert(ring.Len(), Equals, uint64(0))
+}
+
+func (s *MySuite) TestRoundRobinKeepTurnAcrossPolls(c *C) {
+	// given
+	ring := NewRoundRobin[int](NodeBased, 8)
+	a, b, empty := ring.Register(), ring.Register(), ring.Register()
+	a.Offer(1)
+	a.Offer(2)
+	b.Offer(10)
+	b.Offer(20)
+
+	// when
+	var got []int
+	ring.SingleConsumerPoll(func(v int) { got = append(got, v) })
+
+	// then the empty handle doesn't give its turn to a twice
+	c.Assert(got, DeepEquals, []int{1, 10, 2, 20})
+	c.Assert(empty.Len(), Equals, uint64(0))
+}
+
+func (s *MySuite) TestRoundRobinUnregisterClosedOnceDrained(c *C) {
+	// given
+	ring := NewRoundRobin[int](NodeBased, 4)
+	closed := ring.Register()
+	open := ring.Register()
+	closed.Offer(1)
+	open.Offer(2)
+
+	// when
+	closed.Close()
+	rejected := !closed.Offer(3)
+	beforeDrained := ring.Producers()
+	dst := make([]int, 4)
+	count := ring.SingleConsumerPollVec(dst)
+	ring.Poll()
+
+	// then
+	c.Assert(rejected, Equals, true)
+	c.Assert(beforeDrained, Equals, 2)
+	c.Assert(dst[:count], DeepEquals, []int{1, 2})
+	c.Assert(ring.Producers(), Equals, 1)
+
+	// when closed empty
+	open.Close()
+
+	// then
+	c.Assert(ring.Producers(), Equals, 0)
+	_, ok := ring.Poll()
+	c.Assert(ok, Equals, false)
+}
@@ -0,0 +1,78 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// Sender is the blocking offer side, both Blocking and Chan are Sender.
+type Sender[T any] interface {
+	Send(ctx context.Context, value T) error
+}
+
+// Receiver is the blocking poll side, both Blocking and Chan are Receiver.
+type Receiver[T any] interface {
+	Recv(ctx context.Context) (value T, err error)
+}
+
+// PanicError is returned by the Run helpers when the user function panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("lfring: panic in pipeline stage: %v", e.Value)
+}
+
+// RunProducer sends the values from valueSupplier to dst until the supplier finishes. It
+// returns nil when the supplier finishes, ctx.Err() when ctx done, ErrClosed when dst closed,
+// or *PanicError when the supplier panics, so a stage becomes one line of errgroup.Group:
+//
+//	g.Go(func() error { return lfring.RunProducer(ctx, ch, supplier) })
+func RunProducer[T any](ctx context.Context, dst Sender[T], valueSupplier func() (v T, finish bool)) (err error) {
+	defer recoverAsError(&err)
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		v, finish := valueSupplier()
+		if finish {
+			return nil
+		}
+		if err = dst.Send(ctx, v); err != nil {
+			return err
+		}
+	}
+}
+
+// RunConsumer receives values from src and passes them to handler, until src is closed and
+// drained, which returns nil. It returns ctx.Err() when ctx done, the handler error as is,
+// or *PanicError when the handler panics.
+func RunConsumer[T any](ctx context.Context, src Receiver[T], handler func(T) error) (err error) {
+	defer recoverAsError(&err)
+
+	for {
+		var v T
+		v, err = src.Recv(ctx)
+		if errors.Is(err, ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = handler(v); err != nil {
+			return err
+		}
+	}
+}
+
+func recoverAsError(err *error) {
+	if p := recover(); p != nil {
+		*err = &PanicError{Value: p, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,70 @@
+package lfring
+
+import (
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRunProducerAndConsumer(c *C) {
+	// given
+	ctx := context.Background()
+	ch := NewChan[int](4)
+	i := 0
+	sum := 0
+	consumed := make(chan error)
+
+	// when
+	go func() {
+		consumed <- RunConsumer[int](ctx, ch, func(v int) error {
+			sum += v
+			return nil
+		})
+	}()
+	produced := RunProducer[int](ctx, ch, func() (v int, finish bool) {
+		i++
+		return i, i > 100
+	})
+	ch.Close()
+
+	// then
+	c.Assert(produced, IsNil)
+	c.Assert(<-consumed, IsNil)
+	c.Assert(sum, Equals, 5050)
+}
+
+func (s *MySuite) TestRunConsumerStopOnErrorAndPanic(c *C) {
+	// given
+	ctx := context.Background()
+	ch := NewChan[int](4)
+	ch.TrySend(1)
+	ch.TrySend(2)
+	failure := errors.New("failure")
+
+	// when
+	handlerErr := RunConsumer[int](ctx, ch, func(int) error { return failure })
+	panicErr := RunConsumer[int](ctx, ch, func(int) error { panic("boom") })
+
+	// then
+	c.Assert(handlerErr, Equals, failure)
+	var pe *PanicError
+	c.Assert(errors.As(panicErr, &pe), Equals, true)
+	c.Assert(pe.Value, Equals, "boom")
+}
+
+func (s *MySuite) TestRunProducerCancelled(c *C) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := NewChan[int](2)
+
+	// when
+	err := RunProducer[int](ctx, ch, func() (v int, finish bool) {
+		if ch.Len() == ch.Cap() {
+			cancel()
+		}
+		return 1, false
+	})
+
+	// then
+	c.Assert(err, Equals, context.Canceled)
+}
@@ -0,0 +1,101 @@
+package lfring
+
+import (
+	"time"
+)
+
+// Sample is the State of buffer taken by Sampler at Time.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	State State     `json:"state"`
+}
+
+// OccupancySummary sums up the occupancy of the retained samples.
+type OccupancySummary struct {
+	Samples int     `json:"samples"`
+	Min     uint64  `json:"min"`
+	Max     uint64  `json:"max"`
+	Mean    float64 `json:"mean"`
+	Last    uint64  `json:"last"`
+}
+
+// Sampler records the State of buffer at an interval into a small history ring, so the
+// occupancy over time can be queried in process (e.g. by an admin endpoint) for capacity
+// planning, rather than be scraped externally at high frequency. The history is a Multicast,
+// older samples are overwritten.
+type Sampler struct {
+	source   interface{ State() State }
+	interval time.Duration
+	samples  *Multicast[Sample]
+}
+
+// NewSampler build a Sampler of source (any RingBuffer) that keeps the last history (expands
+// to power-of-two) samples, taken every interval once started.
+func NewSampler(source interface{ State() State }, interval time.Duration, history uint64) *Sampler {
+	return &Sampler{
+		source:   source,
+		interval: interval,
+		samples:  NewMulticast[Sample](history),
+	}
+}
+
+// Start runs the sampling goroutine until the returned stop is called.
+func (s *Sampler) Start() (stop func()) {
+	ticker := time.NewTicker(s.interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.Sample()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// Sample takes a sample at once.
+func (s *Sampler) Sample() {
+	s.samples.Offer(Sample{Time: time.Now(), State: s.source.State()})
+}
+
+// History returns the retained samples from the oldest to the newest.
+func (s *Sampler) History() []Sample {
+	var samples []Sample
+	cursor := s.samples.CursorFromEarliest()
+	for {
+		sample, success := cursor.Next()
+		if !success {
+			return samples
+		}
+		samples = append(samples, sample)
+	}
+}
+
+// Summary sums up the occupancy of History.
+func (s *Sampler) Summary() OccupancySummary {
+	var summary OccupancySummary
+	var total uint64
+	for _, sample := range s.History() {
+		occupancy := sample.State.Occupancy
+		if summary.Samples == 0 || occupancy < summary.Min {
+			summary.Min = occupancy
+		}
+		if occupancy > summary.Max {
+			summary.Max = occupancy
+		}
+		summary.Last = occupancy
+		summary.Samples++
+		total += occupancy
+	}
+	if summary.Samples > 0 {
+		summary.Mean = float64(total) / float64(summary.Samples)
+	}
+	return summary
+}
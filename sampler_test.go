@@ -0,0 +1,40 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestSamplerKeepsRecentHistory(c *C) {
+	// given
+	buffer := New[int](NodeBased, 8)
+	sampler := NewSampler(buffer, time.Hour, 4)
+
+	// when
+	for i := 0; i < 6; i++ {
+		MustOffer[int](buffer, i)
+		sampler.Sample()
+	}
+
+	// then
+	history := sampler.History()
+	c.Assert(len(history), Equals, 4)
+	c.Assert(history[0].State.Occupancy, Equals, uint64(3))
+	c.Assert(history[3].State.Occupancy, Equals, uint64(6))
+	c.Assert(sampler.Summary(), Equals, OccupancySummary{Samples: 4, Min: 3, Max: 6, Mean: 4.5, Last: 6})
+}
+
+func (s *MySuite) TestSamplerStart(c *C) {
+	// given
+	buffer := New[int](Classical, 8)
+	sampler := NewSampler(buffer, time.Millisecond, 16)
+
+	// when
+	stop := sampler.Start()
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	// then
+	c.Assert(len(sampler.History()) > 0, Equals, true)
+	c.Assert(sampler.Summary().Max, Equals, uint64(0))
+}
@@ -0,0 +1,137 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// Sequencer is the sequencing part of a multi-producer multi-consumer ring buffer without
+// storage, it works with any storage indexed by Index(seq), e.g. a slice of structs the
+// user already owns or a mmap region.
+//
+// The protocol is the same as nodeBased: every slot has a step, a producer TryClaim the
+// tail sequence only if the step of its slot says it's free, fills the storage, then
+// Publish it; a consumer TryAcquire the head sequence only if it's published, reads the
+// storage, then Release it back to producers. Between claim and publish (acquire and
+// release) the caller has the exclusive ownership of the slot.
+type Sequencer struct {
+	head      uint64
+	_padding0 [56]byte
+	tail      uint64
+	_padding1 [56]byte
+	published uint64
+	_padding2 [56]byte
+	mask      uint64
+	wait      WaitStrategy
+	steps     []sequenceStep
+}
+
+type sequenceStep struct {
+	step     uint64
+	_padding [56]byte
+}
+
+// NewSequencer build a Sequencer, capacity expands to power-of-two as New does, so the
+// storage should have at least Capacity() slots. WithWaitStrategy decides how WaitFor waits.
+func NewSequencer(capacity uint64, opts ...Option) *Sequencer {
+	s := &Sequencer{}
+	s.init(capacity, newConfig(opts))
+	return s
+}
+
+func (s *Sequencer) init(capacity uint64, c *config) {
+	realCapacity := findPowerOfTwo(capacity)
+	s.steps = make([]sequenceStep, realCapacity)
+	for i := uint64(0); i < realCapacity; i++ {
+		s.steps[i].step = i
+	}
+	s.mask = realCapacity - 1
+	s.wait = c.wait
+}
+
+// Capacity returns the real (power-of-two) capacity.
+func (s *Sequencer) Capacity() uint64 {
+	return s.mask + 1
+}
+
+// Index returns the storage index of seq.
+func (s *Sequencer) Index(seq uint64) uint64 {
+	return seq & s.mask
+}
+
+// TryClaim claims the tail sequence for a producer, return false if full or the claim lost
+// in contention. A claimed sequence must be published by Publish.
+func (s *Sequencer) TryClaim() (seq uint64, success bool) {
+	oldTail := atomic.LoadUint64(&s.tail)
+	// not published yet
+	if atomic.LoadUint64(&s.steps[oldTail&s.mask].step) != oldTail {
+		return
+	}
+
+	if !atomic.CompareAndSwapUint64(&s.tail, oldTail, oldTail+1) {
+		return
+	}
+	return oldTail, true
+}
+
+// Publish announces the claimed seq has been filled and can be acquired by consumers.
+func (s *Sequencer) Publish(seq uint64) {
+	atomic.StoreUint64(&s.steps[seq&s.mask].step, seq+1)
+}
+
+// TryAcquire acquires the head sequence for a consumer, return false if empty or the
+// acquire lost in contention. An acquired sequence must be returned by Release.
+func (s *Sequencer) TryAcquire() (seq uint64, success bool) {
+	oldHead := atomic.LoadUint64(&s.head)
+	// not published yet
+	if atomic.LoadUint64(&s.steps[oldHead&s.mask].step) != oldHead+1 {
+		return
+	}
+
+	if !atomic.CompareAndSwapUint64(&s.head, oldHead, oldHead+1) {
+		return
+	}
+	return oldHead, true
+}
+
+// Release gives the acquired seq back to producers, its slot can be claimed by the next lap.
+func (s *Sequencer) Release(seq uint64) {
+	atomic.StoreUint64(&s.steps[seq&s.mask].step, seq+s.mask+1)
+}
+
+// Published returns the sequence next to the contiguously published ones, that is, all
+// sequences in [0, Published()) have been published.
+//
+// Sequences are published out of order under multiple producers (the later claimer may
+// finish first), so the scan stops at the first sequence that still being filled.
+func (s *Sequencer) Published() uint64 {
+	oldPublished := atomic.LoadUint64(&s.published)
+	tail := atomic.LoadUint64(&s.tail)
+	next := oldPublished
+	for next < tail && s.isPublished(next) {
+		next++
+	}
+
+	// others may have scanned further, only move forward
+	for next > oldPublished && !atomic.CompareAndSwapUint64(&s.published, oldPublished, next) {
+		oldPublished = atomic.LoadUint64(&s.published)
+	}
+	return next
+}
+
+// WaitFor blocks by the wait strategy until seq and all sequences before it are published,
+// returns the highest contiguously published sequence, which maybe greater than seq.
+func (s *Sequencer) WaitFor(seq uint64) (available uint64) {
+	for attempt := 1; ; attempt++ {
+		if published := s.Published(); published > seq {
+			return published - 1
+		}
+		s.wait.Wait(attempt)
+	}
+}
+
+// isPublished check whether the given claimed sequence is published. The step of its slot
+// becomes seq+1 once published, then grows along with the following Release and laps, it's
+// never greater than seq before published.
+func (s *Sequencer) isPublished(seq uint64) bool {
+	return atomic.LoadUint64(&s.steps[seq&s.mask].step) > seq
+}
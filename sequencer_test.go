@@ -0,0 +1,54 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestSequencerWithUserStorage(c *C) {
+	// given
+	sequencer := NewSequencer(3)
+	storage := make([]string, sequencer.Capacity())
+	values := []string{"a", "b", "c", "d"}
+
+	// when
+	for _, v := range values {
+		seq, success := sequencer.TryClaim()
+		c.Assert(success, Equals, true)
+		storage[sequencer.Index(seq)] = v
+		sequencer.Publish(seq)
+	}
+	_, full := sequencer.TryClaim()
+
+	// then
+	c.Assert(full, Equals, false)
+	c.Assert(sequencer.Published(), Equals, uint64(4))
+	for _, v := range values {
+		seq, success := sequencer.TryAcquire()
+		c.Assert(success, Equals, true)
+		c.Assert(storage[sequencer.Index(seq)], Equals, v)
+		sequencer.Release(seq)
+	}
+	_, empty := sequencer.TryAcquire()
+	c.Assert(empty, Equals, false)
+}
+
+func (s *MySuite) TestSequencerPublishedOutOfOrder(c *C) {
+	// given
+	sequencer := NewSequencer(4)
+	first, _ := sequencer.TryClaim()
+	second, _ := sequencer.TryClaim()
+
+	// when
+	sequencer.Publish(second)
+
+	// then
+	c.Assert(sequencer.Published(), Equals, uint64(0))
+	_, acquired := sequencer.TryAcquire()
+	c.Assert(acquired, Equals, false)
+
+	// when
+	sequencer.Publish(first)
+
+	// then
+	c.Assert(sequencer.Published(), Equals, uint64(2))
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package shm
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// pollInterval is how often futexWait polls the word without futex.
+const pollInterval = 50 * time.Microsecond
+
+// futexWait polls *addr until it differs from val, at most timeout.
+func futexWait(addr *uint32, val uint32, timeout time.Duration) {
+	for deadline := time.Now().Add(timeout); atomic.LoadUint32(addr) == val && time.Now().Before(deadline); {
+		time.Sleep(pollInterval)
+	}
+}
+
+// futexWake does nothing, the sleepers poll.
+func futexWake(*uint32) {}
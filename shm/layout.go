@@ -0,0 +1,130 @@
+// Package shm is a multi-producer multi-consumer ring buffer in a shared memory region, e.g.
+// a file mapped by more than one process, so Go and non-Go processes can exchange messages
+// without a socket in between.
+//
+// The binary layout is stable and documented below, lfring_shm.h is the reference header
+// for C (and any language with a C FFI), which implements the same protocol.
+//
+// # Layout
+//
+// All the fields are little-endian, the region must be 8-byte aligned (a mapping is page
+// aligned). A region of capacity slots of slotSize bytes takes Size(capacity, slotSize)
+// bytes:
+//
+//	offset  size  field
+//	0       4     magic, "LFRS" (0x5346524c), written last by Init
+//	4       4     version, 1
+//	8       8     capacity, number of slots, power-of-two
+//	16      8     slot size, bytes of payload per slot, multiple of 8
+//	24      4     consumer waiters, number of consumers asleep on readable
+//	28      4     producer waiters, number of producers asleep on writable
+//	32      4     readable, bumped to wake the consumers
+//	36      4     writable, bumped to wake the producers
+//	40      4     notify armed, 1 if a consumer waits for the notification of Notifier
+//	44      20    reserved, zero
+//	64      8     head, the sequence of the next slot to poll
+//	72      56    padding, head and tail on their own cache lines
+//	128     8     tail, the sequence of the next slot to offer
+//	136     56    padding
+//	192           slots, capacity of [16 + slot size] bytes, the slot of sequence s is at
+//	              192 + (s & (capacity-1)) * (16 + slot size)
+//
+// A slot is:
+//
+//	offset  size  field
+//	0       8     step, the sequence protocol below
+//	8       4     length of payload, or 0xffffffff (Discarded) if the producer gave up
+//	12      4     reserved, zero
+//	16            payload, slot size bytes
+//
+// # Sequence protocol
+//
+// It's the one of lfring.NodeBased. Every access to head, tail and step is a sequentially
+// consistent 64-bit atomic operation, the other fields are plain accesses ordered by them.
+// Initially step of slot i is i, head and tail are 0.
+//
+// A producer loads tail as t, the slot of t is free if its step equals t; it claims the slot
+// by CAS tail from t to t+1, writes length and payload, then stores step t+1 to publish it.
+//
+// A consumer loads head as h, the slot of h is published if its step equals h+1; it claims
+// the slot by CAS head from h to h+1, reads length and payload, then stores step h+capacity
+// to release it to the producer of the next lap. A Discarded slot is released and skipped.
+//
+// A failed check or CAS means full / empty or contention, the caller may simply retry.
+//
+// # Waiting
+//
+// Instead of retrying, a consumer may sleep on the 32-bit word readable by a futex (Linux),
+// or poll it elsewhere: it loads readable as w, adds 1 to consumer waiters, retries once, and
+// only if still empty waits while readable equals w, then subtracts 1 from consumer waiters.
+// Every producer, after publishing, bumps readable by 1 and wakes all the waiters of it if
+// consumer waiters is not 0. Producers of a full ring wait on writable the same way, woken
+// by the consumers after releasing. The operations on these words are 32-bit atomic ones.
+//
+// A peer that doesn't wake is still correct, the Go side waits by a timeout as a fallback.
+//
+// # Notification
+//
+// An event loop (e.g. of epoll) can't sleep on a futex, so a consumer may wait for the
+// readiness of a file descriptor instead, an eventfd on Linux or a pipe elsewhere, see
+// Notifier. The consumer stores 1 to notify armed, retries once, and only if still empty
+// waits for the descriptor to be readable. Every producer with the descriptor, after
+// publishing, swaps notify armed with 0, and writes 8 bytes of a little-endian 1 to the
+// descriptor if it was 1. The consumer reads the descriptor until it would block, then polls
+// the ring until empty before arming again.
+package shm
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// Magic identifies an initialized region
+	Magic = 0x5346524c
+	// Version of the layout
+	Version = 1
+	// HeaderSize is the size of region header, the offset of the first slot
+	HeaderSize = 192
+	// SlotHeaderSize is the size of slot header, the offset of payload in slot
+	SlotHeaderSize = 16
+	// Discarded is the length of a slot the producer gave up after claimed
+	Discarded = 0xffffffff
+
+	offsetMagic           = 0
+	offsetVersion         = 4
+	offsetCapacity        = 8
+	offsetSlotSize        = 16
+	offsetConsumerWaiters = 24
+	offsetProducerWaiters = 28
+	offsetReadable        = 32
+	offsetWritable        = 36
+	offsetNotifyArmed     = 40
+	offsetHead            = 64
+	offsetTail            = 128
+
+	slotOffsetStep   = 0
+	slotOffsetLength = 8
+)
+
+var (
+	// ErrLayout is returned when the region doesn't match the layout, e.g. too small,
+	// unaligned, or a bad capacity or slot size.
+	ErrLayout = errors.New("shm: region doesn't match the layout")
+	// ErrNotInitialized is returned by Attach when the region is not initialized (yet).
+	ErrNotInitialized = errors.New("shm: region is not initialized")
+	// ErrVersion is returned by Attach when the region is of another layout version.
+	ErrVersion = errors.New("shm: unsupported layout version")
+	// ErrBigEndian is returned on big-endian hosts, where the native atomic operations can't
+	// access the little-endian fields.
+	ErrBigEndian = errors.New("shm: big-endian host is not supported")
+)
+
+// Size returns the bytes of a region of capacity slots of slotSize bytes.
+func Size(capacity, slotSize uint64) int {
+	return int(HeaderSize + capacity*(SlotHeaderSize+slotSize))
+}
+
+func littleEndianHost() bool {
+	return binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+}
@@ -0,0 +1,270 @@
+/*
+ * lfring_shm.h - the reference C implementation of the shared-memory ring of the Go package
+ * github.com/gsingh-ds/go-lock-free-ring-buffer/shm, see its package doc for the layout and
+ * the sequence protocol. The offsets are checked against the Go constants by the tests of
+ * the package, keep them in sync.
+ *
+ * It needs C11 and a little-endian host. The region is formatted by the Go side (shm.Init /
+ * shm.Create), a C process maps the same file and attaches:
+ *
+ *     lfring_shm ring;
+ *     if (lfring_shm_attach(&ring, region, region_len) != 0) { ... }
+ *     while (!lfring_shm_offer(&ring, msg, msg_len)) { sched_yield(); }
+ */
+#ifndef LFRING_SHM_H
+#define LFRING_SHM_H
+
+#include <stdatomic.h>
+#include <stddef.h>
+#include <stdint.h>
+#include <string.h>
+#include <unistd.h>
+#ifdef __linux__
+#include <linux/futex.h>
+#include <sys/syscall.h>
+#include <time.h>
+#else
+#include <sched.h>
+#endif
+
+#define LFRING_SHM_MAGIC 0x5346524c
+#define LFRING_SHM_VERSION 1
+#define LFRING_SHM_HEADER_SIZE 192
+#define LFRING_SHM_SLOT_HEADER_SIZE 16
+#define LFRING_SHM_DISCARDED 0xffffffff
+
+#define LFRING_SHM_OFFSET_MAGIC 0
+#define LFRING_SHM_OFFSET_VERSION 4
+#define LFRING_SHM_OFFSET_CAPACITY 8
+#define LFRING_SHM_OFFSET_SLOT_SIZE 16
+#define LFRING_SHM_OFFSET_CONSUMER_WAITERS 24
+#define LFRING_SHM_OFFSET_PRODUCER_WAITERS 28
+#define LFRING_SHM_OFFSET_READABLE 32
+#define LFRING_SHM_OFFSET_WRITABLE 36
+#define LFRING_SHM_OFFSET_NOTIFY_ARMED 40
+#define LFRING_SHM_OFFSET_HEAD 64
+#define LFRING_SHM_OFFSET_TAIL 128
+
+#define LFRING_SHM_SLOT_OFFSET_STEP 0
+#define LFRING_SHM_SLOT_OFFSET_LENGTH 8
+
+typedef struct {
+	_Atomic uint32_t magic;
+	uint32_t version;
+	uint64_t capacity;
+	uint64_t slot_size;
+	_Atomic uint32_t consumer_waiters;
+	_Atomic uint32_t producer_waiters;
+	_Atomic uint32_t readable;
+	_Atomic uint32_t writable;
+	_Atomic uint32_t notify_armed;
+	uint8_t reserved[20];
+	_Atomic uint64_t head;
+	uint8_t padding0[56];
+	_Atomic uint64_t tail;
+	uint8_t padding1[56];
+} lfring_shm_header;
+
+typedef struct {
+	_Atomic uint64_t step;
+	uint32_t length;
+	uint32_t reserved;
+	/* followed by slot_size bytes of payload */
+} lfring_shm_slot;
+
+_Static_assert(sizeof(lfring_shm_header) == LFRING_SHM_HEADER_SIZE, "header size");
+_Static_assert(offsetof(lfring_shm_header, magic) == LFRING_SHM_OFFSET_MAGIC, "magic offset");
+_Static_assert(offsetof(lfring_shm_header, version) == LFRING_SHM_OFFSET_VERSION, "version offset");
+_Static_assert(offsetof(lfring_shm_header, capacity) == LFRING_SHM_OFFSET_CAPACITY, "capacity offset");
+_Static_assert(offsetof(lfring_shm_header, slot_size) == LFRING_SHM_OFFSET_SLOT_SIZE, "slot size offset");
+_Static_assert(offsetof(lfring_shm_header, consumer_waiters) == LFRING_SHM_OFFSET_CONSUMER_WAITERS, "consumer waiters offset");
+_Static_assert(offsetof(lfring_shm_header, producer_waiters) == LFRING_SHM_OFFSET_PRODUCER_WAITERS, "producer waiters offset");
+_Static_assert(offsetof(lfring_shm_header, readable) == LFRING_SHM_OFFSET_READABLE, "readable offset");
+_Static_assert(offsetof(lfring_shm_header, writable) == LFRING_SHM_OFFSET_WRITABLE, "writable offset");
+_Static_assert(offsetof(lfring_shm_header, notify_armed) == LFRING_SHM_OFFSET_NOTIFY_ARMED, "notify armed offset");
+_Static_assert(offsetof(lfring_shm_header, head) == LFRING_SHM_OFFSET_HEAD, "head offset");
+_Static_assert(offsetof(lfring_shm_header, tail) == LFRING_SHM_OFFSET_TAIL, "tail offset");
+_Static_assert(sizeof(lfring_shm_slot) == LFRING_SHM_SLOT_HEADER_SIZE, "slot header size");
+_Static_assert(offsetof(lfring_shm_slot, step) == LFRING_SHM_SLOT_OFFSET_STEP, "step offset");
+_Static_assert(offsetof(lfring_shm_slot, length) == LFRING_SHM_SLOT_OFFSET_LENGTH, "length offset");
+
+typedef struct {
+	lfring_shm_header *header;
+	uint8_t *slots;
+	uint64_t mask;
+	uint64_t slot_size;
+	uint64_t stride;
+	/* the descriptor of Notifier the offers write to, -1 (by attach) to not notify */
+	int notify_fd;
+} lfring_shm;
+
+/* lfring_shm_attach returns 0 on success, -1 if region doesn't match the layout. */
+static inline int lfring_shm_attach(lfring_shm *ring, void *region, size_t len) {
+	lfring_shm_header *h = (lfring_shm_header *)region;
+	if (len < LFRING_SHM_HEADER_SIZE || ((uintptr_t)region) % 8 != 0) {
+		return -1;
+	}
+	if (atomic_load(&h->magic) != LFRING_SHM_MAGIC || h->version != LFRING_SHM_VERSION) {
+		return -1;
+	}
+	if (h->capacity == 0 || (h->capacity & (h->capacity - 1)) != 0 || h->slot_size % 8 != 0) {
+		return -1;
+	}
+	if (len < LFRING_SHM_HEADER_SIZE + h->capacity * (LFRING_SHM_SLOT_HEADER_SIZE + h->slot_size)) {
+		return -1;
+	}
+
+	ring->header = h;
+	ring->slots = (uint8_t *)region + LFRING_SHM_HEADER_SIZE;
+	ring->mask = h->capacity - 1;
+	ring->slot_size = h->slot_size;
+	ring->stride = LFRING_SHM_SLOT_HEADER_SIZE + h->slot_size;
+	ring->notify_fd = -1;
+	return 0;
+}
+
+static inline lfring_shm_slot *lfring_shm_slot_of(lfring_shm *ring, uint64_t seq) {
+	return (lfring_shm_slot *)(ring->slots + (seq & ring->mask) * ring->stride);
+}
+
+/* lfring_shm_wake bumps seq and wakes its sleepers, if any, see the waiting protocol. */
+static inline void lfring_shm_wake(_Atomic uint32_t *waiters, _Atomic uint32_t *seq) {
+	if (atomic_load(waiters) == 0) {
+		return;
+	}
+	atomic_fetch_add(seq, 1);
+#ifdef __linux__
+	syscall(SYS_futex, (uint32_t *)seq, FUTEX_WAKE, INT32_MAX, NULL, NULL, 0);
+#endif
+}
+
+/* lfring_shm_sleep sleeps while seq equals val, at most 10ms. */
+static inline void lfring_shm_sleep(_Atomic uint32_t *seq, uint32_t val) {
+#ifdef __linux__
+	struct timespec timeout = {0, 10000000};
+	syscall(SYS_futex, (uint32_t *)seq, FUTEX_WAIT, val, &timeout, NULL, 0);
+#else
+	(void)seq;
+	(void)val;
+	sched_yield();
+#endif
+}
+
+/* lfring_shm_notify writes the notify descriptor if a consumer armed, see the notification. */
+static inline void lfring_shm_notify(lfring_shm *ring) {
+	if (ring->notify_fd < 0 || atomic_exchange(&ring->header->notify_armed, 0) != 1) {
+		return;
+	}
+	uint8_t one[8] = {1, 0, 0, 0, 0, 0, 0, 0};
+	ssize_t n = write(ring->notify_fd, one, sizeof(one));
+	(void)n;
+}
+
+/* lfring_shm_arm asks the producers to notify on the next publish, returns nonzero if ring has
+ * values already, poll rather than wait for the descriptor then. */
+static inline int lfring_shm_arm(lfring_shm *ring) {
+	atomic_store(&ring->header->notify_armed, 1);
+	return atomic_load(&ring->header->tail) != atomic_load(&ring->header->head);
+}
+
+/* lfring_shm_offer copies len bytes of p into a slot, returns 0 if ring is full, the claim
+ * lost in contention, or len is larger than the slot size. */
+static inline int lfring_shm_offer(lfring_shm *ring, const void *p, uint32_t len) {
+	if (len > ring->slot_size) {
+		return 0;
+	}
+
+	uint64_t tail = atomic_load(&ring->header->tail);
+	lfring_shm_slot *slot = lfring_shm_slot_of(ring, tail);
+	if (atomic_load(&slot->step) != tail) {
+		return 0;
+	}
+	if (!atomic_compare_exchange_strong(&ring->header->tail, &tail, tail + 1)) {
+		return 0;
+	}
+
+	memcpy((uint8_t *)slot + LFRING_SHM_SLOT_HEADER_SIZE, p, len);
+	slot->length = len;
+	atomic_store(&slot->step, tail + 1);
+	lfring_shm_wake(&ring->header->consumer_waiters, &ring->header->readable);
+	lfring_shm_notify(ring);
+	return 1;
+}
+
+/* lfring_shm_poll copies the payload of head slot to dst (of cap bytes, at least the slot size),
+ * returns its length, or -1 if ring is empty or the claim lost in contention. */
+static inline int64_t lfring_shm_poll(lfring_shm *ring, void *dst, size_t cap) {
+	if (cap < ring->slot_size) {
+		return -1;
+	}
+
+	for (;;) {
+		uint64_t head = atomic_load(&ring->header->head);
+		lfring_shm_slot *slot = lfring_shm_slot_of(ring, head);
+		if (atomic_load(&slot->step) != head + 1) {
+			return -1;
+		}
+		if (!atomic_compare_exchange_strong(&ring->header->head, &head, head + 1)) {
+			return -1;
+		}
+
+		uint32_t len = slot->length;
+		if (len != LFRING_SHM_DISCARDED) {
+			memcpy(dst, (uint8_t *)slot + LFRING_SHM_SLOT_HEADER_SIZE, len);
+		}
+		atomic_store(&slot->step, head + ring->mask + 1);
+		lfring_shm_wake(&ring->header->producer_waiters, &ring->header->writable);
+		if (len != LFRING_SHM_DISCARDED) {
+			return len;
+		}
+	}
+}
+
+/* lfring_shm_poll_wait is lfring_shm_poll that sleeps while ring is empty. */
+static inline int64_t lfring_shm_poll_wait(lfring_shm *ring, void *dst, size_t cap) {
+	lfring_shm_header *h = ring->header;
+	for (;;) {
+		int64_t n = lfring_shm_poll(ring, dst, cap);
+		if (n >= 0 || cap < ring->slot_size) {
+			return n;
+		}
+
+		uint32_t w = atomic_load(&h->readable);
+		atomic_fetch_add(&h->consumer_waiters, 1);
+		n = lfring_shm_poll(ring, dst, cap);
+		if (n < 0) {
+			lfring_shm_sleep(&h->readable, w);
+		}
+		atomic_fetch_sub(&h->consumer_waiters, 1);
+		if (n >= 0) {
+			return n;
+		}
+	}
+}
+
+/* lfring_shm_offer_wait is lfring_shm_offer that sleeps while ring is full, returns 0 only if
+ * len is larger than the slot size. */
+static inline int lfring_shm_offer_wait(lfring_shm *ring, const void *p, uint32_t len) {
+	lfring_shm_header *h = ring->header;
+	if (len > ring->slot_size) {
+		return 0;
+	}
+	for (;;) {
+		if (lfring_shm_offer(ring, p, len)) {
+			return 1;
+		}
+
+		uint32_t w = atomic_load(&h->writable);
+		atomic_fetch_add(&h->producer_waiters, 1);
+		int ok = lfring_shm_offer(ring, p, len);
+		if (!ok) {
+			lfring_shm_sleep(&h->writable, w);
+		}
+		atomic_fetch_sub(&h->producer_waiters, 1);
+		if (ok) {
+			return 1;
+		}
+	}
+}
+
+#endif /* LFRING_SHM_H */
@@ -0,0 +1,27 @@
+//go:build !unix
+
+package shm
+
+import (
+	"errors"
+)
+
+// Mapping is a Ring over a file mapped into memory, it's only supported on unix.
+type Mapping struct {
+	*Ring
+}
+
+// Create is not supported on this platform, map the region by the platform API and Init it.
+func Create(path string, capacity, slotSize uint64) (*Mapping, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Open is not supported on this platform, map the region by the platform API and Attach it.
+func Open(path string) (*Mapping, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// Close does nothing.
+func (m *Mapping) Close() error {
+	return nil
+}
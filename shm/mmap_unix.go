@@ -0,0 +1,72 @@
+//go:build unix
+
+package shm
+
+import (
+	"os"
+	"syscall"
+)
+
+// Mapping is a Ring over a file mapped into memory, shared by every process that maps the
+// same file, e.g. under /dev/shm.
+type Mapping struct {
+	*Ring
+	data []byte
+}
+
+// Create creates the file of path (it must not exist) of a ring of capacity slots of slotSize
+// bytes, see Init.
+func Create(path string, capacity, slotSize uint64) (*Mapping, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(int64(Size(capacity, slotSize))); err != nil {
+		return nil, err
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, Size(capacity, slotSize), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Init(data, capacity, slotSize)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	return &Mapping{Ring: r, data: data}, nil
+}
+
+// Open maps the file of path created by Create, maybe by another process, see Attach.
+func Open(path string) (*Mapping, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < HeaderSize {
+		return nil, ErrNotInitialized
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Attach(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	return &Mapping{Ring: r, data: data}, nil
+}
+
+// Close unmaps the file, the ring must not be used after. The file is left for the others,
+// remove it once all done.
+func (m *Mapping) Close() error {
+	return syscall.Munmap(m.data)
+}
@@ -0,0 +1,120 @@
+//go:build unix
+
+package shm
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync/atomic"
+	"syscall"
+)
+
+// Notifier notifies a consumer of Ring by the readiness of a file descriptor, for the event
+// loops that wait by epoll (or kqueue, select) rather than a futex, see the package doc for
+// the protocol. It's an eventfd on Linux and a pipe elsewhere, both non-blocking. The
+// descriptors can be passed to another process, e.g. by exec.Cmd.ExtraFiles, and wrapped
+// back by NotifierFromFds.
+type Notifier struct {
+	readFd  int
+	writeFd int
+}
+
+// NewNotifier build a Notifier, either of eventfd or pipe.
+func NewNotifier() (*Notifier, error) {
+	readFd, writeFd, err := newNotifierFds()
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{readFd: readFd, writeFd: writeFd}, nil
+}
+
+// NotifierFromFds wraps the descriptors of a Notifier, which are the same for an eventfd. A
+// process only notifying may pass -1 as readFd, one only waiting -1 as writeFd.
+func NotifierFromFds(readFd, writeFd int) *Notifier {
+	return &Notifier{readFd: readFd, writeFd: writeFd}
+}
+
+// ReadFd returns the descriptor to wait for readability.
+func (n *Notifier) ReadFd() int {
+	return n.readFd
+}
+
+// WriteFd returns the descriptor written by Notify.
+func (n *Notifier) WriteFd() int {
+	return n.writeFd
+}
+
+// Notify makes the descriptor readable. A full pipe (or a saturated eventfd) is readable
+// already, so it's not an error.
+func (n *Notifier) Notify() error {
+	var one [8]byte
+	binary.LittleEndian.PutUint64(one[:], 1)
+	_, err := syscall.Write(n.writeFd, one[:])
+	if errors.Is(err, syscall.EAGAIN) {
+		return nil
+	}
+	return err
+}
+
+// Drain reads the descriptor until it would block, returns whether anything read, i.e.
+// notified since the last Drain.
+func (n *Notifier) Drain() (notified bool, err error) {
+	var buf [64]byte
+	for {
+		m, err := syscall.Read(n.readFd, buf[:])
+		if errors.Is(err, syscall.EAGAIN) {
+			return notified, nil
+		}
+		if err != nil {
+			return notified, err
+		}
+		if m == 0 {
+			return notified, nil
+		}
+		notified = true
+	}
+}
+
+// Close closes the descriptors.
+func (n *Notifier) Close() error {
+	var err error
+	if n.readFd >= 0 {
+		err = syscall.Close(n.readFd)
+	}
+	if n.writeFd >= 0 && n.writeFd != n.readFd {
+		if closeErr := syscall.Close(n.writeFd); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// SetNotifier makes the offers of this process notify n when a consumer armed, see Arm. It
+// must be set before the ring is used.
+func (r *Ring) SetNotifier(n *Notifier) {
+	r.notifier = n
+}
+
+// Arm asks the producers to notify by Notifier on the next publish, then returns whether the
+// ring has values already, the consumer should poll rather than wait then:
+//
+//	for {
+//		for { // poll until empty
+//			v, ok := ring.Poll(buf[:0])
+//			...
+//		}
+//		if !ring.Arm() {
+//			epollWait(notifier.ReadFd())
+//			notifier.Drain()
+//		}
+//	}
+func (r *Ring) Arm() (ready bool) {
+	atomic.StoreUint32(r.word32(offsetNotifyArmed), 1)
+	return r.Len() > 0
+}
+
+func (r *Ring) notify() {
+	if r.notifier != nil && atomic.SwapUint32(r.word32(offsetNotifyArmed), 0) == 1 {
+		r.notifier.Notify()
+	}
+}
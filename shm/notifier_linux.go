@@ -0,0 +1,16 @@
+//go:build linux
+
+package shm
+
+import (
+	"syscall"
+)
+
+// newNotifierFds returns an eventfd as both the read and write descriptor.
+func newNotifierFds() (readFd, writeFd int, err error) {
+	fd, _, errno := syscall.Syscall(syscall.SYS_EVENTFD2, 0, syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if errno != 0 {
+		return -1, -1, errno
+	}
+	return int(fd), int(fd), nil
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package shm
+
+import (
+	"errors"
+)
+
+// Notifier is only supported on unix.
+type Notifier struct{}
+
+// NewNotifier is not supported on this platform.
+func NewNotifier() (*Notifier, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// SetNotifier does nothing on this platform.
+func (r *Ring) SetNotifier(n *Notifier) {}
+
+// Arm returns whether the ring has values, there's no notification on this platform.
+func (r *Ring) Arm() (ready bool) {
+	return r.Len() > 0
+}
+
+func (r *Ring) notify() {}
@@ -0,0 +1,24 @@
+//go:build unix && !linux
+
+package shm
+
+import (
+	"syscall"
+)
+
+// newNotifierFds returns the ends of a non-blocking pipe.
+func newNotifierFds() (readFd, writeFd int, err error) {
+	var fds [2]int
+	if err := syscall.Pipe(fds[:]); err != nil {
+		return -1, -1, err
+	}
+	for _, fd := range fds {
+		syscall.CloseOnExec(fd)
+		if err := syscall.SetNonblock(fd, true); err != nil {
+			syscall.Close(fds[0])
+			syscall.Close(fds[1])
+			return -1, -1, err
+		}
+	}
+	return fds[0], fds[1], nil
+}
@@ -0,0 +1,77 @@
+//go:build unix
+
+package shm
+
+import (
+	. "gopkg.in/check.v1"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+func (s *MySuite) TestNotifier(c *C) {
+	// given
+	n, err := NewNotifier()
+	c.Assert(err, IsNil)
+	defer n.Close()
+	r, _ := Init(newRegion(4, 8), 4, 8)
+	r.SetNotifier(n)
+
+	// when
+	r.Offer([]byte("unarmed"))
+	quiet, _ := n.Drain()
+	ready := r.Arm()
+	r.Poll(nil)
+	empty := r.Arm()
+	r.Offer([]byte("a"))
+	r.Offer([]byte("b"))
+	notified, err := n.Drain()
+	again, _ := n.Drain()
+
+	// then notified once per arm
+	c.Assert(quiet, Equals, false)
+	c.Assert(ready, Equals, true)
+	c.Assert(empty, Equals, false)
+	c.Assert(err, IsNil)
+	c.Assert(notified, Equals, true)
+	c.Assert(again, Equals, false)
+}
+
+// TestNotifierCInterop is notified by the offers of a C process.
+func (s *MySuite) TestNotifierCInterop(c *C) {
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		c.Skip("no C compiler")
+	}
+
+	// given
+	dir := c.MkDir()
+	bin := filepath.Join(dir, "interop")
+	out, err := exec.Command(cc, "-std=c11", "-D_DEFAULT_SOURCE", "-Wall", "-Werror", "-o", bin, "testdata/interop.c").CombinedOutput()
+	c.Assert(err, IsNil, Commentf("%s", out))
+	m, err := Create(filepath.Join(dir, "ring"), 8, 64)
+	c.Assert(err, IsNil)
+	defer m.Close()
+	n, err := NewNotifier()
+	c.Assert(err, IsNil)
+	defer n.Close()
+	c.Assert(m.Arm(), Equals, false)
+
+	// when the descriptor is passed as fd 3
+	fd, err := syscall.Dup(n.WriteFd())
+	c.Assert(err, IsNil)
+	file := os.NewFile(uintptr(fd), "notifier")
+	defer file.Close()
+	cmd := exec.Command(bin, filepath.Join(dir, "ring"), "from c")
+	cmd.ExtraFiles = []*os.File{file}
+	cmd.Env = append(os.Environ(), "LFRING_NOTIFY_FD=3")
+	out, err = cmd.CombinedOutput()
+
+	// then
+	c.Assert(err, IsNil, Commentf("%s", out))
+	notified, _ := n.Drain()
+	c.Assert(notified, Equals, true)
+	v, _ := m.Poll(nil)
+	c.Assert(string(v), Equals, "from c")
+}
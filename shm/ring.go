@@ -0,0 +1,229 @@
+package shm
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+	"unsafe"
+
+	lfring "github.com/gsingh-ds/go-lock-free-ring-buffer"
+)
+
+// Ring is a ring buffer over a shared memory region, see the package doc for the layout. It's
+// safe for concurrent use by any number of goroutines and processes.
+type Ring struct {
+	region   []byte
+	mask     uint64
+	slotSize uint64
+	stride   uint64
+	notifier *Notifier
+}
+
+// Init formats region as an empty ring of capacity slots of slotSize bytes, and returns it.
+// It must be done once by one process before the others Attach. The capacity must be
+// power-of-two, slotSize multiple of 8, and region at least Size(capacity, slotSize) bytes.
+func Init(region []byte, capacity, slotSize uint64) (*Ring, error) {
+	r, err := newRing(region, capacity, slotSize)
+	if err != nil {
+		return nil, err
+	}
+
+	clear(region[:Size(capacity, slotSize)])
+	le := binary.LittleEndian
+	le.PutUint32(region[offsetVersion:], Version)
+	le.PutUint64(region[offsetCapacity:], capacity)
+	le.PutUint64(region[offsetSlotSize:], slotSize)
+	for i := uint64(0); i < capacity; i++ {
+		atomic.StoreUint64(r.step(i), i)
+	}
+	atomic.StoreUint32(r.word32(offsetMagic), Magic)
+	return r, nil
+}
+
+// Attach returns the ring in region initialized by Init, maybe by another process.
+func Attach(region []byte) (*Ring, error) {
+	if len(region) < HeaderSize {
+		return nil, ErrLayout
+	}
+	if atomic.LoadUint32((*uint32)(unsafe.Pointer(&region[offsetMagic]))) != Magic {
+		return nil, ErrNotInitialized
+	}
+
+	le := binary.LittleEndian
+	if le.Uint32(region[offsetVersion:]) != Version {
+		return nil, ErrVersion
+	}
+	return newRing(region, le.Uint64(region[offsetCapacity:]), le.Uint64(region[offsetSlotSize:]))
+}
+
+func newRing(region []byte, capacity, slotSize uint64) (*Ring, error) {
+	if !littleEndianHost() {
+		return nil, ErrBigEndian
+	}
+	if capacity == 0 || capacity&(capacity-1) != 0 || slotSize%8 != 0 || slotSize >= Discarded {
+		return nil, ErrLayout
+	}
+	if len(region) < Size(capacity, slotSize) || uintptr(unsafe.Pointer(&region[0]))%8 != 0 {
+		return nil, ErrLayout
+	}
+
+	return &Ring{
+		region:   region,
+		mask:     capacity - 1,
+		slotSize: slotSize,
+		stride:   SlotHeaderSize + slotSize,
+	}, nil
+}
+
+// Offer copies p into a slot, return false if ring is full or the claim lost in contention,
+// or lfring.ErrSlotTooSmall if p is longer than the slot size.
+func (r *Ring) Offer(p []byte) (success bool, err error) {
+	if uint64(len(p)) > r.slotSize {
+		return false, lfring.ErrSlotTooSmall
+	}
+	return r.OfferFunc(func(slot []byte) (int, error) {
+		return copy(slot, p), nil
+	})
+}
+
+// OfferFunc claims a slot and calls fill to write the payload in place, fill returns how many
+// bytes written. If fill fails the slot is published as Discarded (consumers skip it) and the
+// error is returned. Return false if ring is full or the claim lost in contention.
+func (r *Ring) OfferFunc(fill func(slot []byte) (n int, err error)) (success bool, err error) {
+	tail := r.word(offsetTail)
+	oldTail := atomic.LoadUint64(tail)
+	step := r.step(oldTail)
+	// not polled yet
+	if atomic.LoadUint64(step) != oldTail {
+		return false, nil
+	}
+	if !atomic.CompareAndSwapUint64(tail, oldTail, oldTail+1) {
+		return false, nil
+	}
+
+	n, err := fill(r.payload(oldTail))
+	length := uint32(n)
+	if err != nil || uint64(n) > r.slotSize {
+		length = Discarded
+	}
+	binary.LittleEndian.PutUint32(r.slot(oldTail)[slotOffsetLength:], length)
+	atomic.StoreUint64(step, oldTail+1)
+	r.wake(offsetConsumerWaiters, offsetReadable)
+	r.notify()
+	if err == nil && length == Discarded {
+		err = lfring.ErrSlotTooSmall
+	}
+	return err == nil, err
+}
+
+// Poll appends the payload of head slot to dst and returns it, return false if ring is
+// empty or the claim lost in contention.
+func (r *Ring) Poll(dst []byte) (out []byte, success bool) {
+	success = r.PollFunc(func(data []byte) {
+		out = append(dst, data...)
+	})
+	if !success {
+		return dst, false
+	}
+	return out, true
+}
+
+// PollFunc claims head slot and calls read with the payload in place, which is only valid
+// during the call. Return false if ring is empty or the claim lost in contention, the
+// Discarded slots are skipped.
+func (r *Ring) PollFunc(read func(data []byte)) (success bool) {
+	head := r.word(offsetHead)
+	for {
+		oldHead := atomic.LoadUint64(head)
+		step := r.step(oldHead)
+		// not published yet
+		if atomic.LoadUint64(step) != oldHead+1 {
+			return false
+		}
+		if !atomic.CompareAndSwapUint64(head, oldHead, oldHead+1) {
+			return false
+		}
+
+		length := binary.LittleEndian.Uint32(r.slot(oldHead)[slotOffsetLength:])
+		if length != Discarded {
+			read(r.payload(oldHead)[:length])
+		}
+		atomic.StoreUint64(step, oldHead+r.mask+1)
+		r.wake(offsetProducerWaiters, offsetWritable)
+		if length != Discarded {
+			return true
+		}
+	}
+}
+
+// Len returns the number of slots offered but not polled yet.
+func (r *Ring) Len() uint64 {
+	tail := atomic.LoadUint64(r.word(offsetTail))
+	head := atomic.LoadUint64(r.word(offsetHead))
+	if tail < head {
+		return 0
+	}
+	return tail - head
+}
+
+// Cap returns the number of slots.
+func (r *Ring) Cap() uint64 {
+	return r.mask + 1
+}
+
+// SlotSize returns the bytes of payload per slot.
+func (r *Ring) SlotSize() uint64 {
+	return r.slotSize
+}
+
+func (r *Ring) word(offset uint64) *uint64 {
+	return (*uint64)(unsafe.Pointer(&r.region[offset]))
+}
+
+func (r *Ring) word32(offset uint64) *uint32 {
+	return (*uint32)(unsafe.Pointer(&r.region[offset]))
+}
+
+func (r *Ring) slot(seq uint64) []byte {
+	offset := HeaderSize + (seq&r.mask)*r.stride
+	return r.region[offset : offset+r.stride]
+}
+
+func (r *Ring) step(seq uint64) *uint64 {
+	return r.word(HeaderSize + (seq&r.mask)*r.stride + slotOffsetStep)
+}
+
+func (r *Ring) payload(seq uint64) []byte {
+	return r.slot(seq)[SlotHeaderSize:]
+}
+
+// Typed is a Ring of values of T, moved in and out of the slots by a Codec.
+type Typed[T any] struct {
+	ring  *Ring
+	codec lfring.Codec[T]
+}
+
+// NewTyped build a Typed over ring by codec.
+func NewTyped[T any](ring *Ring, codec lfring.Codec[T]) *Typed[T] {
+	return &Typed[T]{ring: ring, codec: codec}
+}
+
+// Offer marshals v right into a slot, see Ring.OfferFunc.
+func (t *Typed[T]) Offer(v T) (success bool, err error) {
+	return t.ring.OfferFunc(func(slot []byte) (int, error) {
+		return t.codec.Marshal(slot, v)
+	})
+}
+
+// Poll unmarshals the value of head slot, see Ring.PollFunc. The slot is consumed even if
+// Unmarshal fails, the error is returned then.
+func (t *Typed[T]) Poll() (v T, success bool, err error) {
+	success = t.ring.PollFunc(func(data []byte) {
+		v, err = t.codec.Unmarshal(data)
+	})
+	return v, success && err == nil, err
+}
+
+// Ring returns the underlying ring.
+func (t *Typed[T]) Ring() *Ring {
+	return t.ring
+}
@@ -0,0 +1,286 @@
+package shm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	. "gopkg.in/check.v1"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+
+	lfring "github.com/gsingh-ds/go-lock-free-ring-buffer"
+)
+
+// hook up go-check to go testing
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// newRegion returns an 8-byte aligned region.
+func newRegion(capacity, slotSize uint64) []byte {
+	words := make([]uint64, (Size(capacity, slotSize)+7)/8)
+	return unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), len(words)*8)
+}
+
+func (s *MySuite) TestRingOfferPoll(c *C) {
+	// given
+	r, err := Init(newRegion(2, 8), 2, 8)
+	c.Assert(err, IsNil)
+
+	// when
+	first, _ := r.Offer([]byte("ab"))
+	second, _ := r.Offer([]byte("abcdefgh"))
+	full, _ := r.Offer([]byte("c"))
+	_, tooLong := r.Offer([]byte("abcdefghi"))
+	v1, _ := r.Poll(nil)
+	v2, _ := r.Poll([]byte("x"))
+	_, empty := r.Poll(nil)
+
+	// then
+	c.Assert(first, Equals, true)
+	c.Assert(second, Equals, true)
+	c.Assert(full, Equals, false)
+	c.Assert(tooLong, Equals, lfring.ErrSlotTooSmall)
+	c.Assert(string(v1), Equals, "ab")
+	c.Assert(string(v2), Equals, "xabcdefgh")
+	c.Assert(empty, Equals, false)
+	c.Assert(r.Len(), Equals, uint64(0))
+}
+
+func (s *MySuite) TestRingDiscarded(c *C) {
+	// given
+	r, _ := Init(newRegion(4, 8), 4, 8)
+	fillErr := errors.New("fill")
+
+	// when a producer gives up after claimed
+	_, err := r.OfferFunc(func([]byte) (int, error) { return 0, fillErr })
+	r.Offer([]byte("a"))
+	v, success := r.Poll(nil)
+
+	// then the discarded slot is skipped
+	c.Assert(err, Equals, fillErr)
+	c.Assert(success, Equals, true)
+	c.Assert(string(v), Equals, "a")
+	c.Assert(r.Len(), Equals, uint64(0))
+}
+
+func (s *MySuite) TestAttach(c *C) {
+	// given
+	region := newRegion(4, 8)
+
+	// when
+	_, notInitialized := Attach(region)
+	Init(region, 4, 8)
+	r, err := Attach(region)
+	_, badCapacity := Init(newRegion(3, 8), 3, 8)
+	_, badSlot := Init(newRegion(4, 6), 4, 6)
+	_, tooSmall := Init(region[:Size(4, 8)-1], 4, 8)
+	_, unaligned := Init(region[1:], 2, 8)
+	region[offsetVersion] = 2
+	_, badVersion := Attach(region)
+
+	// then
+	c.Assert(notInitialized, Equals, ErrNotInitialized)
+	c.Assert(err, IsNil)
+	c.Assert(r.Cap(), Equals, uint64(4))
+	c.Assert(r.SlotSize(), Equals, uint64(8))
+	c.Assert(badCapacity, Equals, ErrLayout)
+	c.Assert(badSlot, Equals, ErrLayout)
+	c.Assert(tooSmall, Equals, ErrLayout)
+	c.Assert(unaligned, Equals, ErrLayout)
+	c.Assert(badVersion, Equals, ErrVersion)
+}
+
+type sample struct {
+	ID    uint64
+	Value int32
+}
+
+func (s *MySuite) TestTyped(c *C) {
+	// given
+	r, _ := Init(newRegion(4, 16), 4, 16)
+	typed := NewTyped(r, lfring.BinaryCodec[sample]())
+
+	// when
+	success, err := typed.Offer(sample{ID: 1, Value: -2})
+	v, polled, pollErr := typed.Poll()
+
+	// then
+	c.Assert(success, Equals, true)
+	c.Assert(err, IsNil)
+	c.Assert(polled, Equals, true)
+	c.Assert(pollErr, IsNil)
+	c.Assert(v, Equals, sample{ID: 1, Value: -2})
+}
+
+func (s *MySuite) TestMappingConcurrency(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("mapping is unix only")
+	}
+
+	// given two mappings of the same file
+	path := filepath.Join(c.MkDir(), "ring")
+	producer, err := Create(path, 8, 8)
+	c.Assert(err, IsNil)
+	defer producer.Close()
+	consumer, err := Open(path)
+	c.Assert(err, IsNil)
+	defer consumer.Close()
+	_, exists := Create(path, 8, 8)
+	c.Assert(exists, NotNil)
+
+	// when
+	const total = 1000
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; {
+			if ok, _ := producer.Offer([]byte(strconv.Itoa(i))); ok {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	// then
+	var buf []byte
+	for i := 0; i < total; {
+		v, ok := consumer.Poll(buf[:0])
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		c.Assert(string(v), Equals, strconv.Itoa(i))
+		buf = v
+		i++
+	}
+	wg.Wait()
+}
+
+// TestHeaderMatchesLayout validates the constants of lfring_shm.h against the Go ones.
+func (s *MySuite) TestHeaderMatchesLayout(c *C) {
+	// given
+	f, err := os.Open("lfring_shm.h")
+	c.Assert(err, IsNil)
+	defer f.Close()
+	want := map[string]uint64{
+		"LFRING_SHM_MAGIC":                   Magic,
+		"LFRING_SHM_VERSION":                 Version,
+		"LFRING_SHM_HEADER_SIZE":             HeaderSize,
+		"LFRING_SHM_SLOT_HEADER_SIZE":        SlotHeaderSize,
+		"LFRING_SHM_DISCARDED":               Discarded,
+		"LFRING_SHM_OFFSET_MAGIC":            offsetMagic,
+		"LFRING_SHM_OFFSET_VERSION":          offsetVersion,
+		"LFRING_SHM_OFFSET_CAPACITY":         offsetCapacity,
+		"LFRING_SHM_OFFSET_SLOT_SIZE":        offsetSlotSize,
+		"LFRING_SHM_OFFSET_CONSUMER_WAITERS": offsetConsumerWaiters,
+		"LFRING_SHM_OFFSET_PRODUCER_WAITERS": offsetProducerWaiters,
+		"LFRING_SHM_OFFSET_READABLE":         offsetReadable,
+		"LFRING_SHM_OFFSET_WRITABLE":         offsetWritable,
+		"LFRING_SHM_OFFSET_NOTIFY_ARMED":     offsetNotifyArmed,
+		"LFRING_SHM_OFFSET_HEAD":             offsetHead,
+		"LFRING_SHM_OFFSET_TAIL":             offsetTail,
+		"LFRING_SHM_SLOT_OFFSET_STEP":        slotOffsetStep,
+		"LFRING_SHM_SLOT_OFFSET_LENGTH":      slotOffsetLength,
+	}
+
+	// when
+	got := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "#define" {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[2], 0, 64)
+		c.Assert(err, IsNil, Commentf("%s", fields[1]))
+		got[fields[1]] = v
+	}
+
+	// then
+	c.Assert(got, DeepEquals, want)
+}
+
+// TestCInterop exchanges messages with a C process by the reference header.
+func (s *MySuite) TestCInterop(c *C) {
+	cc, err := exec.LookPath("cc")
+	if err != nil || runtime.GOOS == "windows" {
+		c.Skip("no C compiler")
+	}
+
+	// given
+	dir := c.MkDir()
+	bin := filepath.Join(dir, "interop")
+	out, err := exec.Command(cc, "-std=c11", "-D_DEFAULT_SOURCE", "-Wall", "-Werror", "-o", bin, "testdata/interop.c").CombinedOutput()
+	c.Assert(err, IsNil, Commentf("%s", out))
+	path := filepath.Join(dir, "ring")
+	m, err := Create(path, 8, 64)
+	c.Assert(err, IsNil)
+	defer m.Close()
+	m.Offer([]byte("from go"))
+
+	// when
+	out, err = exec.Command(bin, path, "from c", "again").CombinedOutput()
+
+	// then
+	c.Assert(err, IsNil, Commentf("%s", out))
+	c.Assert(string(out), Equals, "from go\n")
+	first, _ := m.Poll(nil)
+	second, _ := m.Poll(nil)
+	c.Assert(string(first), Equals, "from c")
+	c.Assert(string(second), Equals, "again")
+}
+
+func (s *MySuite) TestWait(c *C) {
+	// given
+	r, _ := Init(newRegion(2, 8), 2, 8)
+	r.Offer([]byte("a"))
+	r.Offer([]byte("b"))
+
+	// when a producer waits for room and a consumer waits for values
+	offered := make(chan error)
+	go func() {
+		offered <- r.OfferWait(context.Background(), []byte("c"))
+	}()
+	for atomic.LoadUint32(r.word32(offsetProducerWaiters)) == 0 {
+		runtime.Gosched()
+	}
+	polled := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		v, err := r.PollWait(context.Background(), nil)
+		c.Assert(err, IsNil)
+		polled = append(polled, string(v))
+	}
+
+	// then
+	c.Assert(<-offered, IsNil)
+	c.Assert(polled, DeepEquals, []string{"a", "b", "c"})
+	c.Assert(atomic.LoadUint32(r.word32(offsetProducerWaiters)), Equals, uint32(0))
+	c.Assert(atomic.LoadUint32(r.word32(offsetConsumerWaiters)), Equals, uint32(0))
+}
+
+func (s *MySuite) TestPollWaitContext(c *C) {
+	// given
+	r, _ := Init(newRegion(2, 8), 2, 8)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := r.PollWait(ctx, nil)
+
+	// then
+	c.Assert(err, Equals, context.DeadlineExceeded)
+}
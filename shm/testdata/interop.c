@@ -0,0 +1,40 @@
+/* interop polls the messages offered by the Go side from the ring mapped from the file of
+ * argv[1] and prints them, then offers the rest of arguments, waiting while the ring is full.
+ * The offers notify the descriptor of LFRING_NOTIFY_FD if set. */
+#include <fcntl.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <sys/mman.h>
+#include <sys/stat.h>
+#include <unistd.h>
+
+#include "../lfring_shm.h"
+
+int main(int argc, char **argv) {
+	int fd = open(argv[1], O_RDWR);
+	struct stat st;
+	if (fd < 0 || fstat(fd, &st) != 0) {
+		return 2;
+	}
+	void *region = mmap(NULL, st.st_size, PROT_READ | PROT_WRITE, MAP_SHARED, fd, 0);
+	lfring_shm ring;
+	if (region == MAP_FAILED || lfring_shm_attach(&ring, region, st.st_size) != 0) {
+		return 3;
+	}
+	const char *notify_fd = getenv("LFRING_NOTIFY_FD");
+	if (notify_fd != NULL) {
+		ring.notify_fd = atoi(notify_fd);
+	}
+
+	char buf[256];
+	int64_t n;
+	while ((n = lfring_shm_poll(&ring, buf, sizeof(buf))) >= 0) {
+		printf("%.*s\n", (int)n, buf);
+	}
+	for (int i = 2; i < argc; i++) {
+		if (!lfring_shm_offer_wait(&ring, argv[i], strlen(argv[i]))) {
+			return 4;
+		}
+	}
+	return 0;
+}
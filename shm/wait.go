@@ -0,0 +1,63 @@
+package shm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// waitTimeout bounds a sleep, for the peers that don't wake and for the context checks.
+const waitTimeout = 10 * time.Millisecond
+
+// OfferWait offers p, sleeps while ring is full (on a futex on Linux, polling elsewhere)
+// until ctx done, see the package doc for the waiting protocol.
+func (r *Ring) OfferWait(ctx context.Context, p []byte) error {
+	return r.await(ctx, offsetProducerWaiters, offsetWritable, func() (bool, error) {
+		return r.Offer(p)
+	})
+}
+
+// PollWait appends the payload of head slot to dst and returns it, sleeps while ring is
+// empty until ctx done, see OfferWait.
+func (r *Ring) PollWait(ctx context.Context, dst []byte) (out []byte, err error) {
+	err = r.await(ctx, offsetConsumerWaiters, offsetReadable, func() (success bool, err error) {
+		out, success = r.Poll(dst)
+		return success, nil
+	})
+	return out, err
+}
+
+// await retries try until success, sleeps on the word of seqOffset between two tries.
+func (r *Ring) await(ctx context.Context, waitersOffset, seqOffset uint64, try func() (bool, error)) error {
+	waiters := r.word32(waitersOffset)
+	seq := r.word32(seqOffset)
+	for {
+		if success, err := try(); success || err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		w := atomic.LoadUint32(seq)
+		atomic.AddUint32(waiters, 1)
+		success, err := try()
+		if !success && err == nil {
+			futexWait(seq, w, waitTimeout)
+		}
+		atomic.AddUint32(waiters, ^uint32(0))
+		if success || err != nil {
+			return err
+		}
+	}
+}
+
+// wake bumps the word of seqOffset and wakes its sleepers, if any.
+func (r *Ring) wake(waitersOffset, seqOffset uint64) {
+	if atomic.LoadUint32(r.word32(waitersOffset)) == 0 {
+		return
+	}
+	seq := r.word32(seqOffset)
+	atomic.AddUint32(seq, 1)
+	futexWake(seq)
+}
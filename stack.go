@@ -0,0 +1,98 @@
+package lfring
+
+import (
+	"sync/atomic"
+)
+
+// Stack is the lock-free LIFO stack of R. Kent Treiber, the companion of the FIFO ring
+// buffers for the cases prefer the most recent value, e.g. the schedulers reuse the warmest
+// task or buffer in cache.
+//
+// Every Push links a new node on top by CAS, Pop swings top to the next node by CAS, both
+// retry until success, so a thread may starve but the stack as a whole always progresses.
+// Nodes are never reused (the garbage collector frees them), hence the ABA problem of the
+// classical Treiber stack can't happen. A stack built with a capacity is bounded, Push fails
+// when it's full.
+type Stack[T any] struct {
+	top       atomic.Pointer[stackNode[T]]
+	_padding0 [56]byte
+	size      atomic.Uint64
+	capacity  uint64
+}
+
+type stackNode[T any] struct {
+	value T
+	next  *stackNode[T]
+}
+
+// NewStack build a Stack holds at most capacity values, zero means unbounded. Unlike the
+// ring buffers, capacity is exact rather than expanded to power-of-two.
+func NewStack[T any](capacity uint64) *Stack[T] {
+	return &Stack[T]{capacity: capacity}
+}
+
+// Push puts value on top, return false if the bounded stack is full.
+func (s *Stack[T]) Push(value T) (success bool) {
+	if !s.reserve() {
+		return false
+	}
+
+	n := &stackNode[T]{value: value}
+	for {
+		n.next = s.top.Load()
+		if s.top.CompareAndSwap(n.next, n) {
+			return true
+		}
+	}
+}
+
+// reserve claims a place of the bounded stack ahead of the push, so the size never exceeds
+// capacity.
+func (s *Stack[T]) reserve() bool {
+	if s.capacity == 0 {
+		s.size.Add(1)
+		return true
+	}
+
+	for {
+		size := s.size.Load()
+		if size >= s.capacity {
+			return false
+		}
+		if s.size.CompareAndSwap(size, size+1) {
+			return true
+		}
+	}
+}
+
+// Pop takes the top value, return false if stack is empty.
+func (s *Stack[T]) Pop() (value T, success bool) {
+	for {
+		top := s.top.Load()
+		if top == nil {
+			return
+		}
+		if s.top.CompareAndSwap(top, top.next) {
+			s.size.Add(^uint64(0))
+			return top.value, true
+		}
+	}
+}
+
+// Peek returns the top value without taking it, return false if stack is empty.
+func (s *Stack[T]) Peek() (value T, success bool) {
+	if top := s.top.Load(); top != nil {
+		return top.value, true
+	}
+	return
+}
+
+// Len returns the number of values, including the ones being pushed.
+func (s *Stack[T]) Len() uint64 {
+	return s.size.Load()
+}
+
+// Cap returns the capacity, zero means unbounded.
+func (s *Stack[T]) Cap() uint64 {
+	return s.capacity
+}
@@ -0,0 +1,82 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"sync"
+)
+
+func (s *MySuite) TestStackLIFO(c *C) {
+	// given
+	stack := NewStack[int](0)
+
+	// when
+	for i := 0; i < 3; i++ {
+		stack.Push(i)
+	}
+	top, _ := stack.Peek()
+	var values []int
+	for v, ok := stack.Pop(); ok; v, ok = stack.Pop() {
+		values = append(values, v)
+	}
+
+	// then
+	c.Assert(top, Equals, 2)
+	c.Assert(values, DeepEquals, []int{2, 1, 0})
+	c.Assert(stack.Len(), Equals, uint64(0))
+	_, ok := stack.Peek()
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestStackBounded(c *C) {
+	// given
+	stack := NewStack[int](2)
+
+	// when
+	first, second, third := stack.Push(1), stack.Push(2), stack.Push(3)
+	stack.Pop()
+	again := stack.Push(4)
+
+	// then
+	c.Assert([]bool{first, second, third, again}, DeepEquals, []bool{true, true, false, true})
+	c.Assert(stack.Len(), Equals, uint64(2))
+	c.Assert(stack.Cap(), Equals, uint64(2))
+}
+
+func (s *MySuite) TestStackConcurrency(c *C) {
+	// given
+	const goroutines, perGoroutine = 4, 1000
+	stack := NewStack[int](goroutines * perGoroutine)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+
+	// when every goroutine pushes and pops alternately
+	wg.Add(goroutines)
+	for g := 0; g < goroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+			var popped []int
+			for i := 0; i < perGoroutine; i++ {
+				c.Check(stack.Push(g*perGoroutine+i), Equals, true)
+				if i%2 == 1 {
+					v, _ := stack.Pop()
+					popped = append(popped, v)
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, v := range popped {
+				c.Check(seen[v], Equals, false)
+				seen[v] = true
+			}
+		}(g)
+	}
+	wg.Wait()
+	for v, ok := stack.Pop(); ok; v, ok = stack.Pop() {
+		c.Check(seen[v], Equals, false)
+		seen[v] = true
+	}
+
+	// then
+	c.Assert(len(seen), Equals, goroutines*perGoroutine)
+}
@@ -0,0 +1,105 @@
+package lfring
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TailHandler returns a debug handler that streams the values of m as Server-Sent Events, so
+// operators can watch what's flowing through the buffer live:
+//
+//	http.Handle("/debug/orders/tail", lfring.TailHandler(orders, json.Marshal, 100*time.Millisecond))
+//
+//	$ curl -N localhost:8080/debug/orders/tail?from=earliest
+//
+// Every request tails by its own Cursor, which neither consumes the values nor blocks the
+// producers. It starts from the next value published by default, the query from=earliest
+// replays from the earliest retained value, and from=<seq> from the sequence. A value is sent
+// as a message whose id is its sequence and data is marshal of it, the values overwritten
+// before sent are reported by a "skipped" event with the count. The cursor checks for new
+// values every interval while idle, until the client disconnects.
+func TailHandler[T any](m *Multicast[T], marshal func(T) ([]byte, error), interval time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		var cursor *Cursor[T]
+		switch from := req.URL.Query().Get("from"); from {
+		case "":
+			cursor = m.Cursor()
+		case "earliest":
+			cursor = m.CursorFromEarliest()
+		default:
+			seq, err := strconv.ParseUint(from, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid from: "+from, http.StatusBadRequest)
+				return
+			}
+			cursor = m.CursorFrom(seq)
+		}
+
+		header := w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		done := req.Context().Done()
+		var event bytes.Buffer
+		for {
+			skipped := cursor.Skipped()
+			for {
+				seq := cursor.Sequence()
+				v, success := cursor.Next()
+				if !success {
+					break
+				}
+				if gap := cursor.Skipped() - skipped; gap > 0 {
+					skipped += gap
+					event.WriteString("event: skipped\ndata: " + strconv.FormatUint(gap, 10) + "\n\n")
+					seq += gap
+				}
+
+				data, err := marshal(v)
+				if err != nil {
+					event.WriteString("event: error\ndata: " + err.Error() + "\n\n")
+					continue
+				}
+				writeEvent(&event, seq, data)
+			}
+
+			if event.Len() > 0 {
+				if _, err := w.Write(event.Bytes()); err != nil {
+					return
+				}
+				event.Reset()
+				flusher.Flush()
+			}
+
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// writeEvent writes a message of Server-Sent Events, a data of many lines takes a field per
+// line.
+func writeEvent(event *bytes.Buffer, id uint64, data []byte) {
+	event.WriteString("id: " + strconv.FormatUint(id, 10) + "\n")
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		event.WriteString("data: ")
+		event.Write(line)
+		event.WriteByte('\n')
+	}
+	event.WriteByte('\n')
+}
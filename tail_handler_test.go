@@ -0,0 +1,91 @@
+package lfring
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	. "gopkg.in/check.v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// readEvents reads n events from the stream of Server-Sent Events.
+func readEvents(c *C, r *bufio.Reader, n int) []string {
+	var events []string
+	var event strings.Builder
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		c.Assert(err, IsNil)
+		if line == "\n" {
+			events = append(events, event.String())
+			event.Reset()
+			continue
+		}
+		event.WriteString(line)
+	}
+	return events
+}
+
+func (s *MySuite) TestTailHandler(c *C) {
+	// given
+	m := NewMulticast[string](2)
+	m.Offer("a")
+	for _, v := range []string{"b", "c", "d"} {
+		m.Offer(v)
+	}
+	server := httptest.NewServer(TailHandler(m, func(v string) ([]byte, error) {
+		return json.Marshal(map[string]string{"v": v})
+	}, time.Millisecond))
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// when
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?from=0", nil)
+	resp, err := http.DefaultClient.Do(req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	replayed := readEvents(c, r, 3)
+	m.Offer("e")
+	live := readEvents(c, r, 1)
+
+	// then
+	c.Assert(resp.Header.Get("Content-Type"), Equals, "text/event-stream")
+	c.Assert(replayed, DeepEquals, []string{
+		"event: skipped\ndata: 2\n",
+		"id: 2\ndata: {\"v\":\"c\"}\n",
+		"id: 3\ndata: {\"v\":\"d\"}\n",
+	})
+	c.Assert(live, DeepEquals, []string{"id: 4\ndata: {\"v\":\"e\"}\n"})
+}
+
+func (s *MySuite) TestTailHandlerMultiline(c *C) {
+	// given
+	m := NewMulticast[string](4)
+	m.Offer("a\nb")
+	handler := TailHandler(m, func(v string) ([]byte, error) { return []byte(v), nil }, time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// when
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?from=earliest", nil).WithContext(ctx))
+
+	// then
+	c.Assert(rec.Body.String(), Equals, "id: 0\ndata: a\ndata: b\n\n")
+}
+
+func (s *MySuite) TestTailHandlerInvalidFrom(c *C) {
+	// given
+	handler := TailHandler(NewMulticast[string](4), func(v string) ([]byte, error) { return []byte(v), nil }, time.Millisecond)
+
+	// when
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?from=x", nil))
+
+	// then
+	c.Assert(rec.Code, Equals, http.StatusBadRequest)
+}
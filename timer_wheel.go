@@ -0,0 +1,197 @@
+package lfring
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// wheelBits is the bits of tick each level of TimerWheel covers, 64 slots per level
+	wheelBits  = 6
+	wheelSlots = 1 << wheelBits
+	wheelMask  = wheelSlots - 1
+	// wheelLevels levels cover 2^24 ticks, the timers further are placed at the top level
+	// and placed again when it comes
+	wheelLevels = 4
+)
+
+// Cancel cancels the timer returned along, it returns true if the timer won't fire, false if
+// it fired or got canceled already.
+type Cancel func() bool
+
+// TimerWheel is a hierarchical timing wheel for lots of pending timeouts, which costs far
+// less than a runtime timer per timeout: Schedule is a push to an intrusive MPSCQueue, and
+// the driver goroutine moves the wheel a tick at a time, touching only the timers due.
+//
+// Every level is a ring of 64 slots, each slot is a list of timers: the level 0 slot of a
+// tick holds the timers due at that tick, a slot of level l holds the timers due within the
+// 64^l ticks it covers, which cascade down to the lower levels when the tick reaches it.
+// The resolution is a tick, a timer fires at the first tick not earlier than its deadline.
+//
+// The callbacks run in the driver goroutine (see Start and Advance) and should be short,
+// otherwise they delay the timers after them.
+type TimerWheel struct {
+	tick   time.Duration
+	start  time.Time
+	now    atomic.Uint64
+	intake *MPSCQueue[wheelTimer, *wheelTimer]
+	levels [wheelLevels][wheelSlots]*wheelTimer
+
+	driverGuard ownerGuard
+}
+
+type wheelTimer struct {
+	MPSCNode[wheelTimer]
+	deadline uint64
+	fn       func()
+	// state is timerPending, timerFired or timerCanceled
+	state int32
+	next  *wheelTimer
+}
+
+const (
+	timerPending int32 = iota
+	timerFired
+	timerCanceled
+)
+
+// NewTimerWheel build a TimerWheel of the resolution tick, it's at tick 0 now.
+func NewTimerWheel(tick time.Duration) *TimerWheel {
+	return &TimerWheel{
+		tick:   tick,
+		start:  time.Now(),
+		intake: NewMPSCQueue[wheelTimer](),
+	}
+}
+
+// Schedule fn to run after d, it's safe for concurrent use.
+func (w *TimerWheel) Schedule(d time.Duration, fn func()) Cancel {
+	ticks := uint64((d + w.tick - 1) / w.tick)
+	if d <= 0 || ticks == 0 {
+		ticks = 1
+	}
+
+	t := &wheelTimer{deadline: w.now.Load() + ticks, fn: fn}
+	w.intake.Push(t)
+	return func() bool {
+		return atomic.CompareAndSwapInt32(&t.state, timerPending, timerCanceled)
+	}
+}
+
+// Start drives the wheel by a ticker of the resolution in a new goroutine, until the
+// returned stop is called.
+func (w *TimerWheel) Start() (stop func()) {
+	ticker := time.NewTicker(w.tick)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				w.Advance(now)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// Advance moves the wheel to the tick of now, firing the timers due. It's for the callers
+// drive the wheel by their own loop rather than Start, the caller must be the only driver.
+func (w *TimerWheel) Advance(now time.Time) {
+	w.advance(uint64(now.Sub(w.start) / w.tick))
+}
+
+// Now returns the current tick.
+func (w *TimerWheel) Now() uint64 {
+	return w.now.Load()
+}
+
+func (w *TimerWheel) advance(to uint64) {
+	if debugAssertions {
+		w.driverGuard.enter("TimerWheel.Advance")
+		defer w.driverGuard.exit()
+	}
+
+	w.drainIntake()
+	for now := w.now.Load(); now < to; {
+		now++
+		w.now.Store(now)
+		w.cascade(now)
+
+		slot := &w.levels[0][now&wheelMask]
+		timers := *slot
+		*slot = nil
+		w.fire(timers)
+		// the timers scheduled by the callbacks
+		w.drainIntake()
+	}
+}
+
+// cascade places again the timers of the higher level slots that now reaches.
+func (w *TimerWheel) cascade(now uint64) {
+	for level := 1; level < wheelLevels; level++ {
+		if now&(1<<(wheelBits*level)-1) != 0 {
+			return
+		}
+		slot := &w.levels[level][(now>>(wheelBits*level))&wheelMask]
+		timers := *slot
+		*slot = nil
+		for t := timers; t != nil; {
+			next := t.next
+			w.place(t)
+			t = next
+		}
+	}
+}
+
+func (w *TimerWheel) drainIntake() {
+	for {
+		t, ok := w.intake.Pop()
+		if !ok {
+			return
+		}
+		w.place(t)
+	}
+}
+
+// place puts t to the slot of its deadline, or fires it if the deadline has come.
+func (w *TimerWheel) place(t *wheelTimer) {
+	if atomic.LoadInt32(&t.state) != timerPending {
+		return
+	}
+	if t.deadline <= w.now.Load() {
+		t.next = nil
+		w.fire(t)
+		return
+	}
+
+	deadline := t.deadline
+	level := 0
+	for delta := deadline - w.now.Load(); delta >= 1<<(wheelBits*(level+1)); level++ {
+		if level == wheelLevels-1 {
+			// beyond the top level, place at the furthest slot and place again from there
+			deadline = w.now.Load() + 1<<(wheelBits*wheelLevels) - 1
+			break
+		}
+	}
+
+	slot := &w.levels[level][(deadline>>(wheelBits*level))&wheelMask]
+	t.next = *slot
+	*slot = t
+}
+
+func (w *TimerWheel) fire(timers *wheelTimer) {
+	for t := timers; t != nil; {
+		next := t.next
+		t.next = nil
+		if atomic.CompareAndSwapInt32(&t.state, timerPending, timerFired) {
+			t.fn()
+		}
+		t = next
+	}
+}
@@ -0,0 +1,81 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"sync/atomic"
+	"time"
+)
+
+func (s *MySuite) TestTimerWheelFiresAtDeadline(c *C) {
+	// given
+	w := NewTimerWheel(time.Millisecond)
+	fired := make(map[string]uint64)
+	schedule := func(name string, d time.Duration) Cancel {
+		return w.Schedule(d, func() { fired[name] = w.Now() })
+	}
+	schedule("1ms", time.Millisecond)
+	schedule("partial", 1500*time.Microsecond)
+	schedule("level1", 100*time.Millisecond)
+	schedule("level2", 5000*time.Millisecond)
+	cancel := schedule("canceled", 10*time.Millisecond)
+
+	// when
+	canceled := cancel()
+	w.advance(99)
+	before := len(fired)
+	w.advance(6000)
+
+	// then
+	c.Assert(canceled, Equals, true)
+	c.Assert(cancel(), Equals, false)
+	c.Assert(before, Equals, 2)
+	c.Assert(fired, DeepEquals, map[string]uint64{"1ms": 1, "partial": 2, "level1": 100, "level2": 5000})
+}
+
+func (s *MySuite) TestTimerWheelBeyondTopLevel(c *C) {
+	// given
+	w := NewTimerWheel(time.Millisecond)
+	var at uint64
+	w.advance(10)
+	w.Schedule(time.Duration(1<<24+100)*time.Millisecond, func() { at = w.Now() })
+
+	// when
+	w.advance(1<<24 + 120)
+
+	// then
+	c.Assert(at, Equals, uint64(1<<24+110))
+}
+
+func (s *MySuite) TestTimerWheelScheduleFromCallback(c *C) {
+	// given
+	w := NewTimerWheel(time.Millisecond)
+	var fired int32
+	w.Schedule(time.Millisecond, func() {
+		w.Schedule(0, func() { atomic.AddInt32(&fired, 1) })
+	})
+
+	// when
+	w.advance(1)
+	w.advance(2)
+
+	// then
+	c.Assert(atomic.LoadInt32(&fired), Equals, int32(1))
+}
+
+func (s *MySuite) TestTimerWheelStart(c *C) {
+	// given
+	w := NewTimerWheel(time.Millisecond)
+	fired := make(chan struct{})
+	w.Schedule(5*time.Millisecond, func() { close(fired) })
+
+	// when
+	stop := w.Start()
+	defer stop()
+
+	// then
+	select {
+	case <-fired:
+	case <-time.After(5 * time.Second):
+		c.Fatal("timer not fired")
+	}
+}
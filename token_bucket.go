@@ -0,0 +1,83 @@
+package lfring
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TokenBucket is a lock-free token bucket for the admission control in front of a ring:
+// tokens refill at rate per second up to burst, and every admitted value takes one.
+//
+// It's the generic cell rate algorithm (the leaky bucket as a meter): rather than counting
+// tokens, it keeps the theoretical arrival time (tat) by which the past admissions are paid
+// off, which is never earlier than now. Taking n tokens is a fetch-and-add of n emission
+// intervals on tat, which never fails for contention, and is admitted if tat doesn't run
+// ahead of now by more than burst intervals, otherwise it's refunded by another add.
+type TokenBucket struct {
+	tat      int64
+	interval int64
+	burst    uint64
+}
+
+// maxTokenRate is the highest rate of TokenBucket, a token per nanosecond.
+const maxTokenRate = float64(time.Second)
+
+// NewTokenBucket build a TokenBucket that refills rate tokens per second, and holds at most
+// burst (at least 1) tokens. It's full at start. The rate must be in (0, 1e9], the interval
+// of tokens is counted in nanoseconds, it panics otherwise.
+func NewTokenBucket(rate float64, burst uint64) *TokenBucket {
+	if !(rate > 0 && rate <= maxTokenRate) {
+		panic("lfring: token rate out of (0, 1e9]")
+	}
+	return &TokenBucket{
+		tat:      monotonicNow(),
+		interval: int64(float64(time.Second) / rate),
+		burst:    max(burst, 1),
+	}
+}
+
+// Allow takes a token, return false if there's none.
+func (b *TokenBucket) Allow() bool {
+	return b.AllowN(1)
+}
+
+// AllowN takes n tokens at once, return false (and takes nothing) if there're fewer.
+func (b *TokenBucket) AllowN(n uint64) bool {
+	return b.allowAt(monotonicNow(), n)
+}
+
+// AllowNAt is AllowN at the time now.
+func (b *TokenBucket) AllowNAt(now time.Time, n uint64) bool {
+	return b.allowAt(int64(now.Sub(monotonicBase)), n)
+}
+
+func (b *TokenBucket) allowAt(now int64, n uint64) bool {
+	if n > b.burst {
+		return false
+	}
+
+	// idle for a while, the bucket is full, that is tat catches up with now
+	for tat := atomic.LoadInt64(&b.tat); tat < now; tat = atomic.LoadInt64(&b.tat) {
+		if atomic.CompareAndSwapInt64(&b.tat, tat, now) {
+			break
+		}
+	}
+
+	cost := int64(n) * b.interval
+	if atomic.AddInt64(&b.tat, cost)-now > int64(b.burst)*b.interval {
+		atomic.AddInt64(&b.tat, -cost)
+		return false
+	}
+	return true
+}
+
+// Tokens returns the approximate number of tokens available now.
+func (b *TokenBucket) Tokens() float64 {
+	debt := max(atomic.LoadInt64(&b.tat)-monotonicNow(), 0)
+	return float64(b.burst) - float64(debt)/float64(b.interval)
+}
+
+// Burst returns how many tokens the bucket holds at most.
+func (b *TokenBucket) Burst() uint64 {
+	return b.burst
+}
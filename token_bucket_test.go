@@ -0,0 +1,65 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func (s *MySuite) TestTokenBucketBurstAndRefill(c *C) {
+	// given 10 tokens per second, at most 3
+	b := NewTokenBucket(10, 3)
+	now := time.Now().Add(time.Second)
+
+	// when
+	var burst []bool
+	for i := 0; i < 4; i++ {
+		burst = append(burst, b.AllowNAt(now, 1))
+	}
+	refilled := b.AllowNAt(now.Add(100*time.Millisecond), 1)
+	empty := b.AllowNAt(now.Add(100*time.Millisecond), 1)
+	tooMany := b.AllowNAt(now.Add(time.Hour), 4)
+	all := b.AllowNAt(now.Add(time.Hour), 3)
+
+	// then
+	c.Assert(burst, DeepEquals, []bool{true, true, true, false})
+	c.Assert(refilled, Equals, true)
+	c.Assert(empty, Equals, false)
+	c.Assert(tooMany, Equals, false)
+	c.Assert(all, Equals, true)
+	c.Assert(b.Burst(), Equals, uint64(3))
+}
+
+func (s *MySuite) TestTokenBucketConcurrency(c *C) {
+	// given a bucket that barely refills during the test
+	b := NewTokenBucket(0.001, 100)
+	var allowed int64
+	var wg sync.WaitGroup
+
+	// when
+	wg.Add(4)
+	for g := 0; g < 4; g++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if b.Allow() {
+					atomic.AddInt64(&allowed, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// then
+	c.Assert(allowed, Equals, int64(100))
+	c.Assert(b.Tokens() < 1, Equals, true)
+}
+
+func (s *MySuite) TestTokenBucketRateOutOfRange(c *C) {
+	for _, rate := range []float64{0, -1, math.NaN(), math.Inf(1), 2e9} {
+		c.Assert(func() { NewTokenBucket(rate, 1) }, PanicMatches, `lfring: token rate out of .*`, Commentf("rate: %v", rate))
+	}
+	c.Assert(NewTokenBucket(1e9, 1).Tokens(), Equals, float64(1))
+}
@@ -0,0 +1,45 @@
+package lfring
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// The runtime/trace task and regions emitted when WithTrace, so `go tool trace` shows the
+// batch boundaries and where the loops stall.
+const (
+	TaskProcessor       = "lfring.BatchEventProcessor"
+	RegionClaim         = "lfring.claim"
+	RegionPublish       = "lfring.publish"
+	RegionConsumeBatch  = "lfring.consumeBatch"
+	RegionWaitForValues = "lfring.wait"
+)
+
+// startRegion starts region name if enabled by WithTrace and the trace is running, otherwise
+// returns nil, which endRegion ignores.
+func startRegion(enabled bool, ctx context.Context, name string) *trace.Region {
+	if !enabled || !trace.IsEnabled() {
+		return nil
+	}
+	return trace.StartRegion(ctx, name)
+}
+
+func endRegion(region *trace.Region) {
+	if region != nil {
+		region.End()
+	}
+}
+
+// newTask starts task name like startRegion, the returned ctx carries the task.
+func newTask(enabled bool, ctx context.Context, name string) (context.Context, *trace.Task) {
+	if !enabled || !trace.IsEnabled() {
+		return ctx, nil
+	}
+	return trace.NewTask(ctx, name)
+}
+
+func endTask(task *trace.Task) {
+	if task != nil {
+		task.End()
+	}
+}
@@ -0,0 +1,40 @@
+package lfring
+
+import (
+	"bytes"
+	"context"
+	. "gopkg.in/check.v1"
+	"runtime/trace"
+)
+
+func (s *MySuite) TestRegionsOnlyWhenEnabled(c *C) {
+	c.Assert(startRegion(false, context.Background(), RegionClaim), IsNil)
+	// the trace is not running
+	c.Assert(startRegion(true, context.Background(), RegionClaim), IsNil)
+	endRegion(nil)
+	endTask(nil)
+}
+
+func (s *MySuite) TestTraceWhileRunning(c *C) {
+	// given
+	var out bytes.Buffer
+	c.Assert(trace.Start(&out), IsNil)
+	ring := NewEventRing[int](4, nil, WithTrace())
+	buffer := NewBlocking[int](New[int](NodeBased, 4))
+	buffer.Put(1)
+	buffer.Close()
+	var consumed []int
+
+	// when
+	ok := ring.OfferWith(func(v *int) { *v = 1 })
+	err := ConsumeBatches[int](context.Background(), buffer, func(batch []int) {
+		consumed = append(consumed, batch...)
+	}, WithTrace())
+	trace.Stop()
+
+	// then
+	c.Assert(ok, Equals, true)
+	c.Assert(err, IsNil)
+	c.Assert(consumed, DeepEquals, []int{1})
+	c.Assert(out.Len() > 0, Equals, true)
+}
@@ -0,0 +1,157 @@
+package lfring
+
+import (
+	"math"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// WaitStrategy decides what a goroutine does between two failed attempts on the buffer,
+// e.g. a consumer keeps Poll an empty buffer.
+type WaitStrategy interface {
+	// Wait is called after the attempt-th consecutive failed attempt, attempt starts from 1
+	// and will be reset once an attempt success.
+	Wait(attempt int)
+}
+
+type busySpinWait struct{}
+
+// BusySpinWait never gives up the CPU, it has the lowest latency but burns a core per waiter.
+// It only hints the CPU by PAUSE (amd64) or YIELD (arm64) between attempts, see cpuRelax.
+func BusySpinWait() WaitStrategy {
+	return busySpinWait{}
+}
+
+func (busySpinWait) Wait(int) {
+	cpuRelax()
+}
+
+type yieldingWait struct{}
+
+// YieldingWait calls runtime.Gosched on every failed attempt to let other goroutines run.
+func YieldingWait() WaitStrategy {
+	return yieldingWait{}
+}
+
+func (yieldingWait) Wait(int) {
+	runtime.Gosched()
+}
+
+type sleepingWait struct {
+	spins int
+	sleep time.Duration
+}
+
+// SleepingWait yields for the first spins attempts, then sleeps for the given duration on
+// every following attempt. It fits the case that latency isn't critical and CPU matters.
+func SleepingWait(spins int, sleep time.Duration) WaitStrategy {
+	return sleepingWait{spins: spins, sleep: sleep}
+}
+
+func (w sleepingWait) Wait(attempt int) {
+	if attempt <= w.spins {
+		runtime.Gosched()
+		return
+	}
+	time.Sleep(w.sleep)
+}
+
+type cooperativeWait struct {
+	sleepEvery int
+	sleep      time.Duration
+}
+
+// CooperativeWait yields by runtime.Gosched, and sleeps for the given duration on every
+// sleepEvery-th attempt instead.
+//
+// It's tuned for the case goroutines far outnumber Ps (GOMAXPROCS). Busy spin (and even pure
+// yielding) may livelock there: the spinning goroutines keep every P busy, Gosched just
+// picks another spinner from the run queue, while the goroutine that would make progress
+// (e.g. the consumer of a full buffer) waits for its turn for a long time. The occasional
+// sleep takes the waiter off the run queue, which guarantees the others a share of the Ps.
+// See BenchmarkOversubscribed* in the bench package for the comparison.
+func CooperativeWait(sleepEvery int, sleep time.Duration) WaitStrategy {
+	if sleepEvery < 1 {
+		sleepEvery = 1
+	}
+	return cooperativeWait{sleepEvery: sleepEvery, sleep: sleep}
+}
+
+func (w cooperativeWait) Wait(attempt int) {
+	if attempt%w.sleepEvery == 0 {
+		time.Sleep(w.sleep)
+		return
+	}
+	runtime.Gosched()
+}
+
+// adaptiveYields is how many attempts AdaptiveWait yields for after the spins, before sleep.
+const adaptiveYields = 8
+
+type adaptiveWait struct {
+	// maxSpins, or 0 if GOMAXPROCS was 1 when built
+	limit int32
+	sleep time.Duration
+	spins atomic.Int32
+	last  atomic.Int32
+}
+
+// AdaptiveWait spins (by cpuRelax) for the first attempts, yields for a few more, then sleeps
+// for the given duration on every following attempt, like SleepingWait, but how long it spins
+// adapts to what it sees.
+//
+// The spins never exceed maxSpins, and are 0 if GOMAXPROCS is 1 when built: nobody can make
+// progress on the only P while it spins. GOMAXPROCS is read once, build it after setting
+// GOMAXPROCS. A wait that ends within the spins and yields grows them by one, a wait that
+// ends sleeping halves them. So a waiter that rarely succeeds by spinning, e.g. the Ps
+// heavily oversubscribed or a container with a tiny CPU quota, backs off to yield and sleep
+// soon, while the same binary keeps low latency spins on an idle machine.
+//
+// Unlike the other strategies it has state, share one between the waiters of a buffer rather
+// than build one per Wait.
+func AdaptiveWait(maxSpins int, sleep time.Duration) WaitStrategy {
+	if maxSpins < 0 {
+		maxSpins = 0
+	}
+	if maxSpins > math.MaxInt32 {
+		maxSpins = math.MaxInt32
+	}
+	w := &adaptiveWait{limit: int32(maxSpins), sleep: sleep}
+	if runtime.GOMAXPROCS(0) == 1 {
+		w.limit = 0
+	}
+	w.spins.Store(w.limit)
+	return w
+}
+
+func (w *adaptiveWait) Wait(attempt int) {
+	if attempt == 1 {
+		// the previous wait ended by a successful attempt
+		w.adapt(w.last.Load())
+	}
+	w.last.Store(int32(min(attempt, math.MaxInt32)))
+
+	spins := int(w.spins.Load())
+	switch {
+	case attempt <= spins:
+		cpuRelax()
+	case attempt <= spins+adaptiveYields:
+		runtime.Gosched()
+	default:
+		time.Sleep(w.sleep)
+	}
+}
+
+func (w *adaptiveWait) adapt(lastAttempt int32) {
+	if lastAttempt == 0 {
+		return
+	}
+	spins := w.spins.Load()
+	if lastAttempt <= spins+adaptiveYields {
+		spins++
+	} else {
+		spins /= 2
+	}
+	w.spins.Store(min(spins, w.limit))
+}
@@ -0,0 +1,69 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"runtime"
+	"time"
+)
+
+func (s *MySuite) TestCooperativeWaitSleepOnEveryNthAttempt(c *C) {
+	// given
+	wait := CooperativeWait(4, 20*time.Millisecond)
+
+	// when
+	start := time.Now()
+	for attempt := 1; attempt < 4; attempt++ {
+		wait.Wait(attempt)
+	}
+	yielded := time.Since(start)
+	wait.Wait(4)
+	slept := time.Since(start) - yielded
+
+	// then
+	c.Assert(yielded < 20*time.Millisecond, Equals, true)
+	c.Assert(slept >= 20*time.Millisecond, Equals, true)
+}
+
+func (s *MySuite) TestAdaptiveWaitShrinkSpinsOnLongWaits(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+	// given
+	wait := AdaptiveWait(16, time.Microsecond).(*adaptiveWait)
+
+	// when
+	for attempt := 1; attempt <= 16+adaptiveYields+1; attempt++ {
+		wait.Wait(attempt)
+	}
+	wait.Wait(1)
+
+	// then
+	c.Assert(wait.spins.Load(), Equals, int32(8))
+}
+
+func (s *MySuite) TestAdaptiveWaitGrowSpinsOnShortWaits(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+	// given
+	wait := AdaptiveWait(16, time.Microsecond).(*adaptiveWait)
+	wait.spins.Store(4)
+
+	// when
+	for i := 0; i < 20; i++ {
+		wait.Wait(1)
+		wait.Wait(2)
+	}
+
+	// then
+	c.Assert(wait.spins.Load(), Equals, int32(16))
+}
+
+func (s *MySuite) TestAdaptiveWaitNoSpinsOnSingleP(c *C) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+	// given
+	wait := AdaptiveWait(16, time.Microsecond).(*adaptiveWait)
+
+	// when
+	wait.Wait(1)
+	wait.Wait(1)
+
+	// then
+	c.Assert(wait.spins.Load(), Equals, int32(0))
+}
@@ -0,0 +1,28 @@
+package lfring
+
+// prefaulter is implemented by the buffers can touch all their slots, see WithWarmup.
+type prefaulter interface {
+	prefault()
+}
+
+// warmup prefaults buffer, then fills and drains it for cycles rounds, right after built so
+// nobody else sees it yet.
+func warmup[T any](buffer RingBuffer[T], cycles int) {
+	if p, ok := buffer.(prefaulter); ok {
+		p.prefault()
+	}
+
+	var zero T
+	for i := 0; i < cycles; i++ {
+		for n := buffer.Cap(); n > 0; n-- {
+			if !buffer.Offer(zero) {
+				break
+			}
+		}
+		for n := buffer.Cap(); n > 0; n-- {
+			if _, ok := buffer.Poll(); !ok {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package lfring
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Watchdog detects stuck consumers: the head of buffer hasn't advanced for timeout while
+// the buffer is not empty, which is a sign of deadlocked or crashed consumers. Then it calls
+// onStuck with the State (and logs by WithLogger) once, until the head advances again or the
+// buffer becomes empty.
+type Watchdog struct {
+	source  interface{ State() State }
+	timeout time.Duration
+	onStuck func(state State, stuckFor time.Duration)
+	logger  logger
+
+	mu       sync.Mutex
+	head     uint64
+	since    time.Time
+	reported bool
+}
+
+// NewWatchdog build a Watchdog of source (any RingBuffer), onStuck can be nil if logging is
+// enough.
+func NewWatchdog(source interface{ State() State }, timeout time.Duration, onStuck func(state State, stuckFor time.Duration), opts ...Option) *Watchdog {
+	return &Watchdog{
+		source:  source,
+		timeout: timeout,
+		onStuck: onStuck,
+		logger:  newLogger(newConfig(opts)),
+	}
+}
+
+// Start runs the checking goroutine, which checks every quarter of timeout, until the
+// returned stop is called.
+func (w *Watchdog) Start() (stop func()) {
+	ticker := time.NewTicker(max(w.timeout/4, time.Millisecond))
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				w.Check(now)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// Check checks the State at now, reports whether the consumers are stuck. It's called by the
+// goroutine of Start, or can be called by the user's own scheduler instead.
+func (w *Watchdog) Check(now time.Time) (stuck bool) {
+	state := w.source.State()
+
+	w.mu.Lock()
+	if state.Occupancy == 0 || state.Head != w.head || w.since.IsZero() {
+		w.head = state.Head
+		w.since = now
+		w.reported = false
+		w.mu.Unlock()
+		return false
+	}
+
+	stuckFor := now.Sub(w.since)
+	if stuckFor < w.timeout {
+		w.mu.Unlock()
+		return false
+	}
+	report := !w.reported
+	w.reported = true
+	w.mu.Unlock()
+
+	if report {
+		w.logger.log(slog.LevelWarn, "lfring: consumer stuck", slog.Duration("for", stuckFor), stateAttr(state))
+		if w.onStuck != nil {
+			w.onStuck(state, stuckFor)
+		}
+	}
+	return true
+}
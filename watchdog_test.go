@@ -0,0 +1,77 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestWatchdogReportsStuckOnce(c *C) {
+	// given
+	buffer := New[int](NodeBased, 4)
+	var reports []State
+	watchdog := NewWatchdog(buffer, time.Second, func(state State, stuckFor time.Duration) {
+		reports = append(reports, state)
+	})
+	start := time.Now()
+	watchdog.Check(start)
+	MustOffer[int](buffer, 1)
+	MustOffer[int](buffer, 2)
+
+	// when
+	early := watchdog.Check(start.Add(500 * time.Millisecond))
+	stuck := watchdog.Check(start.Add(1500 * time.Millisecond))
+	stillStuck := watchdog.Check(start.Add(2 * time.Second))
+
+	// then
+	c.Assert(early, Equals, false)
+	c.Assert(stuck, Equals, true)
+	c.Assert(stillStuck, Equals, true)
+	c.Assert(len(reports), Equals, 1)
+	c.Assert(reports[0].Occupancy, Equals, uint64(2))
+
+	// when head advances
+	MustPoll[int](buffer)
+	advanced := watchdog.Check(start.Add(3 * time.Second))
+	stuckAgain := watchdog.Check(start.Add(5 * time.Second))
+
+	// then
+	c.Assert(advanced, Equals, false)
+	c.Assert(stuckAgain, Equals, true)
+	c.Assert(len(reports), Equals, 2)
+}
+
+func (s *MySuite) TestWatchdogIgnoresEmptyBuffer(c *C) {
+	// given
+	buffer := New[int](Classical, 4)
+	watchdog := NewWatchdog(buffer, time.Millisecond, nil)
+	start := time.Now()
+
+	// when
+	watchdog.Check(start)
+	stuck := watchdog.Check(start.Add(time.Hour))
+
+	// then
+	c.Assert(stuck, Equals, false)
+}
+
+func (s *MySuite) TestWatchdogStart(c *C) {
+	// given
+	buffer := New[int](NodeBased, 4)
+	MustOffer[int](buffer, 1)
+	reported := make(chan State, 1)
+	watchdog := NewWatchdog(buffer, 4*time.Millisecond, func(state State, stuckFor time.Duration) {
+		reported <- state
+	})
+
+	// when
+	stop := watchdog.Start()
+	defer stop()
+
+	// then
+	select {
+	case state := <-reported:
+		c.Assert(state.Occupancy, Equals, uint64(1))
+	case <-time.After(time.Second):
+		c.Fatal("stuck consumer not reported")
+	}
+}
@@ -0,0 +1,163 @@
+package lfring
+
+import (
+	"math"
+	"sync"
+)
+
+// WeightedScheduler drains several source rings into one consumer by the weighted fair
+// queuing, e.g. the traffic classes of tenants multiplexed with proportional service: a
+// source of weight 3 is served three times as much as a source of weight 1 while both have
+// values, and an empty source leaves its share to the others.
+//
+// It's the deficit round robin: the sources take turns, every turn grants the weight to the
+// deficit of a source, which is served while its deficit is positive, the cost of every value
+// (1 by default, see NewWeightedScheduler) is charged after polled. A value costs more than
+// left is still served, the debt carries to the next turns. An empty source loses its
+// deficit.
+//
+// The polls are serialized by a mutex, the sources may be offered concurrently as usual.
+type WeightedScheduler[T any] struct {
+	cost func(T) uint64
+
+	mu      sync.Mutex
+	sources []*weightedSource[T]
+	current int
+	granted bool
+	calls   uint64
+}
+
+type weightedSource[T any] struct {
+	name     string
+	consumer Consumer[T]
+	weight   int64
+	deficit  int64
+	polled   uint64
+	charged  uint64
+	// the call of Poll found it empty last
+	emptyIn uint64
+}
+
+// WeightedStats is a snapshot of the counters of a source of WeightedScheduler.
+type WeightedStats struct {
+	Name   string `json:"name"`
+	Weight uint64 `json:"weight"`
+	Polled uint64 `json:"polled"`
+	Cost   uint64 `json:"cost"`
+}
+
+// NewWeightedScheduler build a scheduler without sources, cost returns the cost of a value
+// charged to its source, e.g. the size of a payload for the fairness by bytes, nil means
+// every value costs 1.
+func NewWeightedScheduler[T any](cost func(T) uint64) *WeightedScheduler[T] {
+	return &WeightedScheduler[T]{cost: cost}
+}
+
+// Add a source named name of weight, zero weight is taken as 1. It takes its turn after the
+// sources added before.
+func (s *WeightedScheduler[T]) Add(name string, source Consumer[T], weight uint64) {
+	if weight == 0 {
+		weight = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources = append(s.sources, &weightedSource[T]{name: name, consumer: source, weight: int64(weight)})
+}
+
+// Poll the next value by the weights, return false if every source is empty.
+func (s *WeightedScheduler[T]) Poll() (value T, success bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// empty counts the sources found empty by this call, once each, idle counts the turns
+	// since the last source found empty for the first time
+	s.calls++
+	for empty, idle := 0, 0; empty < len(s.sources); {
+		if idle >= len(s.sources) {
+			s.forgive()
+			idle = 0
+		}
+		src := s.sources[s.current]
+		if !s.granted {
+			src.deficit += src.weight
+			s.granted = true
+		}
+		if src.deficit <= 0 {
+			// still paying for the values cost more
+			s.advance()
+			idle++
+			continue
+		}
+
+		if value, success = src.consumer.Poll(); !success {
+			src.deficit = 0
+			s.advance()
+			if src.emptyIn != s.calls {
+				src.emptyIn = s.calls
+				empty++
+				idle = 0
+			} else {
+				idle++
+			}
+			continue
+		}
+		cost := uint64(1)
+		if s.cost != nil {
+			cost = s.cost(value)
+		}
+		src.deficit -= int64(cost)
+		src.polled++
+		src.charged += cost
+		if src.deficit <= 0 {
+			s.advance()
+		}
+		return
+	}
+	return
+}
+
+// forgive grants the passes the indebted sources would take turns for at once, after a pass
+// found only them and the empty ones, so a large debt doesn't spin Poll for as many passes.
+func (s *WeightedScheduler[T]) forgive() {
+	passes := int64(math.MaxInt64)
+	for _, src := range s.sources {
+		if src.deficit < 0 {
+			passes = min(passes, -src.deficit/src.weight)
+		}
+	}
+	for _, src := range s.sources {
+		if src.deficit < 0 {
+			src.deficit += passes * src.weight
+		}
+	}
+}
+
+func (s *WeightedScheduler[T]) advance() {
+	s.current = (s.current + 1) % len(s.sources)
+	s.granted = false
+}
+
+// PollBatchInto fills dst with the values by the weights, returns how many values are
+// filled.
+func (s *WeightedScheduler[T]) PollBatchInto(dst []T) (count uint64) {
+	for count < uint64(len(dst)) {
+		v, ok := s.Poll()
+		if !ok {
+			break
+		}
+		dst[count] = v
+		count++
+	}
+	return count
+}
+
+// Stats returns the counters of every source in the order added.
+func (s *WeightedScheduler[T]) Stats() []WeightedStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make([]WeightedStats, len(s.sources))
+	for i, src := range s.sources {
+		stats[i] = WeightedStats{Name: src.name, Weight: uint64(src.weight), Polled: src.polled, Cost: src.charged}
+	}
+	return stats
+}
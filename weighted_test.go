@@ -0,0 +1,113 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestWeightedSchedulerProportionalService(c *C) {
+	// given
+	gold, bronze := New[string](NodeBased, 16), New[string](NodeBased, 16)
+	for i := 0; i < 8; i++ {
+		gold.Offer("g")
+		bronze.Offer("b")
+	}
+	scheduler := NewWeightedScheduler[string](nil)
+	scheduler.Add("gold", gold, 3)
+	scheduler.Add("bronze", bronze, 1)
+
+	// when
+	dst := make([]string, 8)
+	count := scheduler.PollBatchInto(dst)
+
+	// then
+	c.Assert(count, Equals, uint64(8))
+	c.Assert(dst, DeepEquals, []string{"g", "g", "g", "b", "g", "g", "g", "b"})
+	c.Assert(scheduler.Stats(), DeepEquals, []WeightedStats{
+		{Name: "gold", Weight: 3, Polled: 6, Cost: 6},
+		{Name: "bronze", Weight: 1, Polled: 2, Cost: 2},
+	})
+}
+
+func (s *MySuite) TestWeightedSchedulerEmptySourceLeavesShare(c *C) {
+	// given
+	idle, busy := New[int](NodeBased, 4), New[int](NodeBased, 4)
+	busy.Offer(1)
+	busy.Offer(2)
+	busy.Offer(3)
+	scheduler := NewWeightedScheduler[int](nil)
+	scheduler.Add("idle", idle, 4)
+	scheduler.Add("busy", busy, 1)
+
+	// when
+	dst := make([]int, 4)
+	count := scheduler.PollBatchInto(dst)
+	_, ok := scheduler.Poll()
+
+	// then
+	c.Assert(dst[:count], DeepEquals, []int{1, 2, 3})
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestWeightedSchedulerChargeCost(c *C) {
+	// given sources of the same weight, one sends large values
+	large, small := New[string](NodeBased, 8), New[string](NodeBased, 8)
+	for i := 0; i < 4; i++ {
+		large.Offer("llll")
+		small.Offer("s")
+		small.Offer("s")
+	}
+	scheduler := NewWeightedScheduler(func(v string) uint64 { return uint64(len(v)) })
+	scheduler.Add("large", large, 2)
+	scheduler.Add("small", small, 2)
+
+	// when
+	dst := make([]string, 6)
+	count := scheduler.PollBatchInto(dst)
+
+	// then the large one pays its debt by skipping a turn
+	c.Assert(count, Equals, uint64(6))
+	c.Assert(dst, DeepEquals, []string{"llll", "s", "s", "s", "s", "llll"})
+	c.Assert(scheduler.Stats()[0].Cost, Equals, uint64(8))
+}
+
+func (s *MySuite) TestWeightedSchedulerServeIndebtedSource(c *C) {
+	// given an empty source and one left in debt by a costly value
+	idle, busy := New[uint64](NodeBased, 4), New[uint64](NodeBased, 4)
+	busy.Offer(10)
+	busy.Offer(1)
+	scheduler := NewWeightedScheduler(func(v uint64) uint64 { return v })
+	scheduler.Add("idle", idle, 1)
+	scheduler.Add("busy", busy, 1)
+
+	// when
+	first, _ := scheduler.Poll()
+	second, ok := scheduler.Poll()
+	_, drained := scheduler.Poll()
+
+	// then the debt delays but never hides the value
+	c.Assert(first, Equals, uint64(10))
+	c.Assert(ok, Equals, true)
+	c.Assert(second, Equals, uint64(1))
+	c.Assert(drained, Equals, false)
+	c.Assert(scheduler.Stats()[1].Cost, Equals, uint64(11))
+}
+
+func (s *MySuite) TestWeightedSchedulerForgiveLargeDebt(c *C) {
+	// given debts of many passes
+	a, b := New[uint64](NodeBased, 4), New[uint64](NodeBased, 4)
+	a.Offer(1 << 40)
+	a.Offer(1)
+	b.Offer(1 << 41)
+	b.Offer(2)
+	scheduler := NewWeightedScheduler(func(v uint64) uint64 { return v })
+	scheduler.Add("a", a, 1)
+	scheduler.Add("b", b, 1)
+
+	// when
+	dst := make([]uint64, 4)
+	count := scheduler.PollBatchInto(dst)
+
+	// then the smaller debt is paid off first
+	c.Assert(count, Equals, uint64(4))
+	c.Assert(dst, DeepEquals, []uint64{1 << 40, 1 << 41, 1, 2})
+}
@@ -0,0 +1,117 @@
+package lfring
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Window is a time-indexed view of a Multicast: every value is stamped with the time added,
+// and the queries only see the values of the last duration, e.g. the count of requests of
+// the last minute for a rate, or the sum of their latencies by Reduce. It fits the in-process
+// metrics that don't justify a time series database.
+//
+// Values are kept for span at most, but the ring still bounds them: once more than Capacity
+// values are added within span, the oldest are overwritten before they expire, so size the
+// capacity by the peak rate times span. Add never blocks, and the queries never block Add.
+type Window[T any] struct {
+	span   time.Duration
+	values *Multicast[windowItem[T]]
+}
+
+type windowItem[T any] struct {
+	at    int64
+	value T
+}
+
+// NewWindow build a Window that keeps values for span, in a Multicast of capacity (expands
+// to power-of-two).
+func NewWindow[T any](span time.Duration, capacity uint64) *Window[T] {
+	return &Window[T]{span: span, values: NewMulticast[windowItem[T]](capacity)}
+}
+
+// Add adds value stamped with the current time.
+func (w *Window[T]) Add(value T) {
+	w.values.Offer(windowItem[T]{at: monotonicNow(), value: value})
+}
+
+// AddAt adds value stamped with at, which should be close to the current time, since the
+// values are searched in the order added.
+func (w *Window[T]) AddAt(value T, at time.Time) {
+	w.values.Offer(windowItem[T]{at: int64(at.Sub(monotonicBase)), value: value})
+}
+
+// Count returns how many values are added within the last d, d is capped at span.
+func (w *Window[T]) Count(d time.Duration) int {
+	return w.CountAt(time.Now(), d)
+}
+
+// CountAt returns how many values are added within d before now.
+func (w *Window[T]) CountAt(now time.Time, d time.Duration) (count int) {
+	w.each(now, d, func(T) { count++ })
+	return count
+}
+
+// Rate returns the number of values added per second within the last d.
+func (w *Window[T]) Rate(d time.Duration) float64 {
+	if d > w.span {
+		d = w.span
+	}
+	if d <= 0 {
+		return 0
+	}
+	return float64(w.Count(d)) / d.Seconds()
+}
+
+// Span returns how long values are kept.
+func (w *Window[T]) Span() time.Duration {
+	return w.span
+}
+
+// Capacity returns the real (power-of-two) capacity.
+func (w *Window[T]) Capacity() uint64 {
+	return w.values.Capacity()
+}
+
+// each passes the values added within d before now to f, from the newest to the oldest. It
+// walks back from tail and stops at the first value older than d, values still being written
+// are skipped.
+func (w *Window[T]) each(now time.Time, d time.Duration, f func(T)) {
+	if d > w.span {
+		d = w.span
+	}
+	to := int64(now.Sub(monotonicBase))
+	from := to - int64(d)
+
+	m := w.values
+	tail := atomic.LoadUint64(&m.tail)
+	for seq := tail; seq > m.Earliest(); {
+		seq--
+		item, status := m.read(seq)
+		if status == readNotYet {
+			continue
+		}
+		if status == readOverwritten || item.at <= from {
+			return
+		}
+		if item.at <= to {
+			f(item.value)
+		}
+	}
+}
+
+// Reduce folds the values added within the last d of w by fold, from the newest to the
+// oldest, starting at init:
+//
+//	total := lfring.Reduce(latencies, time.Minute, time.Duration(0), func(sum, d time.Duration) time.Duration {
+//		return sum + d
+//	})
+func Reduce[T, A any](w *Window[T], d time.Duration, init A, fold func(acc A, value T) A) A {
+	return ReduceAt(w, time.Now(), d, init, fold)
+}
+
+// ReduceAt is Reduce of the values added within d before now.
+func ReduceAt[T, A any](w *Window[T], now time.Time, d time.Duration, init A, fold func(acc A, value T) A) A {
+	acc := init
+	w.each(now, d, func(v T) { acc = fold(acc, v) })
+	return acc
+}
@@ -0,0 +1,44 @@
+package lfring
+
+import (
+	. "gopkg.in/check.v1"
+	"time"
+)
+
+func (s *MySuite) TestWindowQueriesLastDuration(c *C) {
+	// given
+	w := NewWindow[int](time.Minute, 16)
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		w.AddAt(i, now.Add(time.Duration(i-5)*10*time.Second))
+	}
+
+	// when
+	count := w.CountAt(now, 25*time.Second)
+	sum := ReduceAt(w, now, 25*time.Second, 0, func(acc int, v int) int { return acc + v })
+	all := w.CountAt(now, time.Hour)
+	before := w.CountAt(now.Add(-15*time.Second), 10*time.Second)
+
+	// then
+	c.Assert(count, Equals, 3)
+	c.Assert(sum, Equals, 3+4+5)
+	c.Assert(all, Equals, 5)
+	c.Assert(before, Equals, 1)
+}
+
+func (s *MySuite) TestWindowBoundedByCapacity(c *C) {
+	// given
+	w := NewWindow[int](time.Minute, 4)
+
+	// when
+	for i := 0; i < 10; i++ {
+		w.Add(i)
+	}
+
+	// then
+	c.Assert(w.Count(time.Minute), Equals, 4)
+	c.Assert(Reduce(w, time.Minute, []int(nil), func(acc []int, v int) []int { return append(acc, v) }), DeepEquals, []int{9, 8, 7, 6})
+	c.Assert(w.Rate(time.Second) > 0, Equals, true)
+	c.Assert(w.Span(), Equals, time.Minute)
+	c.Assert(w.Capacity(), Equals, uint64(4))
+}